@@ -14,6 +14,7 @@ import (
 	"github.com/AleksK1NG/api-mc/pkg/db/postgres"
 	"github.com/AleksK1NG/api-mc/pkg/db/redis"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/mailer"
 	"github.com/AleksK1NG/api-mc/pkg/utils"
 
 	"github.com/uber/jaeger-client-go"
@@ -65,6 +66,12 @@ func main() {
 	}
 	appLogger.Info("AWS S3 connected")
 
+	mailSender, err := mailer.NewMailer(cfg)
+	if err != nil {
+		appLogger.Fatalf("Mailer init: %s", err)
+	}
+	appLogger.Infof("Mailer initialized, Driver: %s", cfg.Mail.Driver)
+
 	jaegerCfgInstance := jaegercfg.Configuration{
 		ServiceName: cfg.Jaeger.ServiceName,
 		Sampler: &jaegercfg.SamplerConfig{
@@ -90,7 +97,7 @@ func main() {
 	defer closer.Close()
 	appLogger.Info("Opentracing connected")
 
-	s := server.NewServer(cfg, psqlDB, redisClient, awsClient, appLogger)
+	s := server.NewServer(cfg, psqlDB, redisClient, awsClient, mailSender, appLogger)
 	if err = s.Run(); err != nil {
 		log.Fatal(err)
 	}
@@ -10,33 +10,54 @@ import (
 
 // App config struct
 type Config struct {
-	Server   ServerConfig
-	Postgres PostgresConfig
-	Redis    RedisConfig
-	MongoDB  MongoDB
-	Cookie   Cookie
-	Store    Store
-	Session  Session
-	Metrics  Metrics
-	Logger   Logger
-	AWS      AWS
-	Jaeger   Jaeger
+	Server       ServerConfig
+	Postgres     PostgresConfig
+	Redis        RedisConfig
+	MongoDB      MongoDB
+	Cookie       Cookie
+	Store        Store
+	Session      Session
+	Metrics      Metrics
+	Logger       Logger
+	AWS          AWS
+	Jaeger       Jaeger
+	Mail         Mail
+	TwoFactor    TwoFactor
+	Lockout      Lockout
+	JWT          JWT
+	Auth         Auth
+	OIDC         OIDC
+	MagicLink    MagicLink
+	Password     PasswordPolicy
+	PasswordHash PasswordHash
+	Deletion     Deletion
+	Export       Export
+	News         News
+	UserSearch   UserSearch
 }
 
 // Server config struct
 type ServerConfig struct {
-	AppVersion        string
-	Port              string
-	PprofPort         string
-	Mode              string
-	JwtSecretKey      string
-	CookieName        string
-	ReadTimeout       time.Duration
-	WriteTimeout      time.Duration
-	SSL               bool
-	CtxDefaultTimeout time.Duration
-	CSRF              bool
-	Debug             bool
+	AppVersion              string
+	Port                    string
+	PprofPort               string
+	Mode                    string
+	BaseURL                 string
+	JwtSecretKey            string
+	AccessTokenExpire       int
+	RefreshTokenExpire      int
+	PasswordResetExpire     int
+	EmailVerificationExpire int
+	EmailChangeExpire       int
+	EmailRevertExpire       int
+	UnverifiedAccess        string
+	CookieName              string
+	ReadTimeout             time.Duration
+	WriteTimeout            time.Duration
+	SSL                     bool
+	CtxDefaultTimeout       time.Duration
+	CSRF                    bool
+	Debug                   bool
 }
 
 // Logger config
@@ -87,9 +108,10 @@ type Cookie struct {
 
 // Session config
 type Session struct {
-	Prefix string
-	Name   string
-	Expire int
+	Prefix              string
+	Name                string
+	Expire              int
+	ImpersonationExpire int
 }
 
 // Metrics config
@@ -119,6 +141,133 @@ type Jaeger struct {
 	LogSpans    bool
 }
 
+// Mail sender config
+type Mail struct {
+	Driver   string
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	Folder   string
+}
+
+// Two factor authentication config
+type TwoFactor struct {
+	Issuer          string
+	ChallengeExpire int
+	ForceAdmins     bool
+}
+
+// JWT signing config, HS256 uses server JwtSecretKey, RS256 and ES256 use PEM keys
+type JWT struct {
+	Algorithm    string
+	SigningKeyID string
+	Keys         []JWTKey
+}
+
+// JWT key, key without private key file is used only for verification of tokens signed before rotation
+type JWTKey struct {
+	ID             string
+	Algorithm      string
+	PrivateKeyFile string
+	PublicKeyFile  string
+}
+
+// Auth middleware config, mechanisms session, jwt and api_key are tried in given order
+type Auth struct {
+	Mechanisms []string
+}
+
+// OpenID Connect social login config, state expire in seconds
+type OIDC struct {
+	StateExpire int
+	Providers   []OIDCProvider
+}
+
+// OpenID Connect provider, endpoints are resolved by issuer discovery document
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Password policy config, breached hashes file contains upper case SHA-1 hashes sorted by hash, one HASH:COUNT per line
+type PasswordPolicy struct {
+	MinLength          int
+	MaxLength          int
+	RequireUpper       bool
+	RequireLower       bool
+	RequireDigit       bool
+	RequireSymbol      bool
+	BannedWords        []string
+	BreachedHashesFile string
+}
+
+// Password hashing config, algorithm bcrypt or argon2id, argon2 memory in KiB.
+// Stored hashes with other algorithm or parameters are re-hashed on login.
+type PasswordHash struct {
+	Algorithm        string
+	BcryptCost       int
+	Argon2Time       int
+	Argon2Memory     int
+	Argon2Threads    int
+	Argon2KeyLength  int
+	Argon2SaltLength int
+}
+
+// Deleted users config, durations in seconds. Users deleted longer than grace period ago can not be restored
+// and are purged in batches: anonymized, or with purge mode delete hard deleted unless they authored news.
+type Deletion struct {
+	GracePeriod    int
+	PurgeInterval  int
+	PurgeMode      string
+	PurgeBatchSize int
+}
+
+// Personal data export config, durations in seconds. Export bucket should be private
+// and expire objects by lifecycle rule, download links are presigned.
+type Export struct {
+	Bucket     string
+	LinkExpire int
+	Timeout    int
+}
+
+// News config. Search language is PostgreSQL text search configuration of search queries and of
+// search vectors of created and updated news, news saved before language change are reindexed on update.
+// Scheduled news are published in batches every publish interval, in seconds.
+type News struct {
+	SearchLanguage   string
+	PublishInterval  int
+	PublishBatchSize int
+}
+
+// Users fuzzy search config, similarity is pg_trgm word similarity threshold from 0 to 1,
+// lower values tolerate more typos and return more loosely matching users
+type UserSearch struct {
+	Similarity float64
+}
+
+// Passwordless login link config, durations in seconds
+type MagicLink struct {
+	Expire         int
+	MaxRequests    int
+	IPMaxRequests  int
+	RequestsWindow int
+}
+
+// Login brute-force protection config, durations in seconds
+type Lockout struct {
+	MaxAttempts    int
+	IPMaxAttempts  int
+	AttemptsWindow int
+	BaseDelay      int
+	MaxDelay       int
+}
+
 // Load config file from given path
 func LoadConfig(filename string) (*viper.Viper, error) {
 	v := viper.New()
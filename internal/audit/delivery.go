@@ -0,0 +1,8 @@
+package audit
+
+import "github.com/labstack/echo/v4"
+
+// Audit HTTP Handlers interface
+type Handlers interface {
+	GetEvents() echo.HandlerFunc
+}
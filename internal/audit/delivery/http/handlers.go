@@ -0,0 +1,114 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/audit"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+// Audit handlers
+type auditHandlers struct {
+	cfg     *config.Config
+	auditUC audit.UseCase
+	logger  logger.Logger
+}
+
+// Audit handlers constructor
+func NewAuditHandlers(cfg *config.Config, auditUC audit.UseCase, log logger.Logger) audit.Handlers {
+	return &auditHandlers{cfg: cfg, auditUC: auditUC, logger: log}
+}
+
+// GetEvents godoc
+// @Summary Get audit events
+// @Description query audit log, newest events first
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Param actor_id query string false "actor user id"
+// @Param target_type query string false "target type: user, news, comment"
+// @Param target_id query string false "target id"
+// @Param action query string false "action, e.g. user.delete"
+// @Param from query string false "RFC3339 time, inclusive"
+// @Param to query string false "RFC3339 time, exclusive"
+// @Param page query int false "page number" Format(page)
+// @Param size query int false "number of elements per page" Format(size)
+// @Success 200 {object} models.AuditEventsList
+// @Failure 400 {object} httpErrors.RestError
+// @Router /audit/events [get]
+func (h *auditHandlers) GetEvents() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "auditHandlers.GetEvents")
+		defer span.Finish()
+
+		pq, err := utils.GetPaginationFromCtx(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		filter, err := getEventsFilter(c)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		eventsList, err := h.auditUC.GetEvents(ctx, filter, pq)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, eventsList)
+	}
+}
+
+// Parse events filter from query params
+func getEventsFilter(c echo.Context) (*models.AuditEventsFilter, error) {
+	filter := &models.AuditEventsFilter{
+		TargetType: c.QueryParam("target_type"),
+		TargetID:   c.QueryParam("target_id"),
+		Action:     c.QueryParam("action"),
+	}
+
+	if actorID := c.QueryParam("actor_id"); actorID != "" {
+		id, err := uuid.Parse(actorID)
+		if err != nil {
+			return nil, httpErrors.NewRestError(http.StatusBadRequest, httpErrors.BadQueryParams.Error(), err)
+		}
+		filter.ActorID = &id
+	}
+	from, err := getTimeParam(c, "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := getTimeParam(c, "to")
+	if err != nil {
+		return nil, err
+	}
+	filter.From, filter.To = from, to
+
+	return filter, nil
+}
+
+// Parse optional RFC3339 time query param
+func getTimeParam(c echo.Context, name string) (*time.Time, error) {
+	query := c.QueryParam(name)
+	if query == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, query)
+	if err != nil {
+		return nil, httpErrors.NewRestError(http.StatusBadRequest, httpErrors.BadQueryParams.Error(), err)
+	}
+	return &t, nil
+}
@@ -0,0 +1,15 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/AleksK1NG/api-mc/internal/audit"
+	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Map audit log routes
+func MapAuditRoutes(auditGroup *echo.Group, h audit.Handlers, mw *middleware.MiddlewareManager) {
+	auditGroup.Use(mw.AuthMiddleware)
+	auditGroup.GET("/events", h.GetEvents(), mw.PermissionMiddleware(models.PermissionAuditRead))
+}
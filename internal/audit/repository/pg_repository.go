@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/audit"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+// Audit Repository
+type auditRepo struct {
+	db *sqlx.DB
+}
+
+// Audit repository constructor
+func NewAuditRepository(db *sqlx.DB) audit.Repository {
+	return &auditRepo{db: db}
+}
+
+// Audit event row, json columns are scanned as raw bytes
+type auditEventRow struct {
+	models.AuditEvent
+	MetadataData []byte `db:"metadata"`
+	BeforeData   []byte `db:"before_data"`
+	AfterData    []byte `db:"after_data"`
+}
+
+// Append audit event
+func (r *auditRepo) Create(ctx context.Context, event *models.AuditEvent) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditRepo.Create")
+	defer span.Finish()
+
+	metadata, err := marshalJSON(event.Metadata, event.Metadata == nil)
+	if err != nil {
+		return errors.Wrap(err, "auditRepo.Create.marshalJSON.metadata")
+	}
+	before, err := marshalJSON(event.Before, event.Before == nil)
+	if err != nil {
+		return errors.Wrap(err, "auditRepo.Create.marshalJSON.before")
+	}
+	after, err := marshalJSON(event.After, event.After == nil)
+	if err != nil {
+		return errors.Wrap(err, "auditRepo.Create.marshalJSON.after")
+	}
+
+	if _, err = r.db.ExecContext(
+		ctx,
+		createAuditEvent,
+		event.Action,
+		event.ActorID,
+		event.TargetType,
+		event.TargetID,
+		event.IPAddress,
+		event.UserAgent,
+		event.RequestID,
+		metadata,
+		before,
+		after,
+		event.CreatedAt,
+	); err != nil {
+		return errors.Wrap(err, "auditRepo.Create.ExecContext")
+	}
+
+	return nil
+}
+
+// Get audit events matching filter, newest first
+func (r *auditRepo) GetEvents(ctx context.Context, filter *models.AuditEventsFilter, pq *utils.PaginationQuery) (*models.AuditEventsList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditRepo.GetEvents")
+	defer span.Finish()
+
+	args := filterArgs(filter)
+
+	var totalCount int
+	if err := r.db.GetContext(ctx, &totalCount, getAuditEventsTotalCount, args...); err != nil {
+		return nil, errors.Wrap(err, "auditRepo.GetEvents.GetContext.totalCount")
+	}
+
+	if totalCount == 0 {
+		return &models.AuditEventsList{
+			TotalCount: totalCount,
+			TotalPages: utils.GetTotalPages(totalCount, pq.GetSize()),
+			Page:       pq.GetPage(),
+			Size:       pq.GetSize(),
+			HasMore:    utils.GetHasMore(pq.GetPage(), totalCount, pq.GetSize()),
+			Events:     make([]*models.AuditEvent, 0),
+		}, nil
+	}
+
+	rows, err := r.db.QueryxContext(ctx, getAuditEvents, append(args, pq.GetOffset(), pq.GetLimit())...)
+	if err != nil {
+		return nil, errors.Wrap(err, "auditRepo.GetEvents.QueryxContext")
+	}
+	defer rows.Close()
+
+	events := make([]*models.AuditEvent, 0, pq.GetSize())
+	for rows.Next() {
+		row := &auditEventRow{}
+		if err = rows.StructScan(row); err != nil {
+			return nil, errors.Wrap(err, "auditRepo.GetEvents.StructScan")
+		}
+		event, err := row.toEvent()
+		if err != nil {
+			return nil, errors.Wrap(err, "auditRepo.GetEvents.toEvent")
+		}
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "auditRepo.GetEvents.rows.Err")
+	}
+
+	return &models.AuditEventsList{
+		TotalCount: totalCount,
+		TotalPages: utils.GetTotalPages(totalCount, pq.GetSize()),
+		Page:       pq.GetPage(),
+		Size:       pq.GetSize(),
+		HasMore:    utils.GetHasMore(pq.GetPage(), totalCount, pq.GetSize()),
+		Events:     events,
+	}, nil
+}
+
+func (row *auditEventRow) toEvent() (*models.AuditEvent, error) {
+	event := row.AuditEvent
+	if len(row.MetadataData) > 0 {
+		if err := json.Unmarshal(row.MetadataData, &event.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	if len(row.BeforeData) > 0 {
+		if err := json.Unmarshal(row.BeforeData, &event.Before); err != nil {
+			return nil, err
+		}
+	}
+	if len(row.AfterData) > 0 {
+		if err := json.Unmarshal(row.AfterData, &event.After); err != nil {
+			return nil, err
+		}
+	}
+	return &event, nil
+}
+
+// Positional filter arguments, nil pointers are passed as NULL
+func filterArgs(filter *models.AuditEventsFilter) []interface{} {
+	return []interface{}{filter.ActorID, filter.TargetType, filter.TargetID, filter.Action, filter.From, filter.To}
+}
+
+// Marshal json column value, NULL when empty
+func marshalJSON(v interface{}, empty bool) (sql.NullString, error) {
+	if empty {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+func TestAuditRepo_Create(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	auditRepo := NewAuditRepository(sqlxDB)
+
+	event := &models.AuditEvent{
+		Action:     models.AuditActionUpdate,
+		ActorID:    uuid.New(),
+		TargetType: models.AuditTargetUser,
+		TargetID:   uuid.New().String(),
+		IPAddress:  "127.0.0.1",
+		UserAgent:  "agent",
+		RequestID:  "request",
+		Before:     map[string]interface{}{"role": "user"},
+		After:      map[string]interface{}{"role": "admin"},
+		CreatedAt:  time.Now(),
+	}
+
+	mock.ExpectExec(createAuditEvent).WithArgs(
+		event.Action,
+		event.ActorID,
+		event.TargetType,
+		event.TargetID,
+		event.IPAddress,
+		event.UserAgent,
+		event.RequestID,
+		nil,
+		`{"role":"user"}`,
+		`{"role":"admin"}`,
+		event.CreatedAt,
+	).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = auditRepo.Create(context.Background(), event)
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditRepo_GetEvents(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	auditRepo := NewAuditRepository(sqlxDB)
+
+	actorID := uuid.New()
+	from := time.Now().Add(-time.Hour)
+	filter := &models.AuditEventsFilter{ActorID: &actorID, Action: models.AuditActionDelete, From: &from}
+	pq := &utils.PaginationQuery{Size: 10, Page: 1}
+
+	totalCountRows := sqlmock.NewRows([]string{"count"}).AddRow(1)
+	rows := sqlmock.NewRows([]string{"event_id", "action", "actor_id", "target_type", "target_id", "ip_address", "user_agent",
+		"request_id", "metadata", "before_data", "after_data", "created_at"}).
+		AddRow(uuid.New(), models.AuditActionDelete, actorID, models.AuditTargetUser, "target", "127.0.0.1", "agent",
+			"request", []byte(`{"impersonator_id":"admin"}`), nil, nil, time.Now())
+
+	mock.ExpectQuery(getAuditEventsTotalCount).
+		WithArgs(actorID, "", "", models.AuditActionDelete, from, nil).
+		WillReturnRows(totalCountRows)
+	mock.ExpectQuery(getAuditEvents).
+		WithArgs(actorID, "", "", models.AuditActionDelete, from, nil, 0, 10).
+		WillReturnRows(rows)
+
+	eventsList, err := auditRepo.GetEvents(context.Background(), filter, pq)
+	require.NoError(t, err)
+	require.Equal(t, 1, eventsList.TotalCount)
+	require.Len(t, eventsList.Events, 1)
+	require.Equal(t, actorID, eventsList.Events[0].ActorID)
+	require.Equal(t, "admin", eventsList.Events[0].Metadata["impersonator_id"])
+	require.Nil(t, eventsList.Events[0].Before)
+}
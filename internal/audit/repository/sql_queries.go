@@ -0,0 +1,21 @@
+package repository
+
+const (
+	createAuditEvent = `INSERT INTO audit_events (action, actor_id, target_type, target_id, ip_address, user_agent, request_id, 
+                          metadata, before_data, after_data, created_at) 
+						VALUES ($1, NULLIF($2, '00000000-0000-0000-0000-000000000000'::uuid), $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	auditEventsFilter = `WHERE ($1::uuid IS NULL OR actor_id = $1)
+  AND ($2 = '' OR target_type = $2)
+  AND ($3 = '' OR target_id = $3)
+  AND ($4 = '' OR action = $4)
+  AND ($5::timestamptz IS NULL OR created_at >= $5)
+  AND ($6::timestamptz IS NULL OR created_at < $6)`
+
+	getAuditEventsTotalCount = `SELECT COUNT(event_id) FROM audit_events ` + auditEventsFilter
+
+	getAuditEvents = `SELECT event_id, action, COALESCE(actor_id, '00000000-0000-0000-0000-000000000000'::uuid) AS actor_id, 
+       target_type, target_id, ip_address, user_agent, request_id, metadata, before_data, after_data, created_at 
+FROM audit_events ` + auditEventsFilter + `
+ORDER BY created_at DESC, event_id OFFSET $7 LIMIT $8`
+)
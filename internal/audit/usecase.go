@@ -0,0 +1,15 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+package audit
+
+import (
+	"context"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+// Audit use case interface
+type UseCase interface {
+	Record(ctx context.Context, event *models.AuditEvent) error
+	GetEvents(ctx context.Context, filter *models.AuditEventsFilter, pq *utils.PaginationQuery) (*models.AuditEventsList, error)
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/audit"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+// Fields never stored in before and after snapshots
+var sensitiveFields = map[string]bool{
+	"password": true,
+}
+
+// Audit UseCase
+type auditUC struct {
+	cfg       *config.Config
+	auditRepo audit.Repository
+	logger    logger.Logger
+}
+
+// Audit UseCase constructor
+func NewAuditUseCase(cfg *config.Config, auditRepo audit.Repository, log logger.Logger) audit.UseCase {
+	return &auditUC{cfg: cfg, auditRepo: auditRepo, logger: log}
+}
+
+// Persist audit event. Actor and client info missing in event are taken from request context,
+// before and after snapshots are reduced to changed fields.
+func (u *auditUC) Record(ctx context.Context, event *models.AuditEvent) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditUC.Record")
+	defer span.Finish()
+
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	fillFromCtx(ctx, event)
+
+	if event.Before != nil || event.After != nil {
+		before, after, err := diffFields(event.Before, event.After)
+		if err != nil {
+			return errors.Wrap(err, "auditUC.Record.diffFields")
+		}
+		event.Before, event.After = before, after
+	}
+
+	if err := u.auditRepo.Create(ctx, event); err != nil {
+		// Keep event in logs so it is not lost
+		eventBytes, _ := json.Marshal(event)
+		u.logger.Errorf("auditUC.Record.Create Event: %s, Error: %v", string(eventBytes), err)
+		return err
+	}
+
+	return nil
+}
+
+// Get audit events matching filter
+func (u *auditUC) GetEvents(ctx context.Context, filter *models.AuditEventsFilter, pq *utils.PaginationQuery) (*models.AuditEventsList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "auditUC.GetEvents")
+	defer span.Finish()
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, httpErrors.NewRestError(http.StatusBadRequest, httpErrors.BadQueryParams.Error(), errors.New("auditUC.GetEvents: from must be before to"))
+	}
+
+	return u.auditRepo.GetEvents(ctx, filter, pq)
+}
+
+func fillFromCtx(ctx context.Context, event *models.AuditEvent) {
+	if event.ActorID == uuid.Nil {
+		if user, err := utils.GetUserFromCtx(ctx); err == nil {
+			event.ActorID = user.UserID
+		}
+	}
+	if event.IPAddress == "" {
+		event.IPAddress = utils.GetIPAddressFromCtx(ctx)
+	}
+	if event.UserAgent == "" {
+		event.UserAgent = utils.GetUserAgentFromCtx(ctx)
+	}
+	if event.RequestID == "" {
+		event.RequestID = utils.GetRequestIDFromCtx(ctx)
+	}
+	if impersonatorID := utils.GetImpersonatorIDFromCtx(ctx); impersonatorID != nil {
+		if event.Metadata == nil {
+			event.Metadata = make(map[string]interface{}, 1)
+		}
+		event.Metadata["impersonator_id"] = impersonatorID.String()
+	}
+}
+
+// Json fields of before and after which differ, sensitive fields are dropped. Nil snapshot is kept nil.
+func diffFields(before interface{}, after interface{}) (map[string]interface{}, map[string]interface{}, error) {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterFields, err := toFields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if beforeFields != nil && afterFields != nil {
+		for field, value := range beforeFields {
+			if afterValue, ok := afterFields[field]; ok && reflect.DeepEqual(value, afterValue) {
+				delete(beforeFields, field)
+				delete(afterFields, field)
+			}
+		}
+	}
+
+	return beforeFields, afterFields, nil
+}
+
+func toFields(v interface{}) (map[string]interface{}, error) {
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for field := range fields {
+		if sensitiveFields[field] {
+			delete(fields, field)
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/audit/mock"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+func TestAuditUC_Record(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Logger: config.Logger{
+			Development: true,
+			Encoding:    "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	apiLogger.InitLogger()
+	mockAuditRepo := mock.NewMockRepository(ctrl)
+	auditUC := NewAuditUseCase(cfg, mockAuditRepo, apiLogger)
+
+	user := &models.User{UserID: uuid.New()}
+	impersonatorID := uuid.New()
+	ctx := context.WithValue(context.Background(), utils.UserCtxKey{}, user)
+	ctx = context.WithValue(ctx, utils.ImpersonatorCtxKey{}, impersonatorID)
+	ctx = context.WithValue(ctx, utils.IPAddressCtxKey{}, "127.0.0.1")
+	ctx = context.WithValue(ctx, utils.ReqIDCtxKey{}, "request")
+
+	t.Run("Fills actor from context and keeps changed fields", func(t *testing.T) {
+		userRole, adminRole := "user", "admin"
+		event := &models.AuditEvent{
+			Action:     models.AuditActionUpdate,
+			TargetType: models.AuditTargetUser,
+			TargetID:   user.UserID.String(),
+			Before:     &models.User{UserID: user.UserID, FirstName: "Alex", Role: &userRole, Password: "old"},
+			After:      &models.User{UserID: user.UserID, FirstName: "Alex", Role: &adminRole, Password: "new"},
+		}
+
+		mockAuditRepo.EXPECT().Create(gomock.Any(), event).Return(nil)
+
+		err := auditUC.Record(ctx, event)
+		require.NoError(t, err)
+		require.Equal(t, user.UserID, event.ActorID)
+		require.Equal(t, "127.0.0.1", event.IPAddress)
+		require.Equal(t, "request", event.RequestID)
+		require.Equal(t, impersonatorID.String(), event.Metadata["impersonator_id"])
+		require.Equal(t, map[string]interface{}{"role": "user"}, event.Before)
+		require.Equal(t, map[string]interface{}{"role": "admin"}, event.After)
+		require.False(t, event.CreatedAt.IsZero())
+	})
+
+	t.Run("Explicit actor is kept", func(t *testing.T) {
+		actorID := uuid.New()
+		event := &models.AuditEvent{Action: models.AuditActionLogin, ActorID: actorID}
+
+		mockAuditRepo.EXPECT().Create(gomock.Any(), event).Return(nil)
+
+		err := auditUC.Record(context.Background(), event)
+		require.NoError(t, err)
+		require.Equal(t, actorID, event.ActorID)
+		require.Nil(t, event.Metadata)
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		event := &models.AuditEvent{Action: models.AuditActionDelete}
+
+		mockAuditRepo.EXPECT().Create(gomock.Any(), event).Return(errors.New("insert failed"))
+
+		err := auditUC.Record(ctx, event)
+		require.Error(t, err)
+	})
+}
+
+func TestAuditUC_GetEvents(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiLogger := logger.NewApiLogger(nil)
+	mockAuditRepo := mock.NewMockRepository(ctrl)
+	auditUC := NewAuditUseCase(nil, mockAuditRepo, apiLogger)
+
+	pq := &utils.PaginationQuery{Size: 10, Page: 1}
+	from := time.Now()
+	to := from.Add(-time.Hour)
+
+	eventsList, err := auditUC.GetEvents(context.Background(), &models.AuditEventsFilter{From: &from, To: &to}, pq)
+	require.Error(t, err)
+	require.Nil(t, eventsList)
+
+	filter := &models.AuditEventsFilter{Action: models.AuditActionDelete}
+	mockAuditRepo.EXPECT().GetEvents(gomock.Any(), filter, pq).Return(&models.AuditEventsList{}, nil)
+
+	eventsList, err = auditUC.GetEvents(context.Background(), filter, pq)
+	require.NoError(t, err)
+	require.NotNil(t, eventsList)
+}
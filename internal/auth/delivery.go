@@ -9,10 +9,44 @@ type Handlers interface {
 	Logout() echo.HandlerFunc
 	Update() echo.HandlerFunc
 	Delete() echo.HandlerFunc
+	Restore() echo.HandlerFunc
 	GetUserByID() echo.HandlerFunc
 	FindByName() echo.HandlerFunc
+	FindSuggestions() echo.HandlerFunc
 	GetUsers() echo.HandlerFunc
 	GetMe() echo.HandlerFunc
 	UploadAvatar() echo.HandlerFunc
 	GetCSRFToken() echo.HandlerFunc
+	RefreshToken() echo.HandlerFunc
+	ForgotPassword() echo.HandlerFunc
+	ResetPassword() echo.HandlerFunc
+	ChangePassword() echo.HandlerFunc
+	VerifyEmail() echo.HandlerFunc
+	ResendVerification() echo.HandlerFunc
+	ChangeEmail() echo.HandlerFunc
+	ConfirmEmailChange() echo.HandlerFunc
+	RevertEmailChange() echo.HandlerFunc
+	LoginTwoFactor() echo.HandlerFunc
+	EnrollTOTP() echo.HandlerFunc
+	ConfirmTOTP() echo.HandlerFunc
+	DisableTOTP() echo.HandlerFunc
+	Unlock() echo.HandlerFunc
+	UpdateStatus() echo.HandlerFunc
+	Impersonate() echo.HandlerFunc
+	StopImpersonation() echo.HandlerFunc
+	GetRoles() echo.HandlerFunc
+	SendMagicLink() echo.HandlerFunc
+	MagicLinkCallback() echo.HandlerFunc
+	OIDCLogin() echo.HandlerFunc
+	OIDCCallback() echo.HandlerFunc
+	UpdateRole() echo.HandlerFunc
+	GetJWKS() echo.HandlerFunc
+	CreateAPIKey() echo.HandlerFunc
+	GetAPIKeys() echo.HandlerFunc
+	GetAPIKeyByID() echo.HandlerFunc
+	UpdateAPIKey() echo.HandlerFunc
+	DeleteAPIKey() echo.HandlerFunc
+	GetSessions() echo.HandlerFunc
+	DeleteSession() echo.HandlerFunc
+	DeleteSessions() echo.HandlerFunc
 }
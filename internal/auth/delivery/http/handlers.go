@@ -2,8 +2,13 @@ package http
 
 import (
 	"bytes"
+	"context"
+	"crypto/subtle"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -11,6 +16,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/audit"
 	"github.com/AleksK1NG/api-mc/internal/auth"
 	"github.com/AleksK1NG/api-mc/internal/models"
 	"github.com/AleksK1NG/api-mc/internal/session"
@@ -20,240 +26,1389 @@ import (
 	"github.com/AleksK1NG/api-mc/pkg/utils"
 )
 
+const (
+	retryAfterHeader = "Retry-After"
+	// Cookie binding OpenID Connect state to the browser which started the login
+	oidcStateCookie = "oidc_state"
+)
+
 // Auth handlers
 type authHandlers struct {
-	cfg    *config.Config
-	authUC auth.UseCase
-	sessUC session.UCSession
-	logger logger.Logger
+	cfg     *config.Config
+	authUC  auth.UseCase
+	sessUC  session.UCSession
+	auditUC audit.UseCase
+	logger  logger.Logger
+}
+
+// NewAuthHandlers Auth handlers constructor
+func NewAuthHandlers(cfg *config.Config, authUC auth.UseCase, sessUC session.UCSession, auditUC audit.UseCase, log logger.Logger) auth.Handlers {
+	return &authHandlers{cfg: cfg, authUC: authUC, sessUC: sessUC, auditUC: auditUC, logger: log}
+}
+
+// Register godoc
+// @Summary Register new user
+// @Description register new user, returns user and token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 201 {object} models.User
+// @Router /auth/register [post]
+func (h *authHandlers) Register() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "auth.Register")
+		defer span.Finish()
+
+		user := &models.User{}
+		if err := utils.ReadRequest(c, user); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		createdUser, err := h.authUC.Register(ctx, user)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		sess, err := h.sessUC.CreateSession(ctx, h.newSession(c, createdUser.User.UserID), h.cfg.Session.Expire)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		c.SetCookie(utils.CreateSessionCookie(h.cfg, sess))
+
+		return c.JSON(http.StatusCreated, createdUser)
+	}
+}
+
+// Login godoc
+// @Summary Login new user
+// @Description login user, returns user and set session, or two factor challenge if it is enabled
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.User
+// @Success 202 {object} models.UserWithToken
+// @Failure 429 {object} httpErrors.LockoutError
+// @Router /auth/login [post]
+func (h *authHandlers) Login() echo.HandlerFunc {
+	type Login struct {
+		Email    string `json:"email" db:"email" validate:"omitempty,lte=60,email"`
+		Password string `json:"password,omitempty" db:"password" validate:"required,gte=6"`
+	}
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "auth.Login")
+		defer span.Finish()
+
+		login := &Login{}
+		if err := utils.ReadRequest(c, login); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		userWithToken, err := h.authUC.Login(ctx, &models.User{
+			Email:    login.Email,
+			Password: login.Password,
+		}, utils.GetClientIP(c))
+		if err != nil {
+			var lockoutErr httpErrors.LockoutError
+			if errors.As(err, &lockoutErr) {
+				c.Response().Header().Set(retryAfterHeader, strconv.Itoa(lockoutErr.RetryAfter))
+			}
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		if userWithToken.TwoFactorRequired {
+			return c.JSON(http.StatusAccepted, userWithToken)
+		}
+
+		sess, err := h.sessUC.CreateSession(ctx, h.newSession(c, userWithToken.User.UserID), h.cfg.Session.Expire)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		c.SetCookie(utils.CreateSessionCookie(h.cfg, sess))
+
+		return c.JSON(http.StatusOK, userWithToken)
+	}
+}
+
+// SendMagicLink godoc
+// @Summary Send magic login link
+// @Description send single use login link to user email
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {string} string	"ok"
+// @Failure 429 {object} httpErrors.LockoutError
+// @Router /auth/magic-link [post]
+func (h *authHandlers) SendMagicLink() echo.HandlerFunc {
+	type MagicLink struct {
+		Email string `json:"email" validate:"required,lte=60,email"`
+	}
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.SendMagicLink")
+		defer span.Finish()
+
+		magicLink := &MagicLink{}
+		if err := utils.ReadRequest(c, magicLink); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		if err := h.authUC.SendMagicLink(ctx, magicLink.Email, utils.GetClientIP(c)); err != nil {
+			var lockoutErr httpErrors.LockoutError
+			if errors.As(err, &lockoutErr) {
+				c.Response().Header().Set(retryAfterHeader, strconv.Itoa(lockoutErr.RetryAfter))
+			}
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// MagicLinkCallback godoc
+// @Summary Login with magic link
+// @Description exchange magic link token for session, returns user and set session
+// @Tags Auth
+// @Produce json
+// @Param token query string true "magic link token"
+// @Success 200 {object} models.UserWithToken
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/magic-link/callback [get]
+func (h *authHandlers) MagicLinkCallback() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.MagicLinkCallback")
+		defer span.Finish()
+
+		loginToken := c.QueryParam("token")
+		if loginToken == "" {
+			utils.LogResponseError(c, h.logger, httpErrors.InvalidMagicLink)
+			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.InvalidMagicLink))
+		}
+
+		userWithToken, err := h.authUC.LoginMagicLink(ctx, loginToken)
+		if err != nil {
+			var lockoutErr httpErrors.LockoutError
+			if errors.As(err, &lockoutErr) {
+				c.Response().Header().Set(retryAfterHeader, strconv.Itoa(lockoutErr.RetryAfter))
+			}
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		if userWithToken.TwoFactorRequired {
+			return c.JSON(http.StatusAccepted, userWithToken)
+		}
+
+		sess, err := h.sessUC.CreateSession(ctx, h.newSession(c, userWithToken.User.UserID), h.cfg.Session.Expire)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		c.SetCookie(utils.CreateSessionCookie(h.cfg, sess))
+
+		return c.JSON(http.StatusOK, userWithToken)
+	}
+}
+
+// OIDCLogin godoc
+// @Summary OpenID Connect login
+// @Description redirect to identity provider authorization endpoint
+// @Tags Auth
+// @Param provider path string true "provider name"
+// @Success 302 {string} string "redirect"
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/oidc/{provider} [get]
+func (h *authHandlers) OIDCLogin() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.OIDCLogin")
+		defer span.Finish()
+
+		authURL, state, err := h.authUC.OIDCAuthURL(ctx, c.Param("provider"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		c.SetCookie(&http.Cookie{
+			Name:     oidcStateCookie,
+			Value:    state,
+			Path:     "/",
+			MaxAge:   h.cfg.OIDC.StateExpire,
+			Secure:   h.cfg.Cookie.Secure,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+
+		return c.Redirect(http.StatusFound, authURL)
+	}
+}
+
+// OIDCCallback godoc
+// @Summary OpenID Connect callback
+// @Description exchange authorization code, returns user and set session
+// @Tags Auth
+// @Produce json
+// @Param provider path string true "provider name"
+// @Param state query string true "state"
+// @Param code query string true "authorization code"
+// @Success 200 {object} models.UserWithToken
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/oidc/{provider}/callback [get]
+func (h *authHandlers) OIDCCallback() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.OIDCCallback")
+		defer span.Finish()
+
+		if providerErr := c.QueryParam("error"); providerErr != "" {
+			err := httpErrors.NewUnauthorizedError(errors.Errorf("authHandlers.OIDCCallback provider error: %s", providerErr))
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		state := c.QueryParam("state")
+		stateCookie, err := c.Cookie(oidcStateCookie)
+		if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(stateCookie.Value), []byte(state)) != 1 {
+			utils.LogResponseError(c, h.logger, httpErrors.InvalidOIDCState)
+			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.InvalidOIDCState))
+		}
+		c.SetCookie(&http.Cookie{Name: oidcStateCookie, Value: "", Path: "/", MaxAge: -1})
+
+		userWithToken, err := h.authUC.OIDCLogin(ctx, c.Param("provider"), state, c.QueryParam("code"))
+		if err != nil {
+			var lockoutErr httpErrors.LockoutError
+			if errors.As(err, &lockoutErr) {
+				c.Response().Header().Set(retryAfterHeader, strconv.Itoa(lockoutErr.RetryAfter))
+			}
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		if userWithToken.TwoFactorRequired {
+			return c.JSON(http.StatusAccepted, userWithToken)
+		}
+
+		sess, err := h.sessUC.CreateSession(ctx, h.newSession(c, userWithToken.User.UserID), h.cfg.Session.Expire)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		c.SetCookie(utils.CreateSessionCookie(h.cfg, sess))
+
+		return c.JSON(http.StatusOK, userWithToken)
+	}
+}
+
+// LoginTwoFactor godoc
+// @Summary Login second step
+// @Description verify TOTP or recovery code for login challenge, returns user and set session
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.UserWithToken
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/login/2fa [post]
+func (h *authHandlers) LoginTwoFactor() echo.HandlerFunc {
+	type TwoFactor struct {
+		Challenge string `json:"challenge" validate:"required"`
+		Code      string `json:"code" validate:"required,lte=16"`
+	}
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.LoginTwoFactor")
+		defer span.Finish()
+
+		twoFactor := &TwoFactor{}
+		if err := utils.ReadRequest(c, twoFactor); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		userWithToken, err := h.authUC.LoginTwoFactor(ctx, twoFactor.Challenge, twoFactor.Code)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		sess, err := h.sessUC.CreateSession(ctx, h.newSession(c, userWithToken.User.UserID), h.cfg.Session.Expire)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		c.SetCookie(utils.CreateSessionCookie(h.cfg, sess))
+
+		return c.JSON(http.StatusOK, userWithToken)
+	}
+}
+
+// RefreshToken godoc
+// @Summary Refresh tokens
+// @Description rotate refresh token, returns user with new jwt and refresh tokens
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.UserWithToken
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/refresh [post]
+func (h *authHandlers) RefreshToken() echo.HandlerFunc {
+	type Refresh struct {
+		RefreshToken string `json:"refresh_token" validate:"required"`
+	}
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.RefreshToken")
+		defer span.Finish()
+
+		refresh := &Refresh{}
+		if err := utils.ReadRequest(c, refresh); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		userWithToken, err := h.authUC.RefreshTokens(ctx, refresh.RefreshToken)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, userWithToken)
+	}
+}
+
+// ForgotPassword godoc
+// @Summary Forgot password
+// @Description send password reset link to the user email, always responds ok
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {string} string	"ok"
+// @Router /auth/password/forgot [post]
+func (h *authHandlers) ForgotPassword() echo.HandlerFunc {
+	type Forgot struct {
+		Email string `json:"email" validate:"required,lte=60,email"`
+	}
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.ForgotPassword")
+		defer span.Finish()
+
+		forgot := &Forgot{}
+		if err := utils.ReadRequest(c, forgot); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		if err := h.authUC.ForgotPassword(ctx, forgot.Email); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description set new password using reset token, invalidates all user sessions
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {string} string	"ok"
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/password/reset [post]
+func (h *authHandlers) ResetPassword() echo.HandlerFunc {
+	type Reset struct {
+		Token    string `json:"token" validate:"required"`
+		Password string `json:"password" validate:"required,gte=6"`
+	}
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.ResetPassword")
+		defer span.Finish()
+
+		reset := &Reset{}
+		if err := utils.ReadRequest(c, reset); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		if err := h.authUC.ResetPassword(ctx, reset.Token, reset.Password); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// VerifyEmail godoc
+// @Summary Verify email
+// @Description confirm user email address using verification token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param token query string true "verification token"
+// @Success 200 {string} string	"ok"
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/verify [get]
+func (h *authHandlers) VerifyEmail() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.VerifyEmail")
+		defer span.Finish()
+
+		verifyToken := c.QueryParam("token")
+		if verifyToken == "" {
+			utils.LogResponseError(c, h.logger, httpErrors.InvalidVerifyToken)
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(httpErrors.InvalidVerifyToken))
+		}
+
+		if err := h.authUC.VerifyEmail(ctx, verifyToken); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description change password of current user, current password is required, other sessions and refresh tokens except the given one are logged out
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param user_id path int true "user_id"
+// @Success 200 {string} string	"ok"
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 401 {object} httpErrors.RestError
+// @Failure 403 {object} httpErrors.RestError
+// @Router /auth/{user_id}/password [put]
+func (h *authHandlers) ChangePassword() echo.HandlerFunc {
+	type ChangePassword struct {
+		CurrentPassword string `json:"current_password" validate:"required"`
+		NewPassword     string `json:"new_password" validate:"required,gte=6"`
+		RefreshToken    string `json:"refresh_token,omitempty"`
+	}
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.ChangePassword")
+		defer span.Finish()
+
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
+		}
+
+		if user.UserID.String() != c.Param("user_id") {
+			utils.LogResponseError(c, h.logger, httpErrors.Forbidden)
+			return c.JSON(http.StatusForbidden, httpErrors.NewForbiddenError(httpErrors.Forbidden))
+		}
+
+		changePassword := &ChangePassword{}
+		if err = utils.ReadRequest(c, changePassword); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		currentSessionID, _ := c.Get("uid").(string)
+		if err = h.authUC.ChangePassword(ctx, user.UserID, changePassword.CurrentPassword, changePassword.NewPassword, currentSessionID, changePassword.RefreshToken); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// ChangeEmail godoc
+// @Summary Change email
+// @Description send confirmation link to new email, current password is required
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {string} string	"ok"
+// @Failure 400 {object} httpErrors.RestError
+// @Router /auth/email [post]
+func (h *authHandlers) ChangeEmail() echo.HandlerFunc {
+	type ChangeEmail struct {
+		Email    string `json:"email" validate:"required,lte=60,email"`
+		Password string `json:"password" validate:"required,gte=6"`
+	}
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.ChangeEmail")
+		defer span.Finish()
+
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
+		}
+
+		changeEmail := &ChangeEmail{}
+		if err = utils.ReadRequest(c, changeEmail); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		if err = h.authUC.ChangeEmail(ctx, user.UserID, changeEmail.Email, changeEmail.Password); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// ConfirmEmailChange godoc
+// @Summary Confirm email change
+// @Description replace user email with pending email using token sent to the new address
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param token query string true "email change token"
+// @Success 200 {string} string	"ok"
+// @Failure 401 {object} httpErrors.RestError
+// @Failure 409 {object} httpErrors.RestError
+// @Router /auth/email/confirm [get]
+func (h *authHandlers) ConfirmEmailChange() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.ConfirmEmailChange")
+		defer span.Finish()
+
+		changeToken := c.QueryParam("token")
+		if changeToken == "" {
+			utils.LogResponseError(c, h.logger, httpErrors.InvalidEmailChange)
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(httpErrors.InvalidEmailChange))
+		}
+
+		if err := h.authUC.ConfirmEmailChange(ctx, changeToken); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// RevertEmailChange godoc
+// @Summary Revert email change
+// @Description restore previous email using token sent to it and log out all sessions
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param token query string true "email revert token"
+// @Success 200 {string} string	"ok"
+// @Failure 401 {object} httpErrors.RestError
+// @Failure 409 {object} httpErrors.RestError
+// @Router /auth/email/revert [get]
+func (h *authHandlers) RevertEmailChange() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.RevertEmailChange")
+		defer span.Finish()
+
+		revertToken := c.QueryParam("token")
+		if revertToken == "" {
+			utils.LogResponseError(c, h.logger, httpErrors.InvalidEmailChange)
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(httpErrors.InvalidEmailChange))
+		}
+
+		if err := h.authUC.RevertEmailChange(ctx, revertToken); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// ResendVerification godoc
+// @Summary Resend verification email
+// @Description send new email verification link, always responds ok
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {string} string	"ok"
+// @Router /auth/verify/resend [post]
+func (h *authHandlers) ResendVerification() echo.HandlerFunc {
+	type Resend struct {
+		Email string `json:"email" validate:"required,lte=60,email"`
+	}
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.ResendVerification")
+		defer span.Finish()
+
+		resend := &Resend{}
+		if err := utils.ReadRequest(c, resend); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		if err := h.authUC.ResendVerification(ctx, resend.Email); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// Logout godoc
+// @Summary Logout user
+// @Description logout user removing session
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Success 200 {string} string	"ok"
+// @Router /auth/logout [post]
+func (h *authHandlers) Logout() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.Logout")
+		defer span.Finish()
+
+		cookie, err := c.Cookie("session-id")
+		if err != nil {
+			if errors.Is(err, http.ErrNoCookie) {
+				utils.LogResponseError(c, h.logger, err)
+				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
+			}
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(http.StatusInternalServerError, httpErrors.NewInternalServerError(err))
+		}
+
+		sess, err := h.sessUC.GetSessionByID(ctx, cookie.Value)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		if err = h.sessUC.DeleteByID(ctx, cookie.Value); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		h.recordAuditEvent(c, h.newAuditEvent(c, models.AuditActionLogout, sess.UserID, sess.UserID.String()))
+
+		utils.DeleteSessionCookie(c, h.cfg.Session.Name)
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// Update godoc
+// @Summary Update user
+// @Description update existing user, owner updates profile fields, avatar and role require permission
+// @Tags Auth
+// @Accept json
+// @Param id path int true "user_id"
+// @Produce json
+// @Success 200 {object} models.User
+// @Failure 403 {object} httpErrors.RestError
+// @Router /auth/{id} [put]
+func (h *authHandlers) Update() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.Update")
+		defer span.Finish()
+
+		uID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		var user *models.User
+		if utils.HasPermission(ctx, models.PermissionUsersUpdate) {
+			input := &models.AdminUserUpdate{}
+			if err = utils.ReadRequest(c, input); err != nil {
+				utils.LogResponseError(c, h.logger, err)
+				return c.JSON(httpErrors.ErrorResponse(err))
+			}
+			user = input.ToUser(uID)
+		} else {
+			input := &models.UserUpdate{}
+			if err = utils.ReadRequest(c, input); err != nil {
+				utils.LogResponseError(c, h.logger, err)
+				return c.JSON(httpErrors.ErrorResponse(err))
+			}
+			user = input.ToUser(uID)
+		}
+
+		updatedUser, err := h.authUC.Update(ctx, user)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, updatedUser)
+	}
+}
+
+// GetUserByID godoc
+// @Summary get user by id
+// @Description get public user profile by ID, private account state is not included
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param id path int true "user_id"
+// @Success 200 {object} models.User
+// @Failure 500 {object} httpErrors.RestError
+// @Router /auth/{id} [get]
+func (h *authHandlers) GetUserByID() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.GetUserByID")
+		defer span.Finish()
+
+		uID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		user, err := h.authUC.GetByID(ctx, uID)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+		user.SanitizeAccountState()
+
+		return c.JSON(http.StatusOK, user)
+	}
+}
+
+// Delete
+// @Summary Delete user account
+// @Description soft delete user account and revoke user sessions, account can be restored during grace period
+// @Tags Auth
+// @Accept json
+// @Param id path int true "user_id"
+// @Produce json
+// @Success 200 {string} string	"ok"
+// @Failure 500 {object} httpErrors.RestError
+// @Router /auth/{id} [delete]
+func (h *authHandlers) Delete() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.Delete")
+		defer span.Finish()
+
+		uID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		if err = h.authUC.Delete(ctx, uID); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// Restore godoc
+// @Summary Restore deleted user account
+// @Description restore soft deleted user account during grace period
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param id path string true "user_id"
+// @Success 200 {object} models.User
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/{id}/restore [post]
+func (h *authHandlers) Restore() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.Restore")
+		defer span.Finish()
+
+		uID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		user, err := h.authUC.Restore(ctx, uID)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, user)
+	}
+}
+
+// Unlock godoc
+// @Summary Unlock user account
+// @Description unlock account locked after failed login attempts, admin only
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param id path string true "user_id"
+// @Success 200 {string} string	"ok"
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/{id}/unlock [post]
+func (h *authHandlers) Unlock() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.Unlock")
+		defer span.Finish()
+
+		uID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		if err = h.authUC.Unlock(ctx, uID); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// UpdateStatus godoc
+// @Summary Change user account status
+// @Description suspend, ban or reinstate user account, sessions of suspended or banned user are revoked
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param id path string true "user_id"
+// @Param status body models.UserStatus true "account status"
+// @Success 200 {string} string	"ok"
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/{id}/status [put]
+func (h *authHandlers) UpdateStatus() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.UpdateStatus")
+		defer span.Finish()
+
+		admin, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
+		}
+
+		uID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+		if uID == admin.UserID {
+			utils.LogResponseError(c, h.logger, httpErrors.Forbidden)
+			return c.JSON(http.StatusForbidden, httpErrors.NewForbiddenError(httpErrors.Forbidden))
+		}
+
+		status := &models.UserStatus{}
+		if err = utils.ReadRequest(c, status); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		if err = h.authUC.UpdateStatus(ctx, uID, status); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// Impersonate godoc
+// @Summary Impersonate user
+// @Description sign in as user for support, admin session is restored when impersonation stops
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param id path string true "user_id"
+// @Success 200 {object} models.User
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 403 {object} httpErrors.RestError
+// @Router /auth/{id}/impersonate [post]
+func (h *authHandlers) Impersonate() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.Impersonate")
+		defer span.Finish()
+
+		admin, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
+		}
+
+		uID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		user, err := h.authUC.Impersonate(ctx, admin.UserID, uID)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		impersonation := h.newSession(c, user.UserID)
+		impersonation.ImpersonatorID = &admin.UserID
+		impersonation.ImpersonatorSessionID, _ = c.Get("sid").(string)
+		sess, err := h.sessUC.CreateSession(ctx, impersonation, h.cfg.Session.ImpersonationExpire)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		cookie := utils.CreateSessionCookie(h.cfg, sess)
+		cookie.MaxAge = h.cfg.Session.ImpersonationExpire
+		c.SetCookie(cookie)
+
+		event := h.newAuditEvent(c, models.AuditActionImpersonationStart, admin.UserID, user.UserID.String())
+		event.Metadata = map[string]interface{}{"session_id": impersonation.ID}
+		h.recordAuditEvent(c, event)
+
+		return c.JSON(http.StatusOK, user)
+	}
+}
+
+// StopImpersonation godoc
+// @Summary Stop impersonation
+// @Description end impersonation session and restore admin session if it is still active
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {string} string	"ok"
+// @Failure 400 {object} httpErrors.RestError
+// @Router /auth/impersonate [delete]
+func (h *authHandlers) StopImpersonation() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.StopImpersonation")
+		defer span.Finish()
+
+		sid, _ := c.Get("sid").(string)
+		if sid == "" {
+			utils.LogResponseError(c, h.logger, httpErrors.NotImpersonating)
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(httpErrors.NotImpersonating))
+		}
+
+		impersonation, err := h.sessUC.GetSessionByID(ctx, sid)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
+		}
+		if !impersonation.IsImpersonation() {
+			utils.LogResponseError(c, h.logger, httpErrors.NotImpersonating)
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(httpErrors.NotImpersonating))
+		}
+
+		if err = h.sessUC.DeleteUserSession(ctx, impersonation.UserID, impersonation.ID); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		if h.isSessionOf(ctx, impersonation.ImpersonatorSessionID, *impersonation.ImpersonatorID) {
+			c.SetCookie(utils.CreateSessionCookie(h.cfg, impersonation.ImpersonatorSessionID))
+		} else {
+			utils.DeleteSessionCookie(c, h.cfg.Session.Name)
+		}
+
+		event := h.newAuditEvent(c, models.AuditActionImpersonationStop, *impersonation.ImpersonatorID, impersonation.UserID.String())
+		event.Metadata = map[string]interface{}{"session_id": impersonation.ID}
+		h.recordAuditEvent(c, event)
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// GetRoles godoc
+// @Summary Get roles
+// @Description get all roles with granted permissions
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Role
+// @Failure 403 {object} httpErrors.RestError
+// @Router /auth/roles [get]
+func (h *authHandlers) GetRoles() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.GetRoles")
+		defer span.Finish()
+
+		roles, err := h.authUC.GetRoles(ctx)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, roles)
+	}
+}
+
+// UpdateRole godoc
+// @Summary Assign user role
+// @Description assign role to user
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param id path string true "user_id"
+// @Success 200 {string} string	"ok"
+// @Failure 400 {object} httpErrors.RestError
+// @Router /auth/{id}/role [put]
+func (h *authHandlers) UpdateRole() echo.HandlerFunc {
+	type Role struct {
+		Role string `json:"role" validate:"required,lte=10"`
+	}
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.UpdateRole")
+		defer span.Finish()
+
+		uID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		role := &Role{}
+		if err = utils.ReadRequest(c, role); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		if err = h.authUC.UpdateRole(ctx, uID, role.Role); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
 }
 
-// NewAuthHandlers Auth handlers constructor
-func NewAuthHandlers(cfg *config.Config, authUC auth.UseCase, sessUC session.UCSession, log logger.Logger) auth.Handlers {
-	return &authHandlers{cfg: cfg, authUC: authUC, sessUC: sessUC, logger: log}
+// GetJWKS godoc
+// @Summary Get JWKS
+// @Description public keys for verification of issued JWT tokens
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} jwtkeys.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *authHandlers) GetJWKS() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.GetJWKS")
+		defer span.Finish()
+
+		return c.JSON(http.StatusOK, h.authUC.GetJWKS(ctx))
+	}
 }
 
-// Register godoc
-// @Summary Register new user
-// @Description register new user, returns user and token
+// CreateAPIKey godoc
+// @Summary Create API key
+// @Description create personal API key for machine clients, plain key is returned only once
 // @Tags Auth
 // @Accept json
 // @Produce json
-// @Success 201 {object} models.User
-// @Router /auth/register [post]
-func (h *authHandlers) Register() echo.HandlerFunc {
+// @Success 201 {object} models.APIKeyWithSecret
+// @Failure 400 {object} httpErrors.RestError
+// @Router /auth/keys [post]
+func (h *authHandlers) CreateAPIKey() echo.HandlerFunc {
+	type CreateKey struct {
+		Name      string     `json:"name" validate:"required,lte=64"`
+		Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=news:write comments:write"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
 	return func(c echo.Context) error {
-		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "auth.Register")
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.CreateAPIKey")
 		defer span.Finish()
 
-		user := &models.User{}
-		if err := utils.ReadRequest(c, user); err != nil {
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
+		}
+
+		createKey := &CreateKey{}
+		if err = utils.ReadRequest(c, createKey); err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		createdUser, err := h.authUC.Register(ctx, user)
+		apiKey, err := h.authUC.CreateAPIKey(ctx, &models.APIKey{
+			UserID:    user.UserID,
+			Name:      createKey.Name,
+			Scopes:    createKey.Scopes,
+			ExpiresAt: createKey.ExpiresAt,
+		})
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		sess, err := h.sessUC.CreateSession(ctx, &models.Session{
-			UserID: createdUser.User.UserID,
-		}, h.cfg.Session.Expire)
+		return c.JSON(http.StatusCreated, apiKey)
+	}
+}
+
+// GetAPIKeys godoc
+// @Summary Get API keys
+// @Description get all API keys of current user
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.APIKey
+// @Failure 500 {object} httpErrors.RestError
+// @Router /auth/keys [get]
+func (h *authHandlers) GetAPIKeys() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.GetAPIKeys")
+		defer span.Finish()
+
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
+		}
+
+		apiKeys, err := h.authUC.GetAPIKeys(ctx, user.UserID)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		c.SetCookie(utils.CreateSessionCookie(h.cfg, sess))
-
-		return c.JSON(http.StatusCreated, createdUser)
+		return c.JSON(http.StatusOK, apiKeys)
 	}
 }
 
-// Login godoc
-// @Summary Login new user
-// @Description login user, returns user and set session
+// GetAPIKeyByID godoc
+// @Summary Get API key
+// @Description get API key of current user by id
 // @Tags Auth
 // @Accept json
 // @Produce json
-// @Success 200 {object} models.User
-// @Router /auth/login [post]
-func (h *authHandlers) Login() echo.HandlerFunc {
-	type Login struct {
-		Email    string `json:"email" db:"email" validate:"omitempty,lte=60,email"`
-		Password string `json:"password,omitempty" db:"password" validate:"required,gte=6"`
-	}
+// @Param id path string true "key_id"
+// @Success 200 {object} models.APIKey
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/keys/{id} [get]
+func (h *authHandlers) GetAPIKeyByID() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "auth.Login")
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.GetAPIKeyByID")
 		defer span.Finish()
 
-		login := &Login{}
-		if err := utils.ReadRequest(c, login); err != nil {
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(httpErrors.ErrorResponse(err))
+			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
 		}
 
-		userWithToken, err := h.authUC.Login(ctx, &models.User{
-			Email:    login.Email,
-			Password: login.Password,
-		})
+		keyID, err := uuid.Parse(c.Param("key_id"))
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		sess, err := h.sessUC.CreateSession(ctx, &models.Session{
-			UserID: userWithToken.User.UserID,
-		}, h.cfg.Session.Expire)
+		apiKey, err := h.authUC.GetAPIKeyByID(ctx, user.UserID, keyID)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		c.SetCookie(utils.CreateSessionCookie(h.cfg, sess))
-
-		return c.JSON(http.StatusOK, userWithToken)
+		return c.JSON(http.StatusOK, apiKey)
 	}
 }
 
-// Logout godoc
-// @Summary Logout user
-// @Description logout user removing session
+// UpdateAPIKey godoc
+// @Summary Update API key
+// @Description update API key name and scopes
 // @Tags Auth
-// @Accept  json
-// @Produce  json
-// @Success 200 {string} string	"ok"
-// @Router /auth/logout [post]
-func (h *authHandlers) Logout() echo.HandlerFunc {
+// @Accept json
+// @Produce json
+// @Param id path string true "key_id"
+// @Success 200 {object} models.APIKey
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/keys/{id} [put]
+func (h *authHandlers) UpdateAPIKey() echo.HandlerFunc {
+	type UpdateKey struct {
+		Name   string   `json:"name" validate:"omitempty,lte=64"`
+		Scopes []string `json:"scopes" validate:"omitempty,dive,oneof=news:write comments:write"`
+	}
 	return func(c echo.Context) error {
-		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.Logout")
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.UpdateAPIKey")
 		defer span.Finish()
 
-		cookie, err := c.Cookie("session-id")
+		user, err := utils.GetUserFromCtx(ctx)
 		if err != nil {
-			if errors.Is(err, http.ErrNoCookie) {
-				utils.LogResponseError(c, h.logger, err)
-				return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
-			}
 			utils.LogResponseError(c, h.logger, err)
-			return c.JSON(http.StatusInternalServerError, httpErrors.NewInternalServerError(err))
+			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
 		}
 
-		if err := h.sessUC.DeleteByID(ctx, cookie.Value); err != nil {
+		keyID, err := uuid.Parse(c.Param("key_id"))
+		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		utils.DeleteSessionCookie(c, h.cfg.Session.Name)
+		updateKey := &UpdateKey{}
+		if err = utils.ReadRequest(c, updateKey); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
 
-		return c.NoContent(http.StatusOK)
+		apiKey, err := h.authUC.UpdateAPIKey(ctx, &models.APIKey{
+			APIKeyID: keyID,
+			UserID:   user.UserID,
+			Name:     updateKey.Name,
+			Scopes:   updateKey.Scopes,
+		})
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, apiKey)
 	}
 }
 
-// Update godoc
-// @Summary Update user
-// @Description update existing user
+// DeleteAPIKey godoc
+// @Summary Delete API key
+// @Description revoke API key of current user
 // @Tags Auth
 // @Accept json
-// @Param id path int true "user_id"
 // @Produce json
-// @Success 200 {object} models.User
-// @Router /auth/{id} [put]
-func (h *authHandlers) Update() echo.HandlerFunc {
+// @Param id path string true "key_id"
+// @Success 200 {string} string	"ok"
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/keys/{id} [delete]
+func (h *authHandlers) DeleteAPIKey() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.Update")
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.DeleteAPIKey")
 		defer span.Finish()
 
-		uID, err := uuid.Parse(c.Param("user_id"))
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
+		}
+
+		keyID, err := uuid.Parse(c.Param("key_id"))
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		user := &models.User{}
-		user.UserID = uID
+		if err = h.authUC.DeleteAPIKey(ctx, user.UserID, keyID); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// GetSessions godoc
+// @Summary Get sessions
+// @Description get active sessions of current user, or of given user for admin
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.SessionInfo
+// @Failure 500 {object} httpErrors.RestError
+// @Router /auth/sessions [get]
+func (h *authHandlers) GetSessions() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.GetSessions")
+		defer span.Finish()
 
-		if err = utils.ReadRequest(c, user); err != nil {
+		userID, err := h.getSessionsOwnerID(c)
+		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		updatedUser, err := h.authUC.Update(ctx, user)
+		sessions, err := h.sessUC.GetSessionsByUserID(ctx, userID)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		return c.JSON(http.StatusOK, updatedUser)
+		currentSessionID, _ := c.Get("uid").(string)
+		sessionsInfo := make([]*models.SessionInfo, 0, len(sessions))
+		for _, sess := range sessions {
+			sessionsInfo = append(sessionsInfo, &models.SessionInfo{
+				ID:           sess.ID,
+				IPAddress:    sess.IPAddress,
+				UserAgent:    sess.UserAgent,
+				CreatedAt:    sess.CreatedAt,
+				LastSeen:     sess.LastSeen,
+				Current:      sess.SessionID == currentSessionID,
+				Impersonated: sess.IsImpersonation(),
+			})
+		}
+
+		return c.JSON(http.StatusOK, sessionsInfo)
 	}
 }
 
-// GetUserByID godoc
-// @Summary get user by id
-// @Description get string by ID
+// DeleteSession godoc
+// @Summary Revoke session
+// @Description revoke session of current user by id, or of given user for admin
 // @Tags Auth
-// @Accept  json
-// @Produce  json
-// @Param id path int true "user_id"
-// @Success 200 {object} models.User
-// @Failure 500 {object} httpErrors.RestError
-// @Router /auth/{id} [get]
-func (h *authHandlers) GetUserByID() echo.HandlerFunc {
+// @Accept json
+// @Produce json
+// @Param id path string true "session id"
+// @Success 200 {string} string	"ok"
+// @Failure 404 {object} httpErrors.RestError
+// @Router /auth/sessions/{id} [delete]
+func (h *authHandlers) DeleteSession() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.GetUserByID")
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.DeleteSession")
 		defer span.Finish()
 
-		uID, err := uuid.Parse(c.Param("user_id"))
+		userID, err := h.getSessionsOwnerID(c)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		user, err := h.authUC.GetByID(ctx, uID)
-		if err != nil {
+		if err = h.sessUC.DeleteUserSession(ctx, userID, c.Param("id")); err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		return c.JSON(http.StatusOK, user)
+		return c.NoContent(http.StatusOK)
 	}
 }
 
-// Delete
-// @Summary Delete user account
-// @Description some description
+// DeleteSessions godoc
+// @Summary Revoke all sessions
+// @Description log out everywhere, revokes all sessions of current user, or of given user for admin
 // @Tags Auth
 // @Accept json
-// @Param id path int true "user_id"
 // @Produce json
 // @Success 200 {string} string	"ok"
 // @Failure 500 {object} httpErrors.RestError
-// @Router /auth/{id} [delete]
-func (h *authHandlers) Delete() echo.HandlerFunc {
+// @Router /auth/sessions [delete]
+func (h *authHandlers) DeleteSessions() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.Delete")
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.DeleteSessions")
 		defer span.Finish()
 
-		uID, err := uuid.Parse(c.Param("user_id"))
+		userID, err := h.getSessionsOwnerID(c)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		if err = h.authUC.Delete(ctx, uID); err != nil {
+		if err = h.sessUC.DeleteAllByUserID(ctx, userID); err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
+		if c.Param("user_id") == "" {
+			utils.DeleteSessionCookie(c, h.cfg.Session.Name)
+		}
+
 		return c.NoContent(http.StatusOK)
 	}
 }
 
 // FindByName godoc
 // @Summary Find by name
-// @Description Find user by name
+// @Description Fuzzy find users by full name, most similar first, tolerates typos, email and account state are not returned
 // @Tags Auth
 // @Accept json
 // @Param name query string false "username" Format(username)
@@ -287,9 +1442,51 @@ func (h *authHandlers) FindByName() echo.HandlerFunc {
 	}
 }
 
+// FindSuggestions godoc
+// @Summary Users typeahead
+// @Description Fuzzy find users by full name or email for mention pickers, returns only id, name and avatar
+// @Tags Auth
+// @Accept json
+// @Param q query string true "name or email part"
+// @Param limit query int false "max number of suggestions, 10 by default, at most 20"
+// @Produce json
+// @Success 200 {array} models.UserSuggestion
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/suggestions [get]
+func (h *authHandlers) FindSuggestions() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.FindSuggestions")
+		defer span.Finish()
+
+		name := strings.TrimSpace(c.QueryParam("q"))
+		if name == "" {
+			utils.LogResponseError(c, h.logger, httpErrors.NewBadRequestError("q is required"))
+			return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError("q is required"))
+		}
+
+		var limit int
+		if limitParam := c.QueryParam("limit"); limitParam != "" {
+			var err error
+			if limit, err = strconv.Atoi(limitParam); err != nil {
+				utils.LogResponseError(c, h.logger, err)
+				return c.JSON(http.StatusBadRequest, httpErrors.NewBadRequestError(httpErrors.BadQueryParams.Error()))
+			}
+		}
+
+		suggestions, err := h.authUC.FindSuggestions(ctx, name, limit)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, suggestions)
+	}
+}
+
 // GetUsers godoc
 // @Summary Get users
-// @Description Get the list of all users
+// @Description Get the list of all users, private account state is not included
 // @Tags Auth
 // @Accept json
 // @Param page query int false "page number" Format(page)
@@ -315,6 +1512,9 @@ func (h *authHandlers) GetUsers() echo.HandlerFunc {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
+		for _, user := range usersList.Users {
+			user.SanitizeAccountState()
+		}
 
 		return c.JSON(http.StatusOK, usersList)
 	}
@@ -344,6 +1544,113 @@ func (h *authHandlers) GetMe() echo.HandlerFunc {
 	}
 }
 
+// EnrollTOTP godoc
+// @Summary Enroll TOTP
+// @Description generate TOTP secret and otpauth uri, two factor is enabled after confirmation
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.TOTPEnrollment
+// @Failure 400 {object} httpErrors.RestError
+// @Router /auth/2fa/enroll [post]
+func (h *authHandlers) EnrollTOTP() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.EnrollTOTP")
+		defer span.Finish()
+
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
+		}
+
+		enrollment, err := h.authUC.EnrollTOTP(ctx, user)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, enrollment)
+	}
+}
+
+// ConfirmTOTP godoc
+// @Summary Confirm TOTP
+// @Description confirm TOTP enrolment with current code, enables two factor and returns recovery codes
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.RecoveryCodes
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/2fa/confirm [post]
+func (h *authHandlers) ConfirmTOTP() echo.HandlerFunc {
+	type Confirm struct {
+		Code string `json:"code" validate:"required,lte=16"`
+	}
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.ConfirmTOTP")
+		defer span.Finish()
+
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
+		}
+
+		confirm := &Confirm{}
+		if err = utils.ReadRequest(c, confirm); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		recoveryCodes, err := h.authUC.ConfirmTOTP(ctx, user.UserID, confirm.Code)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, recoveryCodes)
+	}
+}
+
+// DisableTOTP godoc
+// @Summary Disable TOTP
+// @Description disable two factor using current TOTP or recovery code
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {string} string	"ok"
+// @Failure 401 {object} httpErrors.RestError
+// @Router /auth/2fa/disable [post]
+func (h *authHandlers) DisableTOTP() echo.HandlerFunc {
+	type Disable struct {
+		Code string `json:"code" validate:"required,lte=16"`
+	}
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "authHandlers.DisableTOTP")
+		defer span.Finish()
+
+		user, err := utils.GetUserFromCtx(ctx)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(err))
+		}
+
+		disable := &Disable{}
+		if err = utils.ReadRequest(c, disable); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		if err = h.authUC.DisableTOTP(ctx, user.UserID, disable.Code); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
+
 // GetCSRFToken godoc
 // @Summary Get CSRF token
 // @Description Get CSRF token, required auth session cookie
@@ -437,3 +1744,57 @@ func (h *authHandlers) UploadAvatar() echo.HandlerFunc {
 		return c.JSON(http.StatusOK, updatedUser)
 	}
 }
+
+// New session with client info of the request
+func (h *authHandlers) newSession(c echo.Context, userID uuid.UUID) *models.Session {
+	return &models.Session{
+		UserID:    userID,
+		IPAddress: utils.GetIPAddress(c),
+		UserAgent: c.Request().UserAgent(),
+	}
+}
+
+func (h *authHandlers) newAuditEvent(c echo.Context, action string, actorID uuid.UUID, targetID string) *models.AuditEvent {
+	return &models.AuditEvent{
+		Action:     action,
+		ActorID:    actorID,
+		TargetType: models.AuditTargetUser,
+		TargetID:   targetID,
+		IPAddress:  utils.GetIPAddress(c),
+		UserAgent:  c.Request().UserAgent(),
+		RequestID:  utils.GetRequestID(c),
+	}
+}
+
+// Record audit event, failures are logged and do not fail the request
+func (h *authHandlers) recordAuditEvent(c echo.Context, event *models.AuditEvent) {
+	if err := h.auditUC.Record(utils.GetRequestCtx(c), event); err != nil {
+		h.logger.Errorf("authHandlers.recordAuditEvent RequestID: %s, Action: %s, Error: %s",
+			utils.GetRequestID(c),
+			event.Action,
+			err.Error(),
+		)
+	}
+}
+
+// Check session is still active and belongs to user
+func (h *authHandlers) isSessionOf(ctx context.Context, sessionID string, userID uuid.UUID) bool {
+	if sessionID == "" {
+		return false
+	}
+	sess, err := h.sessUC.GetSessionByID(ctx, sessionID)
+	return err == nil && sess.UserID == userID
+}
+
+// Get sessions owner, user_id path param is used on admin routes, otherwise current user
+func (h *authHandlers) getSessionsOwnerID(c echo.Context) (uuid.UUID, error) {
+	if c.Param("user_id") != "" {
+		return uuid.Parse(c.Param("user_id"))
+	}
+
+	user, err := utils.GetUserFromCtx(c.Request().Context())
+	if err != nil {
+		return uuid.Nil, httpErrors.NewUnauthorizedError(err)
+	}
+	return user.UserID, nil
+}
@@ -1,10 +1,13 @@
 package http
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
@@ -13,10 +16,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/AleksK1NG/api-mc/config"
+	auditMock "github.com/AleksK1NG/api-mc/internal/audit/mock"
 	"github.com/AleksK1NG/api-mc/internal/auth/mock"
 	"github.com/AleksK1NG/api-mc/internal/models"
 	mockSess "github.com/AleksK1NG/api-mc/internal/session/mock"
 	"github.com/AleksK1NG/api-mc/pkg/converter"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 	"github.com/AleksK1NG/api-mc/pkg/utils"
 )
@@ -40,7 +45,7 @@ func TestAuthHandlers_Register(t *testing.T) {
 	}
 
 	apiLogger := logger.NewApiLogger(cfg)
-	authHandlers := NewAuthHandlers(cfg, mockAuthUC, mockSessUC, apiLogger)
+	authHandlers := NewAuthHandlers(cfg, mockAuthUC, mockSessUC, nil, apiLogger)
 
 	gender := "male"
 	user := &models.User{
@@ -75,7 +80,8 @@ func TestAuthHandlers_Register(t *testing.T) {
 		},
 	}
 	sess := &models.Session{
-		UserID: userUID,
+		UserID:    userUID,
+		IPAddress: req.RemoteAddr,
 	}
 	session := "session"
 
@@ -102,11 +108,13 @@ func TestAuthHandlers_Login(t *testing.T) {
 		},
 		Logger: config.Logger{
 			Development: true,
+			Encoding:    "json",
 		},
 	}
 
 	apiLogger := logger.NewApiLogger(cfg)
-	authHandlers := NewAuthHandlers(cfg, mockAuthUC, mockSessUC, apiLogger)
+	apiLogger.InitLogger()
+	authHandlers := NewAuthHandlers(cfg, mockAuthUC, mockSessUC, nil, apiLogger)
 
 	type Login struct {
 		Email    string `json:"email" db:"email" validate:"omitempty,lte=60,email"`
@@ -147,16 +155,89 @@ func TestAuthHandlers_Login(t *testing.T) {
 		},
 	}
 	sess := &models.Session{
-		UserID: userUID,
+		UserID:    userUID,
+		IPAddress: req.RemoteAddr,
 	}
 	session := "session"
 
-	mockAuthUC.EXPECT().Login(ctxWithTrace, gomock.Eq(user)).Return(userWithToken, nil)
+	mockAuthUC.EXPECT().Login(ctxWithTrace, gomock.Eq(user), utils.GetClientIP(c)).Return(userWithToken, nil)
 	mockSessUC.EXPECT().CreateSession(ctxWithTrace, gomock.Eq(sess), 10).Return(session, nil)
 
 	err = handlerFunc(c)
 	require.NoError(t, err)
 	require.Nil(t, err)
+
+	t.Run("Forwarded header is ignored", func(t *testing.T) {
+		for _, forwardedFor := range []string{"203.0.113.1", "203.0.113.2"} {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(buf.String()))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+			req.Header.Set(echo.HeaderXRealIP, forwardedFor)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			mockAuthUC.EXPECT().Login(gomock.Any(), gomock.Eq(user), "192.0.2.1").Return(nil, httpErrors.NewLockoutError(time.Minute))
+
+			err := authHandlers.Login()(c)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusTooManyRequests, rec.Code)
+		}
+	})
+}
+
+func TestAuthHandlers_SendMagicLink(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthUC := mock.NewMockUseCase(ctrl)
+	mockSessUC := mockSess.NewMockUCSession(ctrl)
+
+	cfg := &config.Config{
+		Logger: config.Logger{
+			Development: true,
+			Encoding:    "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	apiLogger.InitLogger()
+	authHandlers := NewAuthHandlers(cfg, mockAuthUC, mockSessUC, nil, apiLogger)
+
+	e := echo.New()
+	body := `{"email":"email@mail.com"}`
+
+	t.Run("Send", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/magic-link", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockAuthUC.EXPECT().SendMagicLink(gomock.Any(), "email@mail.com", "192.0.2.1").Return(nil)
+
+		err := authHandlers.SendMagicLink()(c)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Forwarded header is ignored", func(t *testing.T) {
+		for _, forwardedFor := range []string{"203.0.113.1", "203.0.113.2"} {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/magic-link", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+			req.Header.Set(echo.HeaderXRealIP, forwardedFor)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			mockAuthUC.EXPECT().SendMagicLink(gomock.Any(), "email@mail.com", "192.0.2.1").Return(httpErrors.NewLockoutError(time.Minute))
+
+			err := authHandlers.SendMagicLink()(c)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusTooManyRequests, rec.Code)
+			require.NotEmpty(t, rec.Header().Get(retryAfterHeader))
+		}
+	})
 }
 
 func TestAuthHandlers_Logout(t *testing.T) {
@@ -167,6 +248,7 @@ func TestAuthHandlers_Logout(t *testing.T) {
 
 	mockAuthUC := mock.NewMockUseCase(ctrl)
 	mockSessUC := mockSess.NewMockUCSession(ctrl)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
 
 	cfg := &config.Config{
 		Session: config.Session{
@@ -178,7 +260,7 @@ func TestAuthHandlers_Logout(t *testing.T) {
 	}
 
 	apiLogger := logger.NewApiLogger(cfg)
-	authHandlers := NewAuthHandlers(cfg, mockAuthUC, mockSessUC, apiLogger)
+	authHandlers := NewAuthHandlers(cfg, mockAuthUC, mockSessUC, mockAuditUC, apiLogger)
 	sessionKey := "session-id"
 	cookieValue := "cookieValue"
 
@@ -202,9 +284,181 @@ func TestAuthHandlers_Logout(t *testing.T) {
 	require.NotEqual(t, cookie.Value, "")
 	require.Equal(t, cookie.Value, cookieValue)
 
+	sess := &models.Session{SessionID: cookieValue, UserID: uuid.New()}
+	mockSessUC.EXPECT().GetSessionByID(ctxWithTrace, gomock.Eq(cookie.Value)).Return(sess, nil)
 	mockSessUC.EXPECT().DeleteByID(ctxWithTrace, gomock.Eq(cookie.Value)).Return(nil)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event *models.AuditEvent) error {
+		require.Equal(t, models.AuditActionLogout, event.Action)
+		require.Equal(t, sess.UserID, event.ActorID)
+		return nil
+	})
 
 	err = logout(c)
 	require.NoError(t, err)
 	require.Nil(t, err)
 }
+
+func TestAuthHandlers_GetUserByID(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthUC := mock.NewMockUseCase(ctrl)
+	mockSessUC := mockSess.NewMockUCSession(ctrl)
+
+	cfg := &config.Config{
+		Logger: config.Logger{
+			Development: true,
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	authHandlers := NewAuthHandlers(cfg, mockAuthUC, mockSessUC, nil, apiLogger)
+
+	pendingEmail := "pending@gmail.com"
+	reason := "spam"
+	lockedUntil := time.Now().Add(time.Hour)
+	user := &models.User{
+		UserID:       uuid.New(),
+		FirstName:    "FirstName",
+		LastName:     "LastName",
+		PendingEmail: &pendingEmail,
+		LockedUntil:  &lockedUntil,
+		Status:       models.UserStatusSuspended,
+		StatusReason: &reason,
+		StatusUntil:  &lockedUntil,
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/"+user.UserID.String(), nil)
+	rec := httptest.NewRecorder()
+
+	c := e.NewContext(req, rec)
+	c.SetParamNames("user_id")
+	c.SetParamValues(user.UserID.String())
+
+	mockAuthUC.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil)
+
+	err := authHandlers.GetUserByID()(c)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "FirstName")
+	for _, field := range []string{"pending_email", "locked_until", "\"status\"", "status_reason", "status_until"} {
+		require.NotContains(t, rec.Body.String(), field)
+	}
+}
+
+func TestAuthHandlers_GetUsers(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthUC := mock.NewMockUseCase(ctrl)
+	mockSessUC := mockSess.NewMockUCSession(ctrl)
+
+	cfg := &config.Config{
+		Logger: config.Logger{
+			Development: true,
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	authHandlers := NewAuthHandlers(cfg, mockAuthUC, mockSessUC, nil, apiLogger)
+
+	pendingEmail := "pending@gmail.com"
+	reason := "spam"
+	lockedUntil := time.Now().Add(time.Hour)
+	usersList := &models.UsersList{
+		TotalCount: 2,
+		TotalPages: 1,
+		Page:       1,
+		Size:       10,
+		Users: []*models.User{
+			{
+				UserID:       uuid.New(),
+				FirstName:    "FirstName",
+				LastName:     "LastName",
+				PendingEmail: &pendingEmail,
+				LockedUntil:  &lockedUntil,
+				Status:       models.UserStatusSuspended,
+				StatusReason: &reason,
+				StatusUntil:  &lockedUntil,
+			},
+			{
+				UserID:    uuid.New(),
+				FirstName: "OtherName",
+				Status:    models.UserStatusBanned,
+			},
+		},
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/all?page=1&size=10", nil)
+	rec := httptest.NewRecorder()
+
+	c := e.NewContext(req, rec)
+
+	mockAuthUC.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(usersList, nil)
+
+	err := authHandlers.GetUsers()(c)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "FirstName")
+	require.Contains(t, rec.Body.String(), "OtherName")
+	for _, field := range []string{"pending_email", "locked_until", "\"status\"", "status_reason", "status_until"} {
+		require.NotContains(t, rec.Body.String(), field)
+	}
+}
+
+func TestAuthHandlers_GetSessions(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthUC := mock.NewMockUseCase(ctrl)
+	mockSessUC := mockSess.NewMockUCSession(ctrl)
+
+	cfg := &config.Config{
+		Session: config.Session{
+			Expire: 10,
+		},
+		Logger: config.Logger{
+			Development: true,
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	authHandlers := NewAuthHandlers(cfg, mockAuthUC, mockSessUC, nil, apiLogger)
+
+	user := &models.User{
+		UserID: uuid.New(),
+	}
+	sessions := []*models.Session{
+		{SessionID: "current", ID: "first", UserID: user.UserID},
+		{SessionID: "other", ID: "second", UserID: user.UserID},
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/sessions", nil)
+	req = req.WithContext(context.WithValue(req.Context(), utils.UserCtxKey{}, user))
+	rec := httptest.NewRecorder()
+
+	c := e.NewContext(req, rec)
+	c.Set("uid", "current")
+
+	mockSessUC.EXPECT().GetSessionsByUserID(gomock.Any(), user.UserID).Return(sessions, nil)
+
+	err := authHandlers.GetSessions()(c)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var sessionsInfo []*models.SessionInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &sessionsInfo))
+	require.Len(t, sessionsInfo, 2)
+	require.True(t, sessionsInfo[0].Current)
+	require.False(t, sessionsInfo[1].Current)
+	require.NotContains(t, rec.Body.String(), "session_id")
+}
@@ -5,21 +5,57 @@ import (
 
 	"github.com/AleksK1NG/api-mc/internal/auth"
 	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/models"
 )
 
 // Map auth routes
 func MapAuthRoutes(authGroup *echo.Group, h auth.Handlers, mw *middleware.MiddlewareManager) {
 	authGroup.POST("/register", h.Register())
 	authGroup.POST("/login", h.Login())
+	authGroup.POST("/login/2fa", h.LoginTwoFactor())
+	authGroup.POST("/magic-link", h.SendMagicLink())
+	authGroup.GET("/magic-link/callback", h.MagicLinkCallback())
+	authGroup.GET("/oidc/:provider", h.OIDCLogin())
+	authGroup.GET("/oidc/:provider/callback", h.OIDCCallback())
 	authGroup.POST("/logout", h.Logout())
+	authGroup.POST("/refresh", h.RefreshToken())
+	authGroup.POST("/password/forgot", h.ForgotPassword())
+	authGroup.POST("/password/reset", h.ResetPassword())
+	authGroup.GET("/verify", h.VerifyEmail())
+	authGroup.POST("/verify/resend", h.ResendVerification())
+	authGroup.GET("/email/confirm", h.ConfirmEmailChange())
+	authGroup.GET("/email/revert", h.RevertEmailChange())
 	authGroup.GET("/find", h.FindByName())
 	authGroup.GET("/all", h.GetUsers())
 	authGroup.GET("/:user_id", h.GetUserByID())
-	// authGroup.Use(middleware.AuthJWTMiddleware(authUC, cfg))
-	authGroup.Use(mw.AuthSessionMiddleware)
+	authGroup.Use(mw.AuthMiddleware)
 	authGroup.GET("/me", h.GetMe())
+	authGroup.GET("/suggestions", h.FindSuggestions())
 	authGroup.GET("/token", h.GetCSRFToken())
+	authGroup.POST("/email", h.ChangeEmail(), mw.CSRF, mw.NotImpersonatingMiddleware)
+	authGroup.POST("/2fa/enroll", h.EnrollTOTP(), mw.CSRF, mw.NotImpersonatingMiddleware)
+	authGroup.POST("/2fa/confirm", h.ConfirmTOTP(), mw.CSRF, mw.NotImpersonatingMiddleware)
+	authGroup.POST("/2fa/disable", h.DisableTOTP(), mw.CSRF, mw.NotImpersonatingMiddleware)
+	authGroup.DELETE("/impersonate", h.StopImpersonation(), mw.CSRF)
+	authGroup.GET("/keys", h.GetAPIKeys())
+	authGroup.POST("/keys", h.CreateAPIKey(), mw.CSRF, mw.NotImpersonatingMiddleware)
+	authGroup.GET("/keys/:key_id", h.GetAPIKeyByID())
+	authGroup.PUT("/keys/:key_id", h.UpdateAPIKey(), mw.CSRF, mw.NotImpersonatingMiddleware)
+	authGroup.DELETE("/keys/:key_id", h.DeleteAPIKey(), mw.CSRF, mw.NotImpersonatingMiddleware)
+	authGroup.GET("/sessions", h.GetSessions())
+	authGroup.DELETE("/sessions", h.DeleteSessions(), mw.CSRF, mw.NotImpersonatingMiddleware)
+	authGroup.DELETE("/sessions/:id", h.DeleteSession(), mw.CSRF, mw.NotImpersonatingMiddleware)
+	authGroup.GET("/roles", h.GetRoles(), mw.PermissionMiddleware(models.PermissionUsersRoles))
+	authGroup.GET("/:user_id/sessions", h.GetSessions(), mw.PermissionMiddleware(models.PermissionUsersSessions))
+	authGroup.DELETE("/:user_id/sessions", h.DeleteSessions(), mw.CSRF, mw.PermissionMiddleware(models.PermissionUsersSessions))
+	authGroup.DELETE("/:user_id/sessions/:id", h.DeleteSession(), mw.CSRF, mw.PermissionMiddleware(models.PermissionUsersSessions))
 	authGroup.POST("/:user_id/avatar", h.UploadAvatar(), mw.CSRF)
-	authGroup.PUT("/:user_id", h.Update(), mw.OwnerOrAdminMiddleware(), mw.CSRF)
-	authGroup.DELETE("/:user_id", h.Delete(), mw.CSRF, mw.RoleBasedAuthMiddleware([]string{"admin"}))
+	authGroup.PUT("/:user_id/password", h.ChangePassword(), mw.CSRF, mw.NotImpersonatingMiddleware)
+	authGroup.PUT("/:user_id", h.Update(), mw.OwnerOrPermissionMiddleware(models.PermissionUsersUpdate), mw.CSRF)
+	authGroup.DELETE("/:user_id", h.Delete(), mw.CSRF, mw.NotImpersonatingMiddleware, mw.PermissionMiddleware(models.PermissionUsersDelete))
+	authGroup.POST("/:user_id/restore", h.Restore(), mw.CSRF, mw.NotImpersonatingMiddleware, mw.PermissionMiddleware(models.PermissionUsersDelete))
+	authGroup.POST("/:user_id/unlock", h.Unlock(), mw.CSRF, mw.PermissionMiddleware(models.PermissionUsersUnlock))
+	authGroup.PUT("/:user_id/role", h.UpdateRole(), mw.CSRF, mw.PermissionMiddleware(models.PermissionUsersRoles))
+	authGroup.PUT("/:user_id/status", h.UpdateStatus(), mw.CSRF, mw.PermissionMiddleware(models.PermissionUsersStatus))
+	authGroup.POST("/:user_id/impersonate", h.Impersonate(), mw.CSRF, mw.NotImpersonatingMiddleware, mw.PermissionMiddleware(models.PermissionUsersImpersonate))
 }
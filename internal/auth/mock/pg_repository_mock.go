@@ -11,6 +11,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
 	reflect "reflect"
+	time "time"
 )
 
 // MockRepository is a mock of Repository interface
@@ -80,6 +81,50 @@ func (mr *MockRepositoryMockRecorder) Delete(ctx, userID interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), ctx, userID)
 }
 
+// Restore mocks base method
+func (m *MockRepository) Restore(ctx context.Context, userID uuid.UUID, deletedAfter time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, userID, deletedAfter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore
+func (mr *MockRepositoryMockRecorder) Restore(ctx, userID, deletedAfter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockRepository)(nil).Restore), ctx, userID, deletedAfter)
+}
+
+// AnonymizeDeletedUsers mocks base method
+func (m *MockRepository) AnonymizeDeletedUsers(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeDeletedUsers", ctx, deletedBefore, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeDeletedUsers indicates an expected call of AnonymizeDeletedUsers
+func (mr *MockRepositoryMockRecorder) AnonymizeDeletedUsers(ctx, deletedBefore, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeDeletedUsers", reflect.TypeOf((*MockRepository)(nil).AnonymizeDeletedUsers), ctx, deletedBefore, limit)
+}
+
+// HardDeleteDeletedUsers mocks base method
+func (m *MockRepository) HardDeleteDeletedUsers(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HardDeleteDeletedUsers", ctx, deletedBefore, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HardDeleteDeletedUsers indicates an expected call of HardDeleteDeletedUsers
+func (mr *MockRepositoryMockRecorder) HardDeleteDeletedUsers(ctx, deletedBefore, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HardDeleteDeletedUsers", reflect.TypeOf((*MockRepository)(nil).HardDeleteDeletedUsers), ctx, deletedBefore, limit)
+}
+
 // GetByID mocks base method
 func (m *MockRepository) GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -96,18 +141,33 @@ func (mr *MockRepositoryMockRecorder) GetByID(ctx, userID interface{}) *gomock.C
 }
 
 // FindByName mocks base method
-func (m *MockRepository) FindByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.UsersList, error) {
+func (m *MockRepository) FindByName(ctx context.Context, name string, similarity float64, query *utils.PaginationQuery) (*models.UsersList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindByName", ctx, name, query)
+	ret := m.ctrl.Call(m, "FindByName", ctx, name, similarity, query)
 	ret0, _ := ret[0].(*models.UsersList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindByName indicates an expected call of FindByName
-func (mr *MockRepositoryMockRecorder) FindByName(ctx, name, query interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) FindByName(ctx, name, similarity, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByName", reflect.TypeOf((*MockRepository)(nil).FindByName), ctx, name, similarity, query)
+}
+
+// FindSuggestions mocks base method
+func (m *MockRepository) FindSuggestions(ctx context.Context, name string, similarity float64, limit int) ([]*models.UserSuggestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSuggestions", ctx, name, similarity, limit)
+	ret0, _ := ret[0].([]*models.UserSuggestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSuggestions indicates an expected call of FindSuggestions
+func (mr *MockRepositoryMockRecorder) FindSuggestions(ctx, name, similarity, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByName", reflect.TypeOf((*MockRepository)(nil).FindByName), ctx, name, query)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSuggestions", reflect.TypeOf((*MockRepository)(nil).FindSuggestions), ctx, name, similarity, limit)
 }
 
 // FindByEmail mocks base method
@@ -139,3 +199,363 @@ func (mr *MockRepositoryMockRecorder) GetUsers(ctx, pq interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockRepository)(nil).GetUsers), ctx, pq)
 }
+
+// UpdatePassword mocks base method
+func (m *MockRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, userID, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword
+func (mr *MockRepositoryMockRecorder) UpdatePassword(ctx, userID, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockRepository)(nil).UpdatePassword), ctx, userID, password)
+}
+
+// VerifyEmail mocks base method
+func (m *MockRepository) VerifyEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, userID, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail
+func (mr *MockRepositoryMockRecorder) VerifyEmail(ctx, userID, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockRepository)(nil).VerifyEmail), ctx, userID, email)
+}
+
+// SetPendingEmail mocks base method
+func (m *MockRepository) SetPendingEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPendingEmail", ctx, userID, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPendingEmail indicates an expected call of SetPendingEmail
+func (mr *MockRepositoryMockRecorder) SetPendingEmail(ctx, userID, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPendingEmail", reflect.TypeOf((*MockRepository)(nil).SetPendingEmail), ctx, userID, email)
+}
+
+// ConfirmPendingEmail mocks base method
+func (m *MockRepository) ConfirmPendingEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmPendingEmail", ctx, userID, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmPendingEmail indicates an expected call of ConfirmPendingEmail
+func (mr *MockRepositoryMockRecorder) ConfirmPendingEmail(ctx, userID, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmPendingEmail", reflect.TypeOf((*MockRepository)(nil).ConfirmPendingEmail), ctx, userID, email)
+}
+
+// RevertEmail mocks base method
+func (m *MockRepository) RevertEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevertEmail", ctx, userID, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevertEmail indicates an expected call of RevertEmail
+func (mr *MockRepositoryMockRecorder) RevertEmail(ctx, userID, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertEmail", reflect.TypeOf((*MockRepository)(nil).RevertEmail), ctx, userID, email)
+}
+
+// UpdateLockedUntil mocks base method
+func (m *MockRepository) UpdateLockedUntil(ctx context.Context, userID uuid.UUID, lockedUntil *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLockedUntil", ctx, userID, lockedUntil)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLockedUntil indicates an expected call of UpdateLockedUntil
+func (mr *MockRepositoryMockRecorder) UpdateLockedUntil(ctx, userID, lockedUntil interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLockedUntil", reflect.TypeOf((*MockRepository)(nil).UpdateLockedUntil), ctx, userID, lockedUntil)
+}
+
+// UpdateStatus mocks base method
+func (m *MockRepository) UpdateStatus(ctx context.Context, userID uuid.UUID, status *models.UserStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, userID, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus
+func (mr *MockRepositoryMockRecorder) UpdateStatus(ctx, userID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockRepository)(nil).UpdateStatus), ctx, userID, status)
+}
+
+// CreateTOTP mocks base method
+func (m *MockRepository) CreateTOTP(ctx context.Context, userID uuid.UUID, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTOTP", ctx, userID, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTOTP indicates an expected call of CreateTOTP
+func (mr *MockRepositoryMockRecorder) CreateTOTP(ctx, userID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTOTP", reflect.TypeOf((*MockRepository)(nil).CreateTOTP), ctx, userID, secret)
+}
+
+// GetTOTP mocks base method
+func (m *MockRepository) GetTOTP(ctx context.Context, userID uuid.UUID) (*models.UserTOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTP", ctx, userID)
+	ret0, _ := ret[0].(*models.UserTOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTP indicates an expected call of GetTOTP
+func (mr *MockRepositoryMockRecorder) GetTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTP", reflect.TypeOf((*MockRepository)(nil).GetTOTP), ctx, userID)
+}
+
+// UpdateTOTPStep mocks base method
+func (m *MockRepository) UpdateTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTOTPStep", ctx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTOTPStep indicates an expected call of UpdateTOTPStep
+func (mr *MockRepositoryMockRecorder) UpdateTOTPStep(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTOTPStep", reflect.TypeOf((*MockRepository)(nil).UpdateTOTPStep), ctx, userID, step)
+}
+
+// EnableTwoFactor mocks base method
+func (m *MockRepository) EnableTwoFactor(ctx context.Context, userID uuid.UUID, recoveryCodeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTwoFactor", ctx, userID, recoveryCodeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTwoFactor indicates an expected call of EnableTwoFactor
+func (mr *MockRepositoryMockRecorder) EnableTwoFactor(ctx, userID, recoveryCodeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTwoFactor", reflect.TypeOf((*MockRepository)(nil).EnableTwoFactor), ctx, userID, recoveryCodeHashes)
+}
+
+// DisableTwoFactor mocks base method
+func (m *MockRepository) DisableTwoFactor(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTwoFactor", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTwoFactor indicates an expected call of DisableTwoFactor
+func (mr *MockRepositoryMockRecorder) DisableTwoFactor(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTwoFactor", reflect.TypeOf((*MockRepository)(nil).DisableTwoFactor), ctx, userID)
+}
+
+// UseRecoveryCode mocks base method
+func (m *MockRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode
+func (mr *MockRepositoryMockRecorder) UseRecoveryCode(ctx, userID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockRepository)(nil).UseRecoveryCode), ctx, userID, codeHash)
+}
+
+// CreateAPIKey mocks base method
+func (m *MockRepository) CreateAPIKey(ctx context.Context, apiKey *models.APIKey) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, apiKey)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey
+func (mr *MockRepositoryMockRecorder) CreateAPIKey(ctx, apiKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockRepository)(nil).CreateAPIKey), ctx, apiKey)
+}
+
+// GetAPIKeysByUserID mocks base method
+func (m *MockRepository) GetAPIKeysByUserID(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeysByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeysByUserID indicates an expected call of GetAPIKeysByUserID
+func (mr *MockRepositoryMockRecorder) GetAPIKeysByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeysByUserID", reflect.TypeOf((*MockRepository)(nil).GetAPIKeysByUserID), ctx, userID)
+}
+
+// GetAPIKeyByID mocks base method
+func (m *MockRepository) GetAPIKeyByID(ctx context.Context, userID, apiKeyID uuid.UUID) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByID", ctx, userID, apiKeyID)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByID indicates an expected call of GetAPIKeyByID
+func (mr *MockRepositoryMockRecorder) GetAPIKeyByID(ctx, userID, apiKeyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByID", reflect.TypeOf((*MockRepository)(nil).GetAPIKeyByID), ctx, userID, apiKeyID)
+}
+
+// GetAPIKeyByHash mocks base method
+func (m *MockRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", ctx, keyHash)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash
+func (mr *MockRepositoryMockRecorder) GetAPIKeyByHash(ctx, keyHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockRepository)(nil).GetAPIKeyByHash), ctx, keyHash)
+}
+
+// UpdateAPIKey mocks base method
+func (m *MockRepository) UpdateAPIKey(ctx context.Context, apiKey *models.APIKey) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAPIKey", ctx, apiKey)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAPIKey indicates an expected call of UpdateAPIKey
+func (mr *MockRepositoryMockRecorder) UpdateAPIKey(ctx, apiKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPIKey", reflect.TypeOf((*MockRepository)(nil).UpdateAPIKey), ctx, apiKey)
+}
+
+// DeleteAPIKey mocks base method
+func (m *MockRepository) DeleteAPIKey(ctx context.Context, userID, apiKeyID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIKey", ctx, userID, apiKeyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAPIKey indicates an expected call of DeleteAPIKey
+func (mr *MockRepositoryMockRecorder) DeleteAPIKey(ctx, userID, apiKeyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockRepository)(nil).DeleteAPIKey), ctx, userID, apiKeyID)
+}
+
+// UpdateAPIKeyLastUsed mocks base method
+func (m *MockRepository) UpdateAPIKeyLastUsed(ctx context.Context, apiKeyID uuid.UUID, lastUsedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAPIKeyLastUsed", ctx, apiKeyID, lastUsedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAPIKeyLastUsed indicates an expected call of UpdateAPIKeyLastUsed
+func (mr *MockRepositoryMockRecorder) UpdateAPIKeyLastUsed(ctx, apiKeyID, lastUsedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPIKeyLastUsed", reflect.TypeOf((*MockRepository)(nil).UpdateAPIKeyLastUsed), ctx, apiKeyID, lastUsedAt)
+}
+
+// GetRoles mocks base method
+func (m *MockRepository) GetRoles(ctx context.Context) ([]*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoles", ctx)
+	ret0, _ := ret[0].([]*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoles indicates an expected call of GetRoles
+func (mr *MockRepositoryMockRecorder) GetRoles(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoles", reflect.TypeOf((*MockRepository)(nil).GetRoles), ctx)
+}
+
+// GetPermissionsByRole mocks base method
+func (m *MockRepository) GetPermissionsByRole(ctx context.Context, role string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPermissionsByRole", ctx, role)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPermissionsByRole indicates an expected call of GetPermissionsByRole
+func (mr *MockRepositoryMockRecorder) GetPermissionsByRole(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPermissionsByRole", reflect.TypeOf((*MockRepository)(nil).GetPermissionsByRole), ctx, role)
+}
+
+// UpdateRole mocks base method
+func (m *MockRepository) UpdateRole(ctx context.Context, userID uuid.UUID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", ctx, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRole indicates an expected call of UpdateRole
+func (mr *MockRepositoryMockRecorder) UpdateRole(ctx, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockRepository)(nil).UpdateRole), ctx, userID, role)
+}
+
+// GetUserIdentity mocks base method
+func (m *MockRepository) GetUserIdentity(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIdentity", ctx, provider, subject)
+	ret0, _ := ret[0].(*models.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIdentity indicates an expected call of GetUserIdentity
+func (mr *MockRepositoryMockRecorder) GetUserIdentity(ctx, provider, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIdentity", reflect.TypeOf((*MockRepository)(nil).GetUserIdentity), ctx, provider, subject)
+}
+
+// CreateUserIdentity mocks base method
+func (m *MockRepository) CreateUserIdentity(ctx context.Context, identity *models.UserIdentity) (*models.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserIdentity", ctx, identity)
+	ret0, _ := ret[0].(*models.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserIdentity indicates an expected call of CreateUserIdentity
+func (mr *MockRepositoryMockRecorder) CreateUserIdentity(ctx, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserIdentity", reflect.TypeOf((*MockRepository)(nil).CreateUserIdentity), ctx, identity)
+}
@@ -9,6 +9,7 @@ import (
 	models "github.com/AleksK1NG/api-mc/internal/models"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockRedisRepository is a mock of RedisRepository interface
@@ -76,3 +77,279 @@ func (mr *MockRedisRepositoryMockRecorder) DeleteUserCtx(ctx, key interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserCtx", reflect.TypeOf((*MockRedisRepository)(nil).DeleteUserCtx), ctx, key)
 }
+
+// GetRefreshTokenCtx mocks base method
+func (m *MockRedisRepository) GetRefreshTokenCtx(ctx context.Context, key string) (*models.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshTokenCtx", ctx, key)
+	ret0, _ := ret[0].(*models.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshTokenCtx indicates an expected call of GetRefreshTokenCtx
+func (mr *MockRedisRepositoryMockRecorder) GetRefreshTokenCtx(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenCtx", reflect.TypeOf((*MockRedisRepository)(nil).GetRefreshTokenCtx), ctx, key)
+}
+
+// SetRefreshTokenCtx mocks base method
+func (m *MockRedisRepository) SetRefreshTokenCtx(ctx context.Context, key string, seconds int, token *models.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRefreshTokenCtx", ctx, key, seconds, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRefreshTokenCtx indicates an expected call of SetRefreshTokenCtx
+func (mr *MockRedisRepositoryMockRecorder) SetRefreshTokenCtx(ctx, key, seconds, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRefreshTokenCtx", reflect.TypeOf((*MockRedisRepository)(nil).SetRefreshTokenCtx), ctx, key, seconds, token)
+}
+
+// MarkRefreshTokenUsedCtx mocks base method
+func (m *MockRedisRepository) MarkRefreshTokenUsedCtx(ctx context.Context, key string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRefreshTokenUsedCtx", ctx, key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkRefreshTokenUsedCtx indicates an expected call of MarkRefreshTokenUsedCtx
+func (mr *MockRedisRepositoryMockRecorder) MarkRefreshTokenUsedCtx(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRefreshTokenUsedCtx", reflect.TypeOf((*MockRedisRepository)(nil).MarkRefreshTokenUsedCtx), ctx, key)
+}
+
+// SetTokenFamilyCtx mocks base method
+func (m *MockRedisRepository) SetTokenFamilyCtx(ctx context.Context, key string, seconds int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTokenFamilyCtx", ctx, key, seconds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTokenFamilyCtx indicates an expected call of SetTokenFamilyCtx
+func (mr *MockRedisRepositoryMockRecorder) SetTokenFamilyCtx(ctx, key, seconds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTokenFamilyCtx", reflect.TypeOf((*MockRedisRepository)(nil).SetTokenFamilyCtx), ctx, key, seconds)
+}
+
+// ExistsTokenFamilyCtx mocks base method
+func (m *MockRedisRepository) ExistsTokenFamilyCtx(ctx context.Context, key string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExistsTokenFamilyCtx", ctx, key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExistsTokenFamilyCtx indicates an expected call of ExistsTokenFamilyCtx
+func (mr *MockRedisRepositoryMockRecorder) ExistsTokenFamilyCtx(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExistsTokenFamilyCtx", reflect.TypeOf((*MockRedisRepository)(nil).ExistsTokenFamilyCtx), ctx, key)
+}
+
+// DeleteTokenFamilyCtx mocks base method
+func (m *MockRedisRepository) DeleteTokenFamilyCtx(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTokenFamilyCtx", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTokenFamilyCtx indicates an expected call of DeleteTokenFamilyCtx
+func (mr *MockRedisRepositoryMockRecorder) DeleteTokenFamilyCtx(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTokenFamilyCtx", reflect.TypeOf((*MockRedisRepository)(nil).DeleteTokenFamilyCtx), ctx, key)
+}
+
+// AddUserTokenFamilyCtx mocks base method
+func (m *MockRedisRepository) AddUserTokenFamilyCtx(ctx context.Context, key, familyID string, seconds int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUserTokenFamilyCtx", ctx, key, familyID, seconds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddUserTokenFamilyCtx indicates an expected call of AddUserTokenFamilyCtx
+func (mr *MockRedisRepositoryMockRecorder) AddUserTokenFamilyCtx(ctx, key, familyID, seconds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUserTokenFamilyCtx", reflect.TypeOf((*MockRedisRepository)(nil).AddUserTokenFamilyCtx), ctx, key, familyID, seconds)
+}
+
+// GetUserTokenFamiliesCtx mocks base method
+func (m *MockRedisRepository) GetUserTokenFamiliesCtx(ctx context.Context, key string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTokenFamiliesCtx", ctx, key)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTokenFamiliesCtx indicates an expected call of GetUserTokenFamiliesCtx
+func (mr *MockRedisRepositoryMockRecorder) GetUserTokenFamiliesCtx(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTokenFamiliesCtx", reflect.TypeOf((*MockRedisRepository)(nil).GetUserTokenFamiliesCtx), ctx, key)
+}
+
+// RemoveUserTokenFamiliesCtx mocks base method
+func (m *MockRedisRepository) RemoveUserTokenFamiliesCtx(ctx context.Context, key string, familyIDs []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveUserTokenFamiliesCtx", ctx, key, familyIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveUserTokenFamiliesCtx indicates an expected call of RemoveUserTokenFamiliesCtx
+func (mr *MockRedisRepositoryMockRecorder) RemoveUserTokenFamiliesCtx(ctx, key, familyIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveUserTokenFamiliesCtx", reflect.TypeOf((*MockRedisRepository)(nil).RemoveUserTokenFamiliesCtx), ctx, key, familyIDs)
+}
+
+// SetUserTokenCtx mocks base method
+func (m *MockRedisRepository) SetUserTokenCtx(ctx context.Context, key string, seconds int, token *models.UserToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserTokenCtx", ctx, key, seconds, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserTokenCtx indicates an expected call of SetUserTokenCtx
+func (mr *MockRedisRepositoryMockRecorder) SetUserTokenCtx(ctx, key, seconds, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserTokenCtx", reflect.TypeOf((*MockRedisRepository)(nil).SetUserTokenCtx), ctx, key, seconds, token)
+}
+
+// ConsumeUserTokenCtx mocks base method
+func (m *MockRedisRepository) ConsumeUserTokenCtx(ctx context.Context, key string) (*models.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeUserTokenCtx", ctx, key)
+	ret0, _ := ret[0].(*models.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeUserTokenCtx indicates an expected call of ConsumeUserTokenCtx
+func (mr *MockRedisRepositoryMockRecorder) ConsumeUserTokenCtx(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeUserTokenCtx", reflect.TypeOf((*MockRedisRepository)(nil).ConsumeUserTokenCtx), ctx, key)
+}
+
+// IncrLoginAttemptsCtx mocks base method
+func (m *MockRedisRepository) IncrLoginAttemptsCtx(ctx context.Context, key string, seconds int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrLoginAttemptsCtx", ctx, key, seconds)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrLoginAttemptsCtx indicates an expected call of IncrLoginAttemptsCtx
+func (mr *MockRedisRepositoryMockRecorder) IncrLoginAttemptsCtx(ctx, key, seconds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrLoginAttemptsCtx", reflect.TypeOf((*MockRedisRepository)(nil).IncrLoginAttemptsCtx), ctx, key, seconds)
+}
+
+// GetLoginAttemptsCtx mocks base method
+func (m *MockRedisRepository) GetLoginAttemptsCtx(ctx context.Context, key string) (int64, time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttemptsCtx", ctx, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(time.Duration)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetLoginAttemptsCtx indicates an expected call of GetLoginAttemptsCtx
+func (mr *MockRedisRepositoryMockRecorder) GetLoginAttemptsCtx(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttemptsCtx", reflect.TypeOf((*MockRedisRepository)(nil).GetLoginAttemptsCtx), ctx, key)
+}
+
+// DeleteLoginAttemptsCtx mocks base method
+func (m *MockRedisRepository) DeleteLoginAttemptsCtx(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginAttemptsCtx", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLoginAttemptsCtx indicates an expected call of DeleteLoginAttemptsCtx
+func (mr *MockRedisRepositoryMockRecorder) DeleteLoginAttemptsCtx(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginAttemptsCtx", reflect.TypeOf((*MockRedisRepository)(nil).DeleteLoginAttemptsCtx), ctx, key)
+}
+
+// GetPermissionsCtx mocks base method
+func (m *MockRedisRepository) GetPermissionsCtx(ctx context.Context, key string) (*models.UserPermissions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPermissionsCtx", ctx, key)
+	ret0, _ := ret[0].(*models.UserPermissions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPermissionsCtx indicates an expected call of GetPermissionsCtx
+func (mr *MockRedisRepositoryMockRecorder) GetPermissionsCtx(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPermissionsCtx", reflect.TypeOf((*MockRedisRepository)(nil).GetPermissionsCtx), ctx, key)
+}
+
+// SetPermissionsCtx mocks base method
+func (m *MockRedisRepository) SetPermissionsCtx(ctx context.Context, key string, seconds int, permissions *models.UserPermissions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPermissionsCtx", ctx, key, seconds, permissions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPermissionsCtx indicates an expected call of SetPermissionsCtx
+func (mr *MockRedisRepositoryMockRecorder) SetPermissionsCtx(ctx, key, seconds, permissions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPermissionsCtx", reflect.TypeOf((*MockRedisRepository)(nil).SetPermissionsCtx), ctx, key, seconds, permissions)
+}
+
+// DeletePermissionsCtx mocks base method
+func (m *MockRedisRepository) DeletePermissionsCtx(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePermissionsCtx", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePermissionsCtx indicates an expected call of DeletePermissionsCtx
+func (mr *MockRedisRepositoryMockRecorder) DeletePermissionsCtx(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePermissionsCtx", reflect.TypeOf((*MockRedisRepository)(nil).DeletePermissionsCtx), ctx, key)
+}
+
+// SetOIDCAuthRequestCtx mocks base method
+func (m *MockRedisRepository) SetOIDCAuthRequestCtx(ctx context.Context, key string, seconds int, authRequest *models.OIDCAuthRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOIDCAuthRequestCtx", ctx, key, seconds, authRequest)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetOIDCAuthRequestCtx indicates an expected call of SetOIDCAuthRequestCtx
+func (mr *MockRedisRepositoryMockRecorder) SetOIDCAuthRequestCtx(ctx, key, seconds, authRequest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOIDCAuthRequestCtx", reflect.TypeOf((*MockRedisRepository)(nil).SetOIDCAuthRequestCtx), ctx, key, seconds, authRequest)
+}
+
+// ConsumeOIDCAuthRequestCtx mocks base method
+func (m *MockRedisRepository) ConsumeOIDCAuthRequestCtx(ctx context.Context, key string) (*models.OIDCAuthRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeOIDCAuthRequestCtx", ctx, key)
+	ret0, _ := ret[0].(*models.OIDCAuthRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeOIDCAuthRequestCtx indicates an expected call of ConsumeOIDCAuthRequestCtx
+func (mr *MockRedisRepositoryMockRecorder) ConsumeOIDCAuthRequestCtx(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeOIDCAuthRequestCtx", reflect.TypeOf((*MockRedisRepository)(nil).ConsumeOIDCAuthRequestCtx), ctx, key)
+}
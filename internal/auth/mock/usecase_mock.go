@@ -7,6 +7,7 @@ package mock
 import (
 	context "context"
 	models "github.com/AleksK1NG/api-mc/internal/models"
+	jwtkeys "github.com/AleksK1NG/api-mc/pkg/jwtkeys"
 	utils "github.com/AleksK1NG/api-mc/pkg/utils"
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
@@ -52,18 +53,18 @@ func (mr *MockUseCaseMockRecorder) Register(ctx, user interface{}) *gomock.Call
 }
 
 // Login mocks base method
-func (m *MockUseCase) Login(ctx context.Context, user *models.User) (*models.UserWithToken, error) {
+func (m *MockUseCase) Login(ctx context.Context, user *models.User, ipAddress string) (*models.UserWithToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", ctx, user)
+	ret := m.ctrl.Call(m, "Login", ctx, user, ipAddress)
 	ret0, _ := ret[0].(*models.UserWithToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Login indicates an expected call of Login
-func (mr *MockUseCaseMockRecorder) Login(ctx, user interface{}) *gomock.Call {
+func (mr *MockUseCaseMockRecorder) Login(ctx, user, ipAddress interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUseCase)(nil).Login), ctx, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUseCase)(nil).Login), ctx, user, ipAddress)
 }
 
 // Update mocks base method
@@ -95,6 +96,36 @@ func (mr *MockUseCaseMockRecorder) Delete(ctx, userID interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUseCase)(nil).Delete), ctx, userID)
 }
 
+// Restore mocks base method
+func (m *MockUseCase) Restore(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore
+func (mr *MockUseCaseMockRecorder) Restore(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockUseCase)(nil).Restore), ctx, userID)
+}
+
+// PurgeDeletedUsers mocks base method
+func (m *MockUseCase) PurgeDeletedUsers(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedUsers", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedUsers indicates an expected call of PurgeDeletedUsers
+func (mr *MockUseCaseMockRecorder) PurgeDeletedUsers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedUsers", reflect.TypeOf((*MockUseCase)(nil).PurgeDeletedUsers), ctx)
+}
+
 // GetByID mocks base method
 func (m *MockUseCase) GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -125,6 +156,21 @@ func (mr *MockUseCaseMockRecorder) FindByName(ctx, name, query interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByName", reflect.TypeOf((*MockUseCase)(nil).FindByName), ctx, name, query)
 }
 
+// FindSuggestions mocks base method
+func (m *MockUseCase) FindSuggestions(ctx context.Context, name string, limit int) ([]*models.UserSuggestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSuggestions", ctx, name, limit)
+	ret0, _ := ret[0].([]*models.UserSuggestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSuggestions indicates an expected call of FindSuggestions
+func (mr *MockUseCaseMockRecorder) FindSuggestions(ctx, name, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSuggestions", reflect.TypeOf((*MockUseCase)(nil).FindSuggestions), ctx, name, limit)
+}
+
 // GetUsers mocks base method
 func (m *MockUseCase) GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error) {
 	m.ctrl.T.Helper()
@@ -154,3 +200,440 @@ func (mr *MockUseCaseMockRecorder) UploadAvatar(ctx, userID, file interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAvatar", reflect.TypeOf((*MockUseCase)(nil).UploadAvatar), ctx, userID, file)
 }
+
+// RefreshTokens mocks base method
+func (m *MockUseCase) RefreshTokens(ctx context.Context, refreshToken string) (*models.UserWithToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshTokens", ctx, refreshToken)
+	ret0, _ := ret[0].(*models.UserWithToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshTokens indicates an expected call of RefreshTokens
+func (mr *MockUseCaseMockRecorder) RefreshTokens(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshTokens", reflect.TypeOf((*MockUseCase)(nil).RefreshTokens), ctx, refreshToken)
+}
+
+// ForgotPassword mocks base method
+func (m *MockUseCase) ForgotPassword(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgotPassword", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForgotPassword indicates an expected call of ForgotPassword
+func (mr *MockUseCaseMockRecorder) ForgotPassword(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockUseCase)(nil).ForgotPassword), ctx, email)
+}
+
+// ResetPassword mocks base method
+func (m *MockUseCase) ResetPassword(ctx context.Context, resetToken, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, resetToken, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword
+func (mr *MockUseCaseMockRecorder) ResetPassword(ctx, resetToken, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockUseCase)(nil).ResetPassword), ctx, resetToken, password)
+}
+
+// ChangePassword mocks base method
+func (m *MockUseCase) ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword, currentSessionID, currentRefreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, userID, currentPassword, newPassword, currentSessionID, currentRefreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword
+func (mr *MockUseCaseMockRecorder) ChangePassword(ctx, userID, currentPassword, newPassword, currentSessionID, currentRefreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUseCase)(nil).ChangePassword), ctx, userID, currentPassword, newPassword, currentSessionID, currentRefreshToken)
+}
+
+// VerifyEmail mocks base method
+func (m *MockUseCase) VerifyEmail(ctx context.Context, verifyToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, verifyToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail
+func (mr *MockUseCaseMockRecorder) VerifyEmail(ctx, verifyToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUseCase)(nil).VerifyEmail), ctx, verifyToken)
+}
+
+// ResendVerification mocks base method
+func (m *MockUseCase) ResendVerification(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResendVerification", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResendVerification indicates an expected call of ResendVerification
+func (mr *MockUseCaseMockRecorder) ResendVerification(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendVerification", reflect.TypeOf((*MockUseCase)(nil).ResendVerification), ctx, email)
+}
+
+// ChangeEmail mocks base method
+func (m *MockUseCase) ChangeEmail(ctx context.Context, userID uuid.UUID, email, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeEmail", ctx, userID, email, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeEmail indicates an expected call of ChangeEmail
+func (mr *MockUseCaseMockRecorder) ChangeEmail(ctx, userID, email, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeEmail", reflect.TypeOf((*MockUseCase)(nil).ChangeEmail), ctx, userID, email, password)
+}
+
+// ConfirmEmailChange mocks base method
+func (m *MockUseCase) ConfirmEmailChange(ctx context.Context, changeToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmailChange", ctx, changeToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmEmailChange indicates an expected call of ConfirmEmailChange
+func (mr *MockUseCaseMockRecorder) ConfirmEmailChange(ctx, changeToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChange", reflect.TypeOf((*MockUseCase)(nil).ConfirmEmailChange), ctx, changeToken)
+}
+
+// RevertEmailChange mocks base method
+func (m *MockUseCase) RevertEmailChange(ctx context.Context, revertToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevertEmailChange", ctx, revertToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevertEmailChange indicates an expected call of RevertEmailChange
+func (mr *MockUseCaseMockRecorder) RevertEmailChange(ctx, revertToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertEmailChange", reflect.TypeOf((*MockUseCase)(nil).RevertEmailChange), ctx, revertToken)
+}
+
+// LoginTwoFactor mocks base method
+func (m *MockUseCase) LoginTwoFactor(ctx context.Context, challenge, code string) (*models.UserWithToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginTwoFactor", ctx, challenge, code)
+	ret0, _ := ret[0].(*models.UserWithToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginTwoFactor indicates an expected call of LoginTwoFactor
+func (mr *MockUseCaseMockRecorder) LoginTwoFactor(ctx, challenge, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginTwoFactor", reflect.TypeOf((*MockUseCase)(nil).LoginTwoFactor), ctx, challenge, code)
+}
+
+// EnrollTOTP mocks base method
+func (m *MockUseCase) EnrollTOTP(ctx context.Context, user *models.User) (*models.TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTOTP", ctx, user)
+	ret0, _ := ret[0].(*models.TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP
+func (mr *MockUseCaseMockRecorder) EnrollTOTP(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockUseCase)(nil).EnrollTOTP), ctx, user)
+}
+
+// ConfirmTOTP mocks base method
+func (m *MockUseCase) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) (*models.RecoveryCodes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, userID, code)
+	ret0, _ := ret[0].(*models.RecoveryCodes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP
+func (mr *MockUseCaseMockRecorder) ConfirmTOTP(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockUseCase)(nil).ConfirmTOTP), ctx, userID, code)
+}
+
+// DisableTOTP mocks base method
+func (m *MockUseCase) DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP
+func (mr *MockUseCaseMockRecorder) DisableTOTP(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockUseCase)(nil).DisableTOTP), ctx, userID, code)
+}
+
+// Unlock mocks base method
+func (m *MockUseCase) Unlock(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unlock", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unlock indicates an expected call of Unlock
+func (mr *MockUseCaseMockRecorder) Unlock(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlock", reflect.TypeOf((*MockUseCase)(nil).Unlock), ctx, userID)
+}
+
+// UpdateStatus mocks base method
+func (m *MockUseCase) UpdateStatus(ctx context.Context, userID uuid.UUID, status *models.UserStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, userID, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus
+func (mr *MockUseCaseMockRecorder) UpdateStatus(ctx, userID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockUseCase)(nil).UpdateStatus), ctx, userID, status)
+}
+
+// Impersonate mocks base method
+func (m *MockUseCase) Impersonate(ctx context.Context, adminID, userID uuid.UUID) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Impersonate", ctx, adminID, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Impersonate indicates an expected call of Impersonate
+func (mr *MockUseCaseMockRecorder) Impersonate(ctx, adminID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Impersonate", reflect.TypeOf((*MockUseCase)(nil).Impersonate), ctx, adminID, userID)
+}
+
+// CreateAPIKey mocks base method
+func (m *MockUseCase) CreateAPIKey(ctx context.Context, apiKey *models.APIKey) (*models.APIKeyWithSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, apiKey)
+	ret0, _ := ret[0].(*models.APIKeyWithSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey
+func (mr *MockUseCaseMockRecorder) CreateAPIKey(ctx, apiKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockUseCase)(nil).CreateAPIKey), ctx, apiKey)
+}
+
+// GetAPIKeys mocks base method
+func (m *MockUseCase) GetAPIKeys(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeys", ctx, userID)
+	ret0, _ := ret[0].([]*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeys indicates an expected call of GetAPIKeys
+func (mr *MockUseCaseMockRecorder) GetAPIKeys(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeys", reflect.TypeOf((*MockUseCase)(nil).GetAPIKeys), ctx, userID)
+}
+
+// GetAPIKeyByID mocks base method
+func (m *MockUseCase) GetAPIKeyByID(ctx context.Context, userID, apiKeyID uuid.UUID) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByID", ctx, userID, apiKeyID)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByID indicates an expected call of GetAPIKeyByID
+func (mr *MockUseCaseMockRecorder) GetAPIKeyByID(ctx, userID, apiKeyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByID", reflect.TypeOf((*MockUseCase)(nil).GetAPIKeyByID), ctx, userID, apiKeyID)
+}
+
+// UpdateAPIKey mocks base method
+func (m *MockUseCase) UpdateAPIKey(ctx context.Context, apiKey *models.APIKey) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAPIKey", ctx, apiKey)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAPIKey indicates an expected call of UpdateAPIKey
+func (mr *MockUseCaseMockRecorder) UpdateAPIKey(ctx, apiKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPIKey", reflect.TypeOf((*MockUseCase)(nil).UpdateAPIKey), ctx, apiKey)
+}
+
+// DeleteAPIKey mocks base method
+func (m *MockUseCase) DeleteAPIKey(ctx context.Context, userID, apiKeyID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIKey", ctx, userID, apiKeyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAPIKey indicates an expected call of DeleteAPIKey
+func (mr *MockUseCaseMockRecorder) DeleteAPIKey(ctx, userID, apiKeyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockUseCase)(nil).DeleteAPIKey), ctx, userID, apiKeyID)
+}
+
+// AuthenticateAPIKey mocks base method
+func (m *MockUseCase) AuthenticateAPIKey(ctx context.Context, key string) (*models.User, *models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateAPIKey", ctx, key)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(*models.APIKey)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AuthenticateAPIKey indicates an expected call of AuthenticateAPIKey
+func (mr *MockUseCaseMockRecorder) AuthenticateAPIKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateAPIKey", reflect.TypeOf((*MockUseCase)(nil).AuthenticateAPIKey), ctx, key)
+}
+
+// GetJWKS mocks base method
+func (m *MockUseCase) GetJWKS(ctx context.Context) *jwtkeys.JWKS {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJWKS", ctx)
+	ret0, _ := ret[0].(*jwtkeys.JWKS)
+	return ret0
+}
+
+// GetJWKS indicates an expected call of GetJWKS
+func (mr *MockUseCaseMockRecorder) GetJWKS(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJWKS", reflect.TypeOf((*MockUseCase)(nil).GetJWKS), ctx)
+}
+
+// GetPermissions mocks base method
+func (m *MockUseCase) GetPermissions(ctx context.Context, user *models.User) (*models.UserPermissions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPermissions", ctx, user)
+	ret0, _ := ret[0].(*models.UserPermissions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPermissions indicates an expected call of GetPermissions
+func (mr *MockUseCaseMockRecorder) GetPermissions(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPermissions", reflect.TypeOf((*MockUseCase)(nil).GetPermissions), ctx, user)
+}
+
+// GetRoles mocks base method
+func (m *MockUseCase) GetRoles(ctx context.Context) ([]*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoles", ctx)
+	ret0, _ := ret[0].([]*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoles indicates an expected call of GetRoles
+func (mr *MockUseCaseMockRecorder) GetRoles(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoles", reflect.TypeOf((*MockUseCase)(nil).GetRoles), ctx)
+}
+
+// UpdateRole mocks base method
+func (m *MockUseCase) UpdateRole(ctx context.Context, userID uuid.UUID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", ctx, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRole indicates an expected call of UpdateRole
+func (mr *MockUseCaseMockRecorder) UpdateRole(ctx, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockUseCase)(nil).UpdateRole), ctx, userID, role)
+}
+
+// SendMagicLink mocks base method
+func (m *MockUseCase) SendMagicLink(ctx context.Context, email, ipAddress string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMagicLink", ctx, email, ipAddress)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMagicLink indicates an expected call of SendMagicLink
+func (mr *MockUseCaseMockRecorder) SendMagicLink(ctx, email, ipAddress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMagicLink", reflect.TypeOf((*MockUseCase)(nil).SendMagicLink), ctx, email, ipAddress)
+}
+
+// LoginMagicLink mocks base method
+func (m *MockUseCase) LoginMagicLink(ctx context.Context, loginToken string) (*models.UserWithToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginMagicLink", ctx, loginToken)
+	ret0, _ := ret[0].(*models.UserWithToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginMagicLink indicates an expected call of LoginMagicLink
+func (mr *MockUseCaseMockRecorder) LoginMagicLink(ctx, loginToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginMagicLink", reflect.TypeOf((*MockUseCase)(nil).LoginMagicLink), ctx, loginToken)
+}
+
+// OIDCAuthURL mocks base method
+func (m *MockUseCase) OIDCAuthURL(ctx context.Context, provider string) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OIDCAuthURL", ctx, provider)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// OIDCAuthURL indicates an expected call of OIDCAuthURL
+func (mr *MockUseCaseMockRecorder) OIDCAuthURL(ctx, provider interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OIDCAuthURL", reflect.TypeOf((*MockUseCase)(nil).OIDCAuthURL), ctx, provider)
+}
+
+// OIDCLogin mocks base method
+func (m *MockUseCase) OIDCLogin(ctx context.Context, provider, state, code string) (*models.UserWithToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OIDCLogin", ctx, provider, state, code)
+	ret0, _ := ret[0].(*models.UserWithToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OIDCLogin indicates an expected call of OIDCLogin
+func (mr *MockUseCaseMockRecorder) OIDCLogin(ctx, provider, state, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OIDCLogin", reflect.TypeOf((*MockUseCase)(nil).OIDCLogin), ctx, provider, state, code)
+}
@@ -3,6 +3,7 @@ package auth
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -15,8 +16,37 @@ type Repository interface {
 	Register(ctx context.Context, user *models.User) (*models.User, error)
 	Update(ctx context.Context, user *models.User) (*models.User, error)
 	Delete(ctx context.Context, userID uuid.UUID) error
+	Restore(ctx context.Context, userID uuid.UUID, deletedAfter time.Time) error
+	AnonymizeDeletedUsers(ctx context.Context, deletedBefore time.Time, limit int) (int64, error)
+	HardDeleteDeletedUsers(ctx context.Context, deletedBefore time.Time, limit int) (int64, error)
 	GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error)
-	FindByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.UsersList, error)
+	FindByName(ctx context.Context, name string, similarity float64, query *utils.PaginationQuery) (*models.UsersList, error)
+	FindSuggestions(ctx context.Context, name string, similarity float64, limit int) ([]*models.UserSuggestion, error)
 	FindByEmail(ctx context.Context, user *models.User) (*models.User, error)
 	GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error)
+	UpdatePassword(ctx context.Context, userID uuid.UUID, password string) error
+	VerifyEmail(ctx context.Context, userID uuid.UUID, email string) error
+	SetPendingEmail(ctx context.Context, userID uuid.UUID, email string) error
+	ConfirmPendingEmail(ctx context.Context, userID uuid.UUID, email string) error
+	RevertEmail(ctx context.Context, userID uuid.UUID, email string) error
+	UpdateLockedUntil(ctx context.Context, userID uuid.UUID, lockedUntil *time.Time) error
+	UpdateStatus(ctx context.Context, userID uuid.UUID, status *models.UserStatus) error
+	CreateTOTP(ctx context.Context, userID uuid.UUID, secret string) error
+	GetTOTP(ctx context.Context, userID uuid.UUID) (*models.UserTOTP, error)
+	UpdateTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error
+	EnableTwoFactor(ctx context.Context, userID uuid.UUID, recoveryCodeHashes []string) error
+	DisableTwoFactor(ctx context.Context, userID uuid.UUID) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error
+	CreateAPIKey(ctx context.Context, apiKey *models.APIKey) (*models.APIKey, error)
+	GetAPIKeysByUserID(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error)
+	GetAPIKeyByID(ctx context.Context, userID uuid.UUID, apiKeyID uuid.UUID) (*models.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	UpdateAPIKey(ctx context.Context, apiKey *models.APIKey) (*models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, userID uuid.UUID, apiKeyID uuid.UUID) error
+	UpdateAPIKeyLastUsed(ctx context.Context, apiKeyID uuid.UUID, lastUsedAt time.Time) error
+	GetRoles(ctx context.Context) ([]*models.Role, error)
+	GetPermissionsByRole(ctx context.Context, role string) ([]string, error)
+	UpdateRole(ctx context.Context, userID uuid.UUID, role string) error
+	GetUserIdentity(ctx context.Context, provider string, subject string) (*models.UserIdentity, error)
+	CreateUserIdentity(ctx context.Context, identity *models.UserIdentity) (*models.UserIdentity, error)
 }
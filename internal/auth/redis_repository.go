@@ -3,6 +3,7 @@ package auth
 
 import (
 	"context"
+	"time"
 
 	"github.com/AleksK1NG/api-mc/internal/models"
 )
@@ -12,4 +13,23 @@ type RedisRepository interface {
 	GetByIDCtx(ctx context.Context, key string) (*models.User, error)
 	SetUserCtx(ctx context.Context, key string, seconds int, user *models.User) error
 	DeleteUserCtx(ctx context.Context, key string) error
+	GetRefreshTokenCtx(ctx context.Context, key string) (*models.RefreshToken, error)
+	SetRefreshTokenCtx(ctx context.Context, key string, seconds int, token *models.RefreshToken) error
+	MarkRefreshTokenUsedCtx(ctx context.Context, key string) (bool, error)
+	SetTokenFamilyCtx(ctx context.Context, key string, seconds int) error
+	ExistsTokenFamilyCtx(ctx context.Context, key string) (bool, error)
+	DeleteTokenFamilyCtx(ctx context.Context, key string) error
+	AddUserTokenFamilyCtx(ctx context.Context, key string, familyID string, seconds int) error
+	GetUserTokenFamiliesCtx(ctx context.Context, key string) ([]string, error)
+	RemoveUserTokenFamiliesCtx(ctx context.Context, key string, familyIDs []string) error
+	SetUserTokenCtx(ctx context.Context, key string, seconds int, token *models.UserToken) error
+	ConsumeUserTokenCtx(ctx context.Context, key string) (*models.UserToken, error)
+	IncrLoginAttemptsCtx(ctx context.Context, key string, seconds int) (int64, error)
+	GetLoginAttemptsCtx(ctx context.Context, key string) (int64, time.Duration, error)
+	DeleteLoginAttemptsCtx(ctx context.Context, key string) error
+	GetPermissionsCtx(ctx context.Context, key string) (*models.UserPermissions, error)
+	SetPermissionsCtx(ctx context.Context, key string, seconds int, permissions *models.UserPermissions) error
+	DeletePermissionsCtx(ctx context.Context, key string) error
+	SetOIDCAuthRequestCtx(ctx context.Context, key string, seconds int, authRequest *models.OIDCAuthRequest) error
+	ConsumeOIDCAuthRequestCtx(ctx context.Context, key string) (*models.OIDCAuthRequest, error)
 }
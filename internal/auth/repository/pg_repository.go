@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -31,7 +33,7 @@ func (r *authRepo) Register(ctx context.Context, user *models.User) (*models.Use
 
 	u := &models.User{}
 	if err := r.db.QueryRowxContext(ctx, createUserQuery, &user.FirstName, &user.LastName, &user.Email,
-		&user.Password, &user.Role, &user.About, &user.Avatar, &user.PhoneNumber, &user.Address, &user.City,
+		&user.Password, &user.About, &user.Avatar, &user.PhoneNumber, &user.Address, &user.City,
 		&user.Gender, &user.Postcode, &user.Birthday,
 	).StructScan(u); err != nil {
 		return nil, errors.Wrap(err, "authRepo.Register.StructScan")
@@ -47,7 +49,7 @@ func (r *authRepo) Update(ctx context.Context, user *models.User) (*models.User,
 
 	u := &models.User{}
 	if err := r.db.GetContext(ctx, u, updateUserQuery, &user.FirstName, &user.LastName, &user.Email,
-		&user.Role, &user.About, &user.Avatar, &user.PhoneNumber, &user.Address, &user.City, &user.Gender,
+		&user.About, &user.Avatar, &user.PhoneNumber, &user.Address, &user.City, &user.Gender,
 		&user.Postcode, &user.Birthday, &user.UserID,
 	); err != nil {
 		return nil, errors.Wrap(err, "authRepo.Update.GetContext")
@@ -56,7 +58,7 @@ func (r *authRepo) Update(ctx context.Context, user *models.User) (*models.User,
 	return u, nil
 }
 
-// Delete existing user
+// Soft delete existing user, deleted user is hidden until restored or purged
 func (r *authRepo) Delete(ctx context.Context, userID uuid.UUID) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.Delete")
 	defer span.Finish()
@@ -76,6 +78,56 @@ func (r *authRepo) Delete(ctx context.Context, userID uuid.UUID) error {
 	return nil
 }
 
+// Restore user deleted after given time and not purged yet
+func (r *authRepo) Restore(ctx context.Context, userID uuid.UUID, deletedAfter time.Time) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.Restore")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, restoreUserQuery, userID, deletedAfter)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.Restore.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "authRepo.Restore.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.Restore.rowsAffected")
+	}
+
+	return nil
+}
+
+// Anonymize batch of users deleted before given time and drop their credentials, returns number of anonymized users
+func (r *authRepo) AnonymizeDeletedUsers(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.AnonymizeDeletedUsers")
+	defer span.Finish()
+
+	var anonymized int64
+	if err := r.db.GetContext(ctx, &anonymized, anonymizeDeletedUsersQuery, deletedBefore, limit); err != nil {
+		return 0, errors.Wrap(err, "authRepo.AnonymizeDeletedUsers.GetContext")
+	}
+
+	return anonymized, nil
+}
+
+// Hard delete batch of users deleted before given time, users who authored news are skipped, returns number of deleted users
+func (r *authRepo) HardDeleteDeletedUsers(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.HardDeleteDeletedUsers")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, hardDeleteDeletedUsersQuery, deletedBefore, limit)
+	if err != nil {
+		return 0, errors.Wrap(err, "authRepo.HardDeleteDeletedUsers.ExecContext")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "authRepo.HardDeleteDeletedUsers.RowsAffected")
+	}
+
+	return deleted, nil
+}
+
 // Get user by id
 func (r *authRepo) GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GetByID")
@@ -88,13 +140,20 @@ func (r *authRepo) GetByID(ctx context.Context, userID uuid.UUID) (*models.User,
 	return user, nil
 }
 
-// Find users by name
-func (r *authRepo) FindByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.UsersList, error) {
+// Fuzzy find users by full name, most similar first.
+// Words of name must be at least similarity similar to some part of full name.
+func (r *authRepo) FindByName(ctx context.Context, name string, similarity float64, query *utils.PaginationQuery) (*models.UsersList, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.FindByName")
 	defer span.Finish()
 
+	tx, err := r.beginSimilarityTx(ctx, similarity)
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.FindByName")
+	}
+	defer tx.Rollback()
+
 	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, getTotalCount, name); err != nil {
+	if err = tx.GetContext(ctx, &totalCount, getTotalCount, name); err != nil {
 		return nil, errors.Wrap(err, "authRepo.FindByName.GetContext.totalCount")
 	}
 
@@ -109,7 +168,7 @@ func (r *authRepo) FindByName(ctx context.Context, name string, query *utils.Pag
 		}, nil
 	}
 
-	rows, err := r.db.QueryxContext(ctx, findUsers, name, query.GetOffset(), query.GetLimit())
+	rows, err := tx.QueryxContext(ctx, findUsers, name, query.GetOffset(), query.GetLimit())
 	if err != nil {
 		return nil, errors.Wrap(err, "authRepo.FindByName.QueryxContext")
 	}
@@ -138,6 +197,38 @@ func (r *authRepo) FindByName(ctx context.Context, name string, query *utils.Pag
 	}, nil
 }
 
+// Fuzzy find user suggestions by full name or email, most similar first, only for authenticated users
+func (r *authRepo) FindSuggestions(ctx context.Context, name string, similarity float64, limit int) ([]*models.UserSuggestion, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.FindSuggestions")
+	defer span.Finish()
+
+	tx, err := r.beginSimilarityTx(ctx, similarity)
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.FindSuggestions")
+	}
+	defer tx.Rollback()
+
+	suggestions := make([]*models.UserSuggestion, 0, limit)
+	if err = tx.SelectContext(ctx, &suggestions, findUserSuggestions, name, limit); err != nil {
+		return nil, errors.Wrap(err, "authRepo.FindSuggestions.SelectContext")
+	}
+	return suggestions, nil
+}
+
+// Begin read only transaction with pg_trgm word similarity threshold set for it, so similarity operators
+// can use trigram indexes. Transaction is only rolled back, threshold is reset with it
+func (r *authRepo) beginSimilarityTx(ctx context.Context, similarity float64) (*sqlx.Tx, error) {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, errors.Wrap(err, "authRepo.beginSimilarityTx.BeginTxx")
+	}
+	if _, err = tx.ExecContext(ctx, setWordSimilarityThreshold, strconv.FormatFloat(similarity, 'f', -1, 64)); err != nil {
+		tx.Rollback()
+		return nil, errors.Wrap(err, "authRepo.beginSimilarityTx.ExecContext")
+	}
+	return tx, nil
+}
+
 // Get users with pagination
 func (r *authRepo) GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GetUsers")
@@ -192,3 +283,476 @@ func (r *authRepo) FindByEmail(ctx context.Context, user *models.User) (*models.
 	}
 	return foundUser, nil
 }
+
+// Update user password hash
+func (r *authRepo) UpdatePassword(ctx context.Context, userID uuid.UUID, password string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.UpdatePassword")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, updatePasswordQuery, password, userID)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.UpdatePassword.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "authRepo.UpdatePassword.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.UpdatePassword.rowsAffected")
+	}
+
+	return nil
+}
+
+// Mark user email as verified, email must be the same as at token issue time
+func (r *authRepo) VerifyEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.VerifyEmail")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, verifyEmailQuery, userID, email)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.VerifyEmail.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "authRepo.VerifyEmail.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.VerifyEmail.rowsAffected")
+	}
+
+	return nil
+}
+
+// Store new email awaiting confirmation
+func (r *authRepo) SetPendingEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.SetPendingEmail")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, setPendingEmailQuery, email, userID)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.SetPendingEmail.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "authRepo.SetPendingEmail.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.SetPendingEmail.rowsAffected")
+	}
+
+	return nil
+}
+
+// Replace user email with confirmed pending email, new email is marked as verified
+func (r *authRepo) ConfirmPendingEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.ConfirmPendingEmail")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, confirmPendingEmailQuery, userID, email)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.ConfirmPendingEmail.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "authRepo.ConfirmPendingEmail.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.ConfirmPendingEmail.rowsAffected")
+	}
+
+	return nil
+}
+
+// Restore previous user email and clear pending email
+func (r *authRepo) RevertEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.RevertEmail")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, revertEmailQuery, email, userID)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.RevertEmail.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "authRepo.RevertEmail.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.RevertEmail.rowsAffected")
+	}
+
+	return nil
+}
+
+// Set or clear user account lock
+func (r *authRepo) UpdateLockedUntil(ctx context.Context, userID uuid.UUID, lockedUntil *time.Time) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.UpdateLockedUntil")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, updateLockedUntilQuery, lockedUntil, userID)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.UpdateLockedUntil.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "authRepo.UpdateLockedUntil.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.UpdateLockedUntil.rowsAffected")
+	}
+
+	return nil
+}
+
+// Update user account status
+func (r *authRepo) UpdateStatus(ctx context.Context, userID uuid.UUID, status *models.UserStatus) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.UpdateStatus")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, updateStatusQuery, status.Status, status.Reason, status.Until, userID)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.UpdateStatus.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "authRepo.UpdateStatus.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.UpdateStatus.rowsAffected")
+	}
+
+	return nil
+}
+
+// Create not confirmed TOTP secret, replaces previous not confirmed secret
+func (r *authRepo) CreateTOTP(ctx context.Context, userID uuid.UUID, secret string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.CreateTOTP")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, upsertTOTPQuery, userID, secret)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.CreateTOTP.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "authRepo.CreateTOTP.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.CreateTOTP.rowsAffected")
+	}
+
+	return nil
+}
+
+// Get user TOTP secret
+func (r *authRepo) GetTOTP(ctx context.Context, userID uuid.UUID) (*models.UserTOTP, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GetTOTP")
+	defer span.Finish()
+
+	userTOTP := &models.UserTOTP{}
+	if err := r.db.GetContext(ctx, userTOTP, getTOTPQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetTOTP.GetContext")
+	}
+	return userTOTP, nil
+}
+
+// Save last accepted TOTP time step, older or same steps are rejected to prevent code replay
+func (r *authRepo) UpdateTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.UpdateTOTPStep")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, updateTOTPStepQuery, step, userID)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.UpdateTOTPStep.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "authRepo.UpdateTOTPStep.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.UpdateTOTPStep.rowsAffected")
+	}
+
+	return nil
+}
+
+// Confirm TOTP secret, enable two factor for the user and replace recovery codes
+func (r *authRepo) EnableTwoFactor(ctx context.Context, userID uuid.UUID, recoveryCodeHashes []string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.EnableTwoFactor")
+	defer span.Finish()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.EnableTwoFactor.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, confirmTOTPQuery, userID)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.EnableTwoFactor.ExecContext.confirmTOTP")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "authRepo.EnableTwoFactor.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.EnableTwoFactor.rowsAffected")
+	}
+
+	if _, err = tx.ExecContext(ctx, enableTwoFactorQuery, true, userID); err != nil {
+		return errors.Wrap(err, "authRepo.EnableTwoFactor.ExecContext.enableTwoFactor")
+	}
+
+	if _, err = tx.ExecContext(ctx, deleteRecoveryCodesQuery, userID); err != nil {
+		return errors.Wrap(err, "authRepo.EnableTwoFactor.ExecContext.deleteRecoveryCodes")
+	}
+
+	for _, codeHash := range recoveryCodeHashes {
+		if _, err = tx.ExecContext(ctx, createRecoveryCodeQuery, codeHash, userID); err != nil {
+			return errors.Wrap(err, "authRepo.EnableTwoFactor.ExecContext.createRecoveryCode")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "authRepo.EnableTwoFactor.Commit")
+	}
+	return nil
+}
+
+// Disable two factor for the user removing TOTP secret and recovery codes
+func (r *authRepo) DisableTwoFactor(ctx context.Context, userID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.DisableTwoFactor")
+	defer span.Finish()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.DisableTwoFactor.BeginTxx")
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, deleteTOTPQuery, userID); err != nil {
+		return errors.Wrap(err, "authRepo.DisableTwoFactor.ExecContext.deleteTOTP")
+	}
+
+	if _, err = tx.ExecContext(ctx, deleteRecoveryCodesQuery, userID); err != nil {
+		return errors.Wrap(err, "authRepo.DisableTwoFactor.ExecContext.deleteRecoveryCodes")
+	}
+
+	if _, err = tx.ExecContext(ctx, enableTwoFactorQuery, false, userID); err != nil {
+		return errors.Wrap(err, "authRepo.DisableTwoFactor.ExecContext.enableTwoFactor")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "authRepo.DisableTwoFactor.Commit")
+	}
+	return nil
+}
+
+// Mark recovery code as used, each code can be used only once
+func (r *authRepo) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.UseRecoveryCode")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, useRecoveryCodeQuery, userID, codeHash)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.UseRecoveryCode.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "authRepo.UseRecoveryCode.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.UseRecoveryCode.rowsAffected")
+	}
+
+	return nil
+}
+
+// Create API key
+func (r *authRepo) CreateAPIKey(ctx context.Context, apiKey *models.APIKey) (*models.APIKey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.CreateAPIKey")
+	defer span.Finish()
+
+	k := &models.APIKey{}
+	if err := r.db.QueryRowxContext(ctx, createAPIKeyQuery, apiKey.UserID, apiKey.Name, apiKey.Prefix,
+		apiKey.KeyHash, apiKey.Scopes, apiKey.ExpiresAt,
+	).StructScan(k); err != nil {
+		return nil, errors.Wrap(err, "authRepo.CreateAPIKey.StructScan")
+	}
+
+	return k, nil
+}
+
+// Get all API keys of user
+func (r *authRepo) GetAPIKeysByUserID(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GetAPIKeysByUserID")
+	defer span.Finish()
+
+	apiKeys := make([]*models.APIKey, 0)
+	if err := r.db.SelectContext(ctx, &apiKeys, getAPIKeysByUserIDQuery, userID); err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetAPIKeysByUserID.SelectContext")
+	}
+
+	return apiKeys, nil
+}
+
+// Get user API key by id
+func (r *authRepo) GetAPIKeyByID(ctx context.Context, userID uuid.UUID, apiKeyID uuid.UUID) (*models.APIKey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GetAPIKeyByID")
+	defer span.Finish()
+
+	apiKey := &models.APIKey{}
+	if err := r.db.GetContext(ctx, apiKey, getAPIKeyByIDQuery, userID, apiKeyID); err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetAPIKeyByID.GetContext")
+	}
+
+	return apiKey, nil
+}
+
+// Get API key by key hash
+func (r *authRepo) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GetAPIKeyByHash")
+	defer span.Finish()
+
+	apiKey := &models.APIKey{}
+	if err := r.db.GetContext(ctx, apiKey, getAPIKeyByHashQuery, keyHash); err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetAPIKeyByHash.GetContext")
+	}
+
+	return apiKey, nil
+}
+
+// Update API key name and scopes
+func (r *authRepo) UpdateAPIKey(ctx context.Context, apiKey *models.APIKey) (*models.APIKey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.UpdateAPIKey")
+	defer span.Finish()
+
+	k := &models.APIKey{}
+	if err := r.db.GetContext(ctx, k, updateAPIKeyQuery, apiKey.Name, apiKey.Scopes, apiKey.UserID, apiKey.APIKeyID); err != nil {
+		return nil, errors.Wrap(err, "authRepo.UpdateAPIKey.GetContext")
+	}
+
+	return k, nil
+}
+
+// Delete user API key
+func (r *authRepo) DeleteAPIKey(ctx context.Context, userID uuid.UUID, apiKeyID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.DeleteAPIKey")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, deleteAPIKeyQuery, userID, apiKeyID)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.DeleteAPIKey.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "authRepo.DeleteAPIKey.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.DeleteAPIKey.rowsAffected")
+	}
+
+	return nil
+}
+
+// Update API key last used timestamp
+func (r *authRepo) UpdateAPIKeyLastUsed(ctx context.Context, apiKeyID uuid.UUID, lastUsedAt time.Time) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.UpdateAPIKeyLastUsed")
+	defer span.Finish()
+
+	if _, err := r.db.ExecContext(ctx, updateAPIKeyLastUsedQuery, lastUsedAt, apiKeyID); err != nil {
+		return errors.Wrap(err, "authRepo.UpdateAPIKeyLastUsed.ExecContext")
+	}
+
+	return nil
+}
+
+// Get all roles with permissions
+func (r *authRepo) GetRoles(ctx context.Context) ([]*models.Role, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GetRoles")
+	defer span.Finish()
+
+	roles := make([]*models.Role, 0)
+	if err := r.db.SelectContext(ctx, &roles, getRolesQuery); err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetRoles.SelectContext.roles")
+	}
+
+	rolePermissions := make([]*models.RolePermission, 0)
+	if err := r.db.SelectContext(ctx, &rolePermissions, getRolePermissionsQuery); err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetRoles.SelectContext.rolePermissions")
+	}
+
+	rolesByName := make(map[string]*models.Role, len(roles))
+	for _, role := range roles {
+		role.Permissions = make([]string, 0)
+		rolesByName[role.Name] = role
+	}
+	for _, rp := range rolePermissions {
+		if role, ok := rolesByName[rp.Role]; ok {
+			role.Permissions = append(role.Permissions, rp.Permission)
+		}
+	}
+
+	return roles, nil
+}
+
+// Get permissions granted to role
+func (r *authRepo) GetPermissionsByRole(ctx context.Context, role string) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GetPermissionsByRole")
+	defer span.Finish()
+
+	permissions := make([]string, 0)
+	if err := r.db.SelectContext(ctx, &permissions, getPermissionsByRoleQuery, role); err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetPermissionsByRole.SelectContext")
+	}
+
+	return permissions, nil
+}
+
+// Update user role
+func (r *authRepo) UpdateRole(ctx context.Context, userID uuid.UUID, role string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.UpdateRole")
+	defer span.Finish()
+
+	result, err := r.db.ExecContext(ctx, updateRoleQuery, role, userID)
+	if err != nil {
+		return errors.Wrap(err, "authRepo.UpdateRole.ExecContext")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "authRepo.UpdateRole.RowsAffected")
+	}
+	if rowsAffected == 0 {
+		return errors.Wrap(sql.ErrNoRows, "authRepo.UpdateRole.rowsAffected")
+	}
+
+	return nil
+}
+
+// Get external identity by provider and subject
+func (r *authRepo) GetUserIdentity(ctx context.Context, provider string, subject string) (*models.UserIdentity, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.GetUserIdentity")
+	defer span.Finish()
+
+	identity := &models.UserIdentity{}
+	if err := r.db.GetContext(ctx, identity, getUserIdentityQuery, provider, subject); err != nil {
+		return nil, errors.Wrap(err, "authRepo.GetUserIdentity.GetContext")
+	}
+
+	return identity, nil
+}
+
+// Link external identity to user
+func (r *authRepo) CreateUserIdentity(ctx context.Context, identity *models.UserIdentity) (*models.UserIdentity, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRepo.CreateUserIdentity")
+	defer span.Finish()
+
+	i := &models.UserIdentity{}
+	if err := r.db.QueryRowxContext(ctx, createUserIdentityQuery, identity.UserID, identity.Provider,
+		identity.Subject, identity.Email,
+	).StructScan(i); err != nil {
+		return nil, errors.Wrap(err, "authRepo.CreateUserIdentity.StructScan")
+	}
+
+	return i, nil
+}
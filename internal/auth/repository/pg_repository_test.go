@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
@@ -28,10 +29,10 @@ func TestAuthRepo_Register(t *testing.T) {
 
 	t.Run("Register", func(t *testing.T) {
 		gender := "male"
-		role := "admin"
+		role := "user"
 
 		rows := sqlmock.NewRows([]string{"first_name", "last_name", "password", "email", "role", "gender"}).AddRow(
-			"Alex", "Bryksin", "123456", "alex@gmail.com", "admin", &gender)
+			"Alex", "Bryksin", "123456", "alex@gmail.com", "user", &gender)
 
 		user := &models.User{
 			FirstName: "Alex",
@@ -43,7 +44,7 @@ func TestAuthRepo_Register(t *testing.T) {
 		}
 
 		mock.ExpectQuery(createUserQuery).WithArgs(&user.FirstName, &user.LastName, &user.Email,
-			&user.Password, &user.Role, &user.About, &user.Avatar, &user.PhoneNumber, &user.Address, &user.City,
+			&user.Password, &user.About, &user.Avatar, &user.PhoneNumber, &user.Address, &user.City,
 			&user.Gender, &user.Postcode, &user.Birthday).WillReturnRows(rows)
 
 		createdUser, err := authRepo.Register(context.Background(), user)
@@ -125,6 +126,58 @@ func TestAuthRepo_Delete(t *testing.T) {
 	})
 }
 
+func TestAuthRepo_Restore(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+	deletedAfter := time.Now().Add(-time.Hour)
+
+	t.Run("Restore", func(t *testing.T) {
+		uid := uuid.New()
+
+		mock.ExpectExec(restoreUserQuery).WithArgs(uid, deletedAfter).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := authRepo.Restore(context.Background(), uid, deletedAfter)
+		require.NoError(t, err)
+	})
+
+	t.Run("Grace period is over", func(t *testing.T) {
+		uid := uuid.New()
+
+		mock.ExpectExec(restoreUserQuery).WithArgs(uid, deletedAfter).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := authRepo.Restore(context.Background(), uid, deletedAfter)
+		require.Error(t, err)
+	})
+}
+
+func TestAuthRepo_AnonymizeDeletedUsers(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+	deletedBefore := time.Now().Add(-time.Hour)
+
+	mock.ExpectQuery(anonymizeDeletedUsersQuery).WithArgs(deletedBefore, 100).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	anonymized, err := authRepo.AnonymizeDeletedUsers(context.Background(), deletedBefore, 100)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), anonymized)
+}
+
 func TestAuthRepo_Update(t *testing.T) {
 	t.Parallel()
 
@@ -139,10 +192,10 @@ func TestAuthRepo_Update(t *testing.T) {
 
 	t.Run("Update", func(t *testing.T) {
 		gender := "male"
-		role := "admin"
+		role := "user"
 
 		rows := sqlmock.NewRows([]string{"first_name", "last_name", "password", "email", "role", "gender"}).AddRow(
-			"Alex", "Bryksin", "123456", "alex@gmail.com", "admin", &gender)
+			"Alex", "Bryksin", "123456", "alex@gmail.com", "user", &gender)
 
 		user := &models.User{
 			FirstName: "Alex",
@@ -154,7 +207,7 @@ func TestAuthRepo_Update(t *testing.T) {
 		}
 
 		mock.ExpectQuery(updateUserQuery).WithArgs(&user.FirstName, &user.LastName, &user.Email,
-			&user.Role, &user.About, &user.Avatar, &user.PhoneNumber, &user.Address, &user.City, &user.Gender,
+			&user.About, &user.Avatar, &user.PhoneNumber, &user.Address, &user.City, &user.Gender,
 			&user.Postcode, &user.Birthday, &user.UserID).WillReturnRows(rows)
 
 		updatedUser, err := authRepo.Update(context.Background(), user)
@@ -250,15 +303,18 @@ func TestAuthRepo_FindByName(t *testing.T) {
 		uid := uuid.New()
 		userName := "Alex"
 
-		totalCountRows := sqlmock.NewRows([]string{"count"}).AddRow(0)
+		totalCountRows := sqlmock.NewRows([]string{"count"}).AddRow(1)
 
-		rows := sqlmock.NewRows([]string{"user_id", "first_name", "last_name", "email"}).AddRow(
-			uid, "Alex", "Bryksin", "alex@mail.ru")
+		rows := sqlmock.NewRows([]string{"user_id", "first_name", "last_name"}).AddRow(
+			uid, "Alex", "Bryksin")
 
-		mock.ExpectQuery(getTotalCount).WillReturnRows(totalCountRows)
-		mock.ExpectQuery(findUsers).WithArgs("", 0, 10).WillReturnRows(rows)
+		mock.ExpectBegin()
+		mock.ExpectExec(setWordSimilarityThreshold).WithArgs("0.3").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(getTotalCount).WithArgs(userName).WillReturnRows(totalCountRows)
+		mock.ExpectQuery(findUsers).WithArgs(userName, 0, 10).WillReturnRows(rows)
+		mock.ExpectRollback()
 
-		usersList, err := authRepo.FindByName(context.Background(), userName, &utils.PaginationQuery{
+		usersList, err := authRepo.FindByName(context.Background(), userName, 0.3, &utils.PaginationQuery{
 			Size:    10,
 			Page:    1,
 			OrderBy: "",
@@ -266,5 +322,407 @@ func TestAuthRepo_FindByName(t *testing.T) {
 
 		require.NoError(t, err)
 		require.NotNil(t, usersList)
+		require.Len(t, usersList.Users, 1)
+		require.Equal(t, uid, usersList.Users[0].UserID)
+		require.Empty(t, usersList.Users[0].Email)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Public search does not match email", func(t *testing.T) {
+		require.NotContains(t, getTotalCount, "email")
+		require.NotContains(t, findUsers, "email")
+	})
+}
+
+func TestAuthRepo_FindSuggestions(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+
+	t.Run("FindSuggestions", func(t *testing.T) {
+		uid := uuid.New()
+		name := "alx"
+
+		rows := sqlmock.NewRows([]string{"user_id", "first_name", "last_name", "avatar"}).AddRow(
+			uid, "Alex", "Bryksin", nil)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(setWordSimilarityThreshold).WithArgs("0.25").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(findUserSuggestions).WithArgs(name, 5).WillReturnRows(rows)
+		mock.ExpectRollback()
+
+		suggestions, err := authRepo.FindSuggestions(context.Background(), name, 0.25, 5)
+		require.NoError(t, err)
+		require.Len(t, suggestions, 1)
+		require.Equal(t, uid, suggestions[0].UserID)
+		require.Nil(t, suggestions[0].Avatar)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAuthRepo_UpdatePassword(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+
+	t.Run("UpdatePassword", func(t *testing.T) {
+		uid := uuid.New()
+		password := "hashed password"
+
+		mock.ExpectExec(updatePasswordQuery).WithArgs(password, uid).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := authRepo.UpdatePassword(context.Background(), uid, password)
+		require.NoError(t, err)
+	})
+
+	t.Run("UpdatePassword No rows", func(t *testing.T) {
+		uid := uuid.New()
+		password := "hashed password"
+
+		mock.ExpectExec(updatePasswordQuery).WithArgs(password, uid).WillReturnResult(sqlmock.NewResult(1, 0))
+
+		err := authRepo.UpdatePassword(context.Background(), uid, password)
+		require.NotNil(t, err)
+	})
+}
+
+func TestAuthRepo_VerifyEmail(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+
+	t.Run("VerifyEmail", func(t *testing.T) {
+		uid := uuid.New()
+		email := "alex@gmail.com"
+
+		mock.ExpectExec(verifyEmailQuery).WithArgs(uid, email).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := authRepo.VerifyEmail(context.Background(), uid, email)
+		require.NoError(t, err)
+	})
+
+	t.Run("VerifyEmail No rows", func(t *testing.T) {
+		uid := uuid.New()
+		email := "alex@gmail.com"
+
+		mock.ExpectExec(verifyEmailQuery).WithArgs(uid, email).WillReturnResult(sqlmock.NewResult(1, 0))
+
+		err := authRepo.VerifyEmail(context.Background(), uid, email)
+		require.NotNil(t, err)
+	})
+}
+
+func TestAuthRepo_EnableTwoFactor(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+
+	t.Run("EnableTwoFactor", func(t *testing.T) {
+		uid := uuid.New()
+		hashes := []string{"first hash", "second hash"}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(confirmTOTPQuery).WithArgs(uid).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(enableTwoFactorQuery).WithArgs(true, uid).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(deleteRecoveryCodesQuery).WithArgs(uid).WillReturnResult(sqlmock.NewResult(0, 0))
+		for _, hash := range hashes {
+			mock.ExpectExec(createRecoveryCodeQuery).WithArgs(hash, uid).WillReturnResult(sqlmock.NewResult(1, 1))
+		}
+		mock.ExpectCommit()
+
+		err := authRepo.EnableTwoFactor(context.Background(), uid, hashes)
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("EnableTwoFactor Not enrolled", func(t *testing.T) {
+		uid := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(confirmTOTPQuery).WithArgs(uid).WillReturnResult(sqlmock.NewResult(1, 0))
+		mock.ExpectRollback()
+
+		err := authRepo.EnableTwoFactor(context.Background(), uid, nil)
+		require.NotNil(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAuthRepo_UseRecoveryCode(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+
+	t.Run("UseRecoveryCode", func(t *testing.T) {
+		uid := uuid.New()
+
+		mock.ExpectExec(useRecoveryCodeQuery).WithArgs(uid, "hash").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := authRepo.UseRecoveryCode(context.Background(), uid, "hash")
+		require.NoError(t, err)
+	})
+
+	t.Run("UseRecoveryCode Already used", func(t *testing.T) {
+		uid := uuid.New()
+
+		mock.ExpectExec(useRecoveryCodeQuery).WithArgs(uid, "hash").WillReturnResult(sqlmock.NewResult(1, 0))
+
+		err := authRepo.UseRecoveryCode(context.Background(), uid, "hash")
+		require.NotNil(t, err)
+	})
+}
+
+func TestAuthRepo_UpdateLockedUntil(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+
+	t.Run("Lock", func(t *testing.T) {
+		uid := uuid.New()
+		lockedUntil := time.Now().Add(time.Minute)
+
+		mock.ExpectExec(updateLockedUntilQuery).WithArgs(&lockedUntil, uid).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := authRepo.UpdateLockedUntil(context.Background(), uid, &lockedUntil)
+		require.NoError(t, err)
+	})
+
+	t.Run("Unlock", func(t *testing.T) {
+		uid := uuid.New()
+
+		mock.ExpectExec(updateLockedUntilQuery).WithArgs(nil, uid).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := authRepo.UpdateLockedUntil(context.Background(), uid, nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestAuthRepo_CreateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+
+	t.Run("CreateAPIKey", func(t *testing.T) {
+		apiKey := &models.APIKey{
+			UserID:  uuid.New(),
+			Name:    "ci",
+			Prefix:  "amc_01020304",
+			KeyHash: "hash",
+			Scopes:  models.APIKeyScopes{models.APIKeyScopeNewsWrite, models.APIKeyScopeCommentsWrite},
+		}
+
+		rows := sqlmock.NewRows([]string{"api_key_id", "user_id", "name", "prefix", "key_hash", "scopes"}).AddRow(
+			uuid.New(), apiKey.UserID, apiKey.Name, apiKey.Prefix, apiKey.KeyHash, "news:write,comments:write")
+
+		mock.ExpectQuery(createAPIKeyQuery).WithArgs(apiKey.UserID, apiKey.Name, apiKey.Prefix, apiKey.KeyHash,
+			"news:write,comments:write", apiKey.ExpiresAt).WillReturnRows(rows)
+
+		createdKey, err := authRepo.CreateAPIKey(context.Background(), apiKey)
+		require.NoError(t, err)
+		require.NotNil(t, createdKey)
+		require.Equal(t, apiKey.Scopes, createdKey.Scopes)
+		require.Equal(t, apiKey.Name, createdKey.Name)
+	})
+}
+
+func TestAuthRepo_DeleteAPIKey(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+
+	t.Run("DeleteAPIKey", func(t *testing.T) {
+		uid := uuid.New()
+		keyID := uuid.New()
+
+		mock.ExpectExec(deleteAPIKeyQuery).WithArgs(uid, keyID).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := authRepo.DeleteAPIKey(context.Background(), uid, keyID)
+		require.NoError(t, err)
+	})
+
+	t.Run("DeleteAPIKey Not Found", func(t *testing.T) {
+		uid := uuid.New()
+		keyID := uuid.New()
+
+		mock.ExpectExec(deleteAPIKeyQuery).WithArgs(uid, keyID).WillReturnResult(sqlmock.NewResult(1, 0))
+
+		err := authRepo.DeleteAPIKey(context.Background(), uid, keyID)
+		require.NotNil(t, err)
+	})
+}
+
+func TestAuthRepo_GetPermissionsByRole(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+
+	rows := sqlmock.NewRows([]string{"permission"}).
+		AddRow(models.PermissionNewsModerate).
+		AddRow(models.PermissionNewsPublish)
+
+	mock.ExpectQuery(getPermissionsByRoleQuery).WithArgs("editor").WillReturnRows(rows)
+
+	permissions, err := authRepo.GetPermissionsByRole(context.Background(), "editor")
+	require.NoError(t, err)
+	require.Equal(t, []string{models.PermissionNewsModerate, models.PermissionNewsPublish}, permissions)
+}
+
+func TestAuthRepo_UpdateRole(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+
+	t.Run("UpdateRole", func(t *testing.T) {
+		uid := uuid.New()
+
+		mock.ExpectExec(updateRoleQuery).WithArgs("moderator", uid).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := authRepo.UpdateRole(context.Background(), uid, "moderator")
+		require.NoError(t, err)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		uid := uuid.New()
+
+		mock.ExpectExec(updateRoleQuery).WithArgs("moderator", uid).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := authRepo.UpdateRole(context.Background(), uid, "moderator")
+		require.Error(t, err)
+	})
+}
+
+func TestAuthRepo_ConfirmPendingEmail(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+
+	t.Run("Confirm", func(t *testing.T) {
+		uid := uuid.New()
+
+		mock.ExpectExec(confirmPendingEmailQuery).WithArgs(uid, "new@gmail.com").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := authRepo.ConfirmPendingEmail(context.Background(), uid, "new@gmail.com")
+		require.NoError(t, err)
+	})
+
+	t.Run("Pending email changed", func(t *testing.T) {
+		uid := uuid.New()
+
+		mock.ExpectExec(confirmPendingEmailQuery).WithArgs(uid, "new@gmail.com").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := authRepo.ConfirmPendingEmail(context.Background(), uid, "new@gmail.com")
+		require.Error(t, err)
+	})
+}
+
+func TestAuthRepo_UpdateStatus(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	authRepo := NewAuthRepository(sqlxDB)
+
+	reason := "spam"
+	until := time.Now().Add(time.Hour)
+	status := &models.UserStatus{Status: models.UserStatusSuspended, Reason: &reason, Until: &until}
+
+	t.Run("UpdateStatus", func(t *testing.T) {
+		uid := uuid.New()
+
+		mock.ExpectExec(updateStatusQuery).WithArgs(status.Status, status.Reason, status.Until, uid).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := authRepo.UpdateStatus(context.Background(), uid, status)
+		require.NoError(t, err)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		uid := uuid.New()
+
+		mock.ExpectExec(updateStatusQuery).WithArgs(status.Status, status.Reason, status.Until, uid).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := authRepo.UpdateStatus(context.Background(), uid, status)
+		require.Error(t, err)
 	})
 }
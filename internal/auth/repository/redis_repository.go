@@ -64,3 +64,328 @@ func (a *authRedisRepo) DeleteUserCtx(ctx context.Context, key string) error {
 	}
 	return nil
 }
+
+// Get refresh token by key
+func (a *authRedisRepo) GetRefreshTokenCtx(ctx context.Context, key string) (*models.RefreshToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.GetRefreshTokenCtx")
+	defer span.Finish()
+
+	tokenBytes, err := a.redisClient.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "authRedisRepo.GetRefreshTokenCtx.redisClient.Get")
+	}
+	token := &models.RefreshToken{}
+	if err = json.Unmarshal(tokenBytes, token); err != nil {
+		return nil, errors.Wrap(err, "authRedisRepo.GetRefreshTokenCtx.json.Unmarshal")
+	}
+	return token, nil
+}
+
+// Cache refresh token with duration in seconds
+func (a *authRedisRepo) SetRefreshTokenCtx(ctx context.Context, key string, seconds int, token *models.RefreshToken) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.SetRefreshTokenCtx")
+	defer span.Finish()
+
+	tokenBytes, err := json.Marshal(token)
+	if err != nil {
+		return errors.Wrap(err, "authRedisRepo.SetRefreshTokenCtx.json.Marshal")
+	}
+	if err = a.redisClient.Set(ctx, key, tokenBytes, time.Second*time.Duration(seconds)).Err(); err != nil {
+		return errors.Wrap(err, "authRedisRepo.SetRefreshTokenCtx.redisClient.Set")
+	}
+	return nil
+}
+
+// Mark refresh token as used in one optimistic transaction keeping its remaining lifetime,
+// returns false when the token was already used or concurrently changed by another request
+func (a *authRedisRepo) MarkRefreshTokenUsedCtx(ctx context.Context, key string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.MarkRefreshTokenUsedCtx")
+	defer span.Finish()
+
+	marked := false
+	err := a.redisClient.Watch(ctx, func(tx *redis.Tx) error {
+		tokenBytes, err := tx.Get(ctx, key).Bytes()
+		if err != nil {
+			return errors.Wrap(err, "tx.Get")
+		}
+		token := &models.RefreshToken{}
+		if err = json.Unmarshal(tokenBytes, token); err != nil {
+			return errors.Wrap(err, "json.Unmarshal")
+		}
+		if token.Used {
+			return nil
+		}
+
+		ttl, err := tx.TTL(ctx, key).Result()
+		if err != nil {
+			return errors.Wrap(err, "tx.TTL")
+		}
+		if ttl < 0 {
+			ttl = 0
+		}
+
+		token.Used = true
+		if tokenBytes, err = json.Marshal(token); err != nil {
+			return errors.Wrap(err, "json.Marshal")
+		}
+		if _, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, tokenBytes, ttl)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		marked = true
+		return nil
+	}, key)
+	if err == redis.TxFailedErr {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "authRedisRepo.MarkRefreshTokenUsedCtx.redisClient.Watch")
+	}
+	return marked, nil
+}
+
+// Mark refresh token family as active with duration in seconds
+func (a *authRedisRepo) SetTokenFamilyCtx(ctx context.Context, key string, seconds int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.SetTokenFamilyCtx")
+	defer span.Finish()
+
+	if err := a.redisClient.Set(ctx, key, time.Now().Unix(), time.Second*time.Duration(seconds)).Err(); err != nil {
+		return errors.Wrap(err, "authRedisRepo.SetTokenFamilyCtx.redisClient.Set")
+	}
+	return nil
+}
+
+// Check refresh token family is active
+func (a *authRedisRepo) ExistsTokenFamilyCtx(ctx context.Context, key string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.ExistsTokenFamilyCtx")
+	defer span.Finish()
+
+	count, err := a.redisClient.Exists(ctx, key).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "authRedisRepo.ExistsTokenFamilyCtx.redisClient.Exists")
+	}
+	return count > 0, nil
+}
+
+// Revoke refresh token family by key
+func (a *authRedisRepo) DeleteTokenFamilyCtx(ctx context.Context, key string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.DeleteTokenFamilyCtx")
+	defer span.Finish()
+
+	if err := a.redisClient.Del(ctx, key).Err(); err != nil {
+		return errors.Wrap(err, "authRedisRepo.DeleteTokenFamilyCtx.redisClient.Del")
+	}
+	return nil
+}
+
+// Add refresh token family to the user families index, index expires after duration in seconds since last added family
+func (a *authRedisRepo) AddUserTokenFamilyCtx(ctx context.Context, key string, familyID string, seconds int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.AddUserTokenFamilyCtx")
+	defer span.Finish()
+
+	pipe := a.redisClient.TxPipeline()
+	pipe.SAdd(ctx, key, familyID)
+	pipe.Expire(ctx, key, time.Second*time.Duration(seconds))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrap(err, "authRedisRepo.AddUserTokenFamilyCtx.pipe.Exec")
+	}
+	return nil
+}
+
+// Get refresh token families of the user
+func (a *authRedisRepo) GetUserTokenFamiliesCtx(ctx context.Context, key string) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.GetUserTokenFamiliesCtx")
+	defer span.Finish()
+
+	familyIDs, err := a.redisClient.SMembers(ctx, key).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "authRedisRepo.GetUserTokenFamiliesCtx.redisClient.SMembers")
+	}
+	return familyIDs, nil
+}
+
+// Remove refresh token families from the user families index
+func (a *authRedisRepo) RemoveUserTokenFamiliesCtx(ctx context.Context, key string, familyIDs []string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.RemoveUserTokenFamiliesCtx")
+	defer span.Finish()
+
+	if len(familyIDs) == 0 {
+		return nil
+	}
+	members := make([]interface{}, 0, len(familyIDs))
+	for _, familyID := range familyIDs {
+		members = append(members, familyID)
+	}
+	if err := a.redisClient.SRem(ctx, key, members...).Err(); err != nil {
+		return errors.Wrap(err, "authRedisRepo.RemoveUserTokenFamiliesCtx.redisClient.SRem")
+	}
+	return nil
+}
+
+// Cache single use user token with duration in seconds
+func (a *authRedisRepo) SetUserTokenCtx(ctx context.Context, key string, seconds int, token *models.UserToken) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.SetUserTokenCtx")
+	defer span.Finish()
+
+	tokenBytes, err := json.Marshal(token)
+	if err != nil {
+		return errors.Wrap(err, "authRedisRepo.SetUserTokenCtx.json.Marshal")
+	}
+	if err = a.redisClient.Set(ctx, key, tokenBytes, time.Second*time.Duration(seconds)).Err(); err != nil {
+		return errors.Wrap(err, "authRedisRepo.SetUserTokenCtx.redisClient.Set")
+	}
+	return nil
+}
+
+// Get and delete single use user token in one transaction
+func (a *authRedisRepo) ConsumeUserTokenCtx(ctx context.Context, key string) (*models.UserToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.ConsumeUserTokenCtx")
+	defer span.Finish()
+
+	pipe := a.redisClient.TxPipeline()
+	getCmd := pipe.Get(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, errors.Wrap(err, "authRedisRepo.ConsumeUserTokenCtx.pipe.Exec")
+	}
+
+	tokenBytes, err := getCmd.Bytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "authRedisRepo.ConsumeUserTokenCtx.getCmd.Bytes")
+	}
+	token := &models.UserToken{}
+	if err = json.Unmarshal(tokenBytes, token); err != nil {
+		return nil, errors.Wrap(err, "authRedisRepo.ConsumeUserTokenCtx.json.Unmarshal")
+	}
+	return token, nil
+}
+
+// Increment failed login attempts counter, counter expires after duration in seconds since last attempt
+func (a *authRedisRepo) IncrLoginAttemptsCtx(ctx context.Context, key string, seconds int) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.IncrLoginAttemptsCtx")
+	defer span.Finish()
+
+	pipe := a.redisClient.TxPipeline()
+	incrCmd := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, time.Second*time.Duration(seconds))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, errors.Wrap(err, "authRedisRepo.IncrLoginAttemptsCtx.pipe.Exec")
+	}
+	return incrCmd.Val(), nil
+}
+
+// Get failed login attempts counter with its remaining lifetime
+func (a *authRedisRepo) GetLoginAttemptsCtx(ctx context.Context, key string) (int64, time.Duration, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.GetLoginAttemptsCtx")
+	defer span.Finish()
+
+	pipe := a.redisClient.TxPipeline()
+	getCmd := pipe.Get(ctx, key)
+	ttlCmd := pipe.TTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, 0, errors.Wrap(err, "authRedisRepo.GetLoginAttemptsCtx.pipe.Exec")
+	}
+
+	attempts, err := getCmd.Int64()
+	if err == redis.Nil {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "authRedisRepo.GetLoginAttemptsCtx.getCmd.Int64")
+	}
+	return attempts, ttlCmd.Val(), nil
+}
+
+// Reset failed login attempts counter
+func (a *authRedisRepo) DeleteLoginAttemptsCtx(ctx context.Context, key string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.DeleteLoginAttemptsCtx")
+	defer span.Finish()
+
+	if err := a.redisClient.Del(ctx, key).Err(); err != nil {
+		return errors.Wrap(err, "authRedisRepo.DeleteLoginAttemptsCtx.redisClient.Del")
+	}
+	return nil
+}
+
+// Get cached user permissions
+func (a *authRedisRepo) GetPermissionsCtx(ctx context.Context, key string) (*models.UserPermissions, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.GetPermissionsCtx")
+	defer span.Finish()
+
+	permissionsBytes, err := a.redisClient.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "authRedisRepo.GetPermissionsCtx.redisClient.Get")
+	}
+	permissions := &models.UserPermissions{}
+	if err = json.Unmarshal(permissionsBytes, permissions); err != nil {
+		return nil, errors.Wrap(err, "authRedisRepo.GetPermissionsCtx.json.Unmarshal")
+	}
+	return permissions, nil
+}
+
+// Cache user permissions with duration in seconds
+func (a *authRedisRepo) SetPermissionsCtx(ctx context.Context, key string, seconds int, permissions *models.UserPermissions) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.SetPermissionsCtx")
+	defer span.Finish()
+
+	permissionsBytes, err := json.Marshal(permissions)
+	if err != nil {
+		return errors.Wrap(err, "authRedisRepo.SetPermissionsCtx.json.Marshal")
+	}
+	if err = a.redisClient.Set(ctx, key, permissionsBytes, time.Second*time.Duration(seconds)).Err(); err != nil {
+		return errors.Wrap(err, "authRedisRepo.SetPermissionsCtx.redisClient.Set")
+	}
+	return nil
+}
+
+// Delete cached user permissions
+func (a *authRedisRepo) DeletePermissionsCtx(ctx context.Context, key string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.DeletePermissionsCtx")
+	defer span.Finish()
+
+	if err := a.redisClient.Del(ctx, key).Err(); err != nil {
+		return errors.Wrap(err, "authRedisRepo.DeletePermissionsCtx.redisClient.Del")
+	}
+	return nil
+}
+
+// Store pending OpenID Connect authorization request with duration in seconds
+func (a *authRedisRepo) SetOIDCAuthRequestCtx(ctx context.Context, key string, seconds int, authRequest *models.OIDCAuthRequest) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.SetOIDCAuthRequestCtx")
+	defer span.Finish()
+
+	authRequestBytes, err := json.Marshal(authRequest)
+	if err != nil {
+		return errors.Wrap(err, "authRedisRepo.SetOIDCAuthRequestCtx.json.Marshal")
+	}
+	if err = a.redisClient.Set(ctx, key, authRequestBytes, time.Second*time.Duration(seconds)).Err(); err != nil {
+		return errors.Wrap(err, "authRedisRepo.SetOIDCAuthRequestCtx.redisClient.Set")
+	}
+	return nil
+}
+
+// Get and delete pending OpenID Connect authorization request atomically, so state can be used only once
+func (a *authRedisRepo) ConsumeOIDCAuthRequestCtx(ctx context.Context, key string) (*models.OIDCAuthRequest, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authRedisRepo.ConsumeOIDCAuthRequestCtx")
+	defer span.Finish()
+
+	pipe := a.redisClient.TxPipeline()
+	getCmd := pipe.Get(ctx, key)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, errors.Wrap(err, "authRedisRepo.ConsumeOIDCAuthRequestCtx.pipe.Exec")
+	}
+
+	authRequestBytes, err := getCmd.Bytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "authRedisRepo.ConsumeOIDCAuthRequestCtx.getCmd.Bytes")
+	}
+	authRequest := &models.OIDCAuthRequest{}
+	if err = json.Unmarshal(authRequestBytes, authRequest); err != nil {
+		return nil, errors.Wrap(err, "authRedisRepo.ConsumeOIDCAuthRequestCtx.json.Unmarshal")
+	}
+	return authRequest, nil
+}
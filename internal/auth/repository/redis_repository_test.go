@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis"
 	"github.com/go-redis/redis/v8"
@@ -84,3 +85,189 @@ func TestAuthRedisRepo_DeleteUserCtx(t *testing.T) {
 		require.Nil(t, err)
 	})
 }
+
+func TestAuthRedisRepo_RefreshTokenCtx(t *testing.T) {
+	t.Parallel()
+
+	authRedisRepo := SetupRedis()
+
+	t.Run("SetRefreshTokenCtx", func(t *testing.T) {
+		key := uuid.New().String()
+		token := &models.RefreshToken{
+			FamilyID: uuid.New(),
+			UserID:   uuid.New(),
+		}
+
+		err := authRedisRepo.SetRefreshTokenCtx(context.Background(), key, 10, token)
+		require.NoError(t, err)
+
+		storedToken, err := authRedisRepo.GetRefreshTokenCtx(context.Background(), key)
+		require.NoError(t, err)
+		require.NotNil(t, storedToken)
+		require.Equal(t, token.FamilyID, storedToken.FamilyID)
+		require.Equal(t, token.UserID, storedToken.UserID)
+		require.False(t, storedToken.Used)
+	})
+
+	t.Run("MarkRefreshTokenUsedCtx", func(t *testing.T) {
+		key := uuid.New().String()
+		token := &models.RefreshToken{
+			FamilyID: uuid.New(),
+			UserID:   uuid.New(),
+		}
+
+		err := authRedisRepo.SetRefreshTokenCtx(context.Background(), key, 10, token)
+		require.NoError(t, err)
+
+		marked, err := authRedisRepo.MarkRefreshTokenUsedCtx(context.Background(), key)
+		require.NoError(t, err)
+		require.True(t, marked)
+
+		storedToken, err := authRedisRepo.GetRefreshTokenCtx(context.Background(), key)
+		require.NoError(t, err)
+		require.True(t, storedToken.Used)
+
+		marked, err = authRedisRepo.MarkRefreshTokenUsedCtx(context.Background(), key)
+		require.NoError(t, err)
+		require.False(t, marked)
+	})
+}
+
+func TestAuthRedisRepo_TokenFamilyCtx(t *testing.T) {
+	t.Parallel()
+
+	authRedisRepo := SetupRedis()
+
+	t.Run("TokenFamilyCtx", func(t *testing.T) {
+		key := uuid.New().String()
+
+		err := authRedisRepo.SetTokenFamilyCtx(context.Background(), key, 10)
+		require.NoError(t, err)
+
+		exists, err := authRedisRepo.ExistsTokenFamilyCtx(context.Background(), key)
+		require.NoError(t, err)
+		require.True(t, exists)
+
+		err = authRedisRepo.DeleteTokenFamilyCtx(context.Background(), key)
+		require.NoError(t, err)
+
+		exists, err = authRedisRepo.ExistsTokenFamilyCtx(context.Background(), key)
+		require.NoError(t, err)
+		require.False(t, exists)
+	})
+}
+
+func TestAuthRedisRepo_UserTokenFamiliesCtx(t *testing.T) {
+	t.Parallel()
+
+	authRedisRepo := SetupRedis()
+
+	t.Run("UserTokenFamiliesCtx", func(t *testing.T) {
+		key := uuid.New().String()
+		firstFamilyID := uuid.New().String()
+		secondFamilyID := uuid.New().String()
+
+		err := authRedisRepo.AddUserTokenFamilyCtx(context.Background(), key, firstFamilyID, 10)
+		require.NoError(t, err)
+		err = authRedisRepo.AddUserTokenFamilyCtx(context.Background(), key, secondFamilyID, 10)
+		require.NoError(t, err)
+
+		familyIDs, err := authRedisRepo.GetUserTokenFamiliesCtx(context.Background(), key)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{firstFamilyID, secondFamilyID}, familyIDs)
+
+		err = authRedisRepo.RemoveUserTokenFamiliesCtx(context.Background(), key, []string{firstFamilyID})
+		require.NoError(t, err)
+
+		familyIDs, err = authRedisRepo.GetUserTokenFamiliesCtx(context.Background(), key)
+		require.NoError(t, err)
+		require.Equal(t, []string{secondFamilyID}, familyIDs)
+	})
+}
+
+func TestAuthRedisRepo_UserTokenCtx(t *testing.T) {
+	t.Parallel()
+
+	authRedisRepo := SetupRedis()
+
+	t.Run("UserTokenCtx", func(t *testing.T) {
+		key := uuid.New().String()
+		token := &models.UserToken{
+			UserID: uuid.New(),
+			Email:  "alex@gmail.com",
+		}
+
+		err := authRedisRepo.SetUserTokenCtx(context.Background(), key, 10, token)
+		require.NoError(t, err)
+
+		consumed, err := authRedisRepo.ConsumeUserTokenCtx(context.Background(), key)
+		require.NoError(t, err)
+		require.Equal(t, token, consumed)
+
+		consumed, err = authRedisRepo.ConsumeUserTokenCtx(context.Background(), key)
+		require.Error(t, err)
+		require.Nil(t, consumed)
+	})
+}
+
+func TestAuthRedisRepo_LoginAttemptsCtx(t *testing.T) {
+	t.Parallel()
+
+	authRedisRepo := SetupRedis()
+
+	t.Run("LoginAttemptsCtx", func(t *testing.T) {
+		key := uuid.New().String()
+
+		attempts, ttl, err := authRedisRepo.GetLoginAttemptsCtx(context.Background(), key)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), attempts)
+		require.Equal(t, time.Duration(0), ttl)
+
+		attempts, err = authRedisRepo.IncrLoginAttemptsCtx(context.Background(), key, 10)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), attempts)
+
+		attempts, err = authRedisRepo.IncrLoginAttemptsCtx(context.Background(), key, 10)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), attempts)
+
+		attempts, ttl, err = authRedisRepo.GetLoginAttemptsCtx(context.Background(), key)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), attempts)
+		require.True(t, ttl > 0)
+
+		err = authRedisRepo.DeleteLoginAttemptsCtx(context.Background(), key)
+		require.NoError(t, err)
+
+		attempts, _, err = authRedisRepo.GetLoginAttemptsCtx(context.Background(), key)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), attempts)
+	})
+}
+
+func TestAuthRedisRepo_PermissionsCtx(t *testing.T) {
+	t.Parallel()
+
+	authRedisRepo := SetupRedis()
+
+	t.Run("PermissionsCtx", func(t *testing.T) {
+		key := uuid.New().String()
+		permissions := &models.UserPermissions{
+			Role:        "editor",
+			Permissions: []string{models.PermissionNewsModerate, models.PermissionNewsPublish},
+		}
+
+		err := authRedisRepo.SetPermissionsCtx(context.Background(), key, 10, permissions)
+		require.NoError(t, err)
+
+		cached, err := authRedisRepo.GetPermissionsCtx(context.Background(), key)
+		require.NoError(t, err)
+		require.Equal(t, permissions, cached)
+
+		err = authRedisRepo.DeletePermissionsCtx(context.Background(), key)
+		require.NoError(t, err)
+
+		_, err = authRedisRepo.GetPermissionsCtx(context.Background(), key)
+		require.Error(t, err)
+	})
+}
@@ -3,54 +3,176 @@ package repository
 const (
 	createUserQuery = `INSERT INTO users (first_name, last_name, email, password, role, about, avatar, phone_number, address,
 	               		city, gender, postcode, birthday, created_at, updated_at, login_date)
-						VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'user'), $6, $7, $8, $9, $10, $11, $12, $13, now(), now(), now()) 
+						VALUES ($1, $2, $3, $4, 'user', $5, $6, $7, $8, $9, $10, $11, $12, now(), now(), now()) 
 						RETURNING *`
 
 	updateUserQuery = `UPDATE users 
 						SET first_name = COALESCE(NULLIF($1, ''), first_name),
 						    last_name = COALESCE(NULLIF($2, ''), last_name),
 						    email = COALESCE(NULLIF($3, ''), email),
-						    role = COALESCE(NULLIF($4, ''), role),
-						    about = COALESCE(NULLIF($5, ''), about),
-						    avatar = COALESCE(NULLIF($6, ''), avatar),
-						    phone_number = COALESCE(NULLIF($7, ''), phone_number),
-						    address = COALESCE(NULLIF($8, ''), address),
-						    city = COALESCE(NULLIF($9, ''), city),
-						    gender = COALESCE(NULLIF($10, ''), gender),
-						    postcode = COALESCE(NULLIF($11, 0), postcode),
-						    birthday = COALESCE(NULLIF($12, '')::date, birthday),
+						    about = COALESCE(NULLIF($4, ''), about),
+						    avatar = COALESCE(NULLIF($5, ''), avatar),
+						    phone_number = COALESCE(NULLIF($6, ''), phone_number),
+						    address = COALESCE(NULLIF($7, ''), address),
+						    city = COALESCE(NULLIF($8, ''), city),
+						    gender = COALESCE(NULLIF($9, ''), gender),
+						    postcode = COALESCE(NULLIF($10, 0), postcode),
+						    birthday = COALESCE(NULLIF($11, '')::date, birthday),
 						    updated_at = now()
-						WHERE user_id = $13
+						WHERE user_id = $12
 						RETURNING *
 						`
 
-	deleteUserQuery = `DELETE FROM users WHERE user_id = $1`
+	updatePasswordQuery = `UPDATE users SET password = $1, updated_at = now() WHERE user_id = $2`
+
+	verifyEmailQuery = `UPDATE users SET verified_at = now(), updated_at = now() 
+						WHERE user_id = $1 AND email = $2 AND verified_at IS NULL`
+
+	setPendingEmailQuery = `UPDATE users SET pending_email = $1, updated_at = now() WHERE user_id = $2`
+
+	confirmPendingEmailQuery = `UPDATE users SET email = pending_email, pending_email = NULL, verified_at = now(), updated_at = now()
+						WHERE user_id = $1 AND pending_email = $2`
+
+	revertEmailQuery = `UPDATE users SET email = $1, pending_email = NULL, verified_at = now(), updated_at = now()
+						WHERE user_id = $2`
+
+	updateLockedUntilQuery = `UPDATE users SET locked_until = $1 WHERE user_id = $2`
+
+	updateStatusQuery = `UPDATE users SET status = $1, status_reason = $2, status_until = $3, updated_at = now() WHERE user_id = $4`
+
+	deleteUserQuery = `UPDATE users SET deleted_at = now(), updated_at = now() WHERE user_id = $1 AND deleted_at IS NULL`
+
+	restoreUserQuery = `UPDATE users SET deleted_at = NULL, updated_at = now() 
+						WHERE user_id = $1 AND deleted_at > $2 AND purged_at IS NULL`
+
+	anonymizeDeletedUsersQuery = `WITH purged AS (
+							UPDATE users 
+							SET first_name = 'Deleted', last_name = 'User', email = 'deleted-' || user_id || '@invalid', password = '!', 
+							    about = '', avatar = NULL, phone_number = NULL, address = NULL, city = NULL, country = NULL, 
+							    postcode = NULL, birthday = NULL, pending_email = NULL, two_factor_enabled = false, status_reason = NULL, 
+							    purged_at = now(), updated_at = now()
+							WHERE user_id IN (SELECT user_id FROM users 
+							                  WHERE deleted_at < $1 AND purged_at IS NULL 
+							                  ORDER BY deleted_at LIMIT $2 FOR UPDATE SKIP LOCKED)
+							RETURNING user_id
+						), totp AS (
+							DELETE FROM user_totp WHERE user_id IN (SELECT user_id FROM purged)
+						), recovery_codes AS (
+							DELETE FROM user_recovery_codes WHERE user_id IN (SELECT user_id FROM purged)
+						), api_keys AS (
+							DELETE FROM api_keys WHERE user_id IN (SELECT user_id FROM purged)
+						), identities AS (
+							DELETE FROM user_identities WHERE user_id IN (SELECT user_id FROM purged)
+						)
+						SELECT COUNT(*) FROM purged`
+
+	hardDeleteDeletedUsersQuery = `DELETE FROM users 
+						WHERE user_id IN (SELECT user_id FROM users u 
+						                  WHERE u.deleted_at < $1 AND NOT EXISTS (SELECT 1 FROM news WHERE author_id = u.user_id) 
+						                  ORDER BY u.deleted_at LIMIT $2 FOR UPDATE SKIP LOCKED)`
 
 	getUserQuery = `SELECT user_id, first_name, last_name, email, role, about, avatar, phone_number, 
-       				 address, city, gender, postcode, birthday, created_at, updated_at, login_date  
+       				 address, city, gender, postcode, birthday, created_at, updated_at, login_date, verified_at, two_factor_enabled, locked_until,
+       				 pending_email, status, status_reason, status_until
 					 FROM users 
-					 WHERE user_id = $1`
+					 WHERE user_id = $1 AND deleted_at IS NULL`
+
+	setWordSimilarityThreshold = `SELECT set_config('pg_trgm.word_similarity_threshold', $1::text, true)`
 
 	getTotalCount = `SELECT COUNT(user_id) FROM users 
-						WHERE first_name ILIKE '%' || $1 || '%' or last_name ILIKE '%' || $1 || '%'`
+						WHERE $1 <% (first_name || ' ' || last_name) AND deleted_at IS NULL`
 
-	findUsers = `SELECT user_id, first_name, last_name, email, role, about, avatar, phone_number, address,
-	              city, gender, postcode, birthday, created_at, updated_at, login_date 
+	findUsers = `SELECT user_id, first_name, last_name, role, about, avatar, phone_number, address,
+	              city, gender, postcode, birthday, created_at, updated_at, login_date, verified_at, two_factor_enabled
 				  FROM users 
-				  WHERE first_name ILIKE '%' || $1 || '%' or last_name ILIKE '%' || $1 || '%'
-				  ORDER BY first_name, last_name
+				  WHERE $1 <% (first_name || ' ' || last_name) AND deleted_at IS NULL
+				  ORDER BY word_similarity($1, first_name || ' ' || last_name) DESC, first_name, last_name
 				  OFFSET $2 LIMIT $3
 				  `
 
-	getTotal = `SELECT COUNT(user_id) FROM users`
+	findUserSuggestions = `SELECT user_id, first_name, last_name, avatar
+				  FROM users 
+				  WHERE ($1 <% (first_name || ' ' || last_name) OR $1 <% email) AND deleted_at IS NULL
+				  ORDER BY GREATEST(word_similarity($1, first_name || ' ' || last_name), word_similarity($1, email)) DESC,
+				           first_name, last_name
+				  LIMIT $2`
+
+	getTotal = `SELECT COUNT(user_id) FROM users WHERE deleted_at IS NULL`
 
 	getUsers = `SELECT user_id, first_name, last_name, email, role, about, avatar, phone_number, 
-       			 address, city, gender, postcode, birthday, created_at, updated_at, login_date
+       			 address, city, gender, postcode, birthday, created_at, updated_at, login_date, verified_at, two_factor_enabled
 				 FROM users 
+				 WHERE deleted_at IS NULL
 				 ORDER BY COALESCE(NULLIF($1, ''), first_name) OFFSET $2 LIMIT $3`
 
 	findUserByEmail = `SELECT user_id, first_name, last_name, email, role, about, avatar, phone_number, 
-       			 		address, city, gender, postcode, birthday, created_at, updated_at, login_date, password
+       			 		address, city, gender, postcode, birthday, created_at, updated_at, login_date, verified_at, two_factor_enabled, locked_until, password,
+       			 		status, status_reason, status_until
 				 		FROM users 
-				 		WHERE email = $1`
+				 		WHERE email = $1 AND deleted_at IS NULL`
+
+	upsertTOTPQuery = `INSERT INTO user_totp (user_id, secret, last_used_step, confirmed_at, created_at)
+						VALUES ($1, $2, 0, NULL, now())
+						ON CONFLICT (user_id) DO UPDATE 
+						SET secret = EXCLUDED.secret, last_used_step = 0, created_at = now()
+						WHERE user_totp.confirmed_at IS NULL`
+
+	getTOTPQuery = `SELECT user_id, secret, last_used_step, confirmed_at, created_at FROM user_totp WHERE user_id = $1`
+
+	confirmTOTPQuery = `UPDATE user_totp SET confirmed_at = now() WHERE user_id = $1 AND confirmed_at IS NULL`
+
+	enableTwoFactorQuery = `UPDATE users SET two_factor_enabled = $1, updated_at = now() WHERE user_id = $2`
+
+	deleteTOTPQuery = `DELETE FROM user_totp WHERE user_id = $1`
+
+	updateTOTPStepQuery = `UPDATE user_totp SET last_used_step = $1 WHERE user_id = $2 AND last_used_step < $1`
+
+	deleteRecoveryCodesQuery = `DELETE FROM user_recovery_codes WHERE user_id = $1`
+
+	createRecoveryCodeQuery = `INSERT INTO user_recovery_codes (code_hash, user_id, created_at) VALUES ($1, $2, now())`
+
+	useRecoveryCodeQuery = `UPDATE user_recovery_codes SET used_at = now() 
+							WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+
+	createAPIKeyQuery = `INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at, created_at)
+						VALUES ($1, $2, $3, $4, $5, $6, now())
+						RETURNING api_key_id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at`
+
+	getAPIKeysByUserIDQuery = `SELECT api_key_id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+								FROM api_keys 
+								WHERE user_id = $1
+								ORDER BY created_at DESC`
+
+	getAPIKeyByIDQuery = `SELECT api_key_id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+							FROM api_keys 
+							WHERE user_id = $1 AND api_key_id = $2`
+
+	getAPIKeyByHashQuery = `SELECT api_key_id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+							FROM api_keys 
+							WHERE key_hash = $1`
+
+	updateAPIKeyQuery = `UPDATE api_keys 
+						SET name = COALESCE(NULLIF($1, ''), name),
+						    scopes = COALESCE(NULLIF($2, ''), scopes)
+						WHERE user_id = $3 AND api_key_id = $4
+						RETURNING api_key_id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at`
+
+	deleteAPIKeyQuery = `DELETE FROM api_keys WHERE user_id = $1 AND api_key_id = $2`
+
+	updateAPIKeyLastUsedQuery = `UPDATE api_keys SET last_used_at = $1 WHERE api_key_id = $2`
+
+	getRolesQuery = `SELECT name, description FROM roles ORDER BY name`
+
+	getRolePermissionsQuery = `SELECT role, permission FROM role_permissions ORDER BY role, permission`
+
+	getPermissionsByRoleQuery = `SELECT permission FROM role_permissions WHERE role = $1 ORDER BY permission`
+
+	updateRoleQuery = `UPDATE users SET role = $1, updated_at = now() WHERE user_id = $2`
+
+	getUserIdentityQuery = `SELECT identity_id, user_id, provider, subject, email, created_at
+						FROM user_identities WHERE provider = $1 AND subject = $2`
+
+	createUserIdentityQuery = `INSERT INTO user_identities (user_id, provider, subject, email, created_at)
+						VALUES ($1, $2, $3, $4, now())
+						RETURNING identity_id, user_id, provider, subject, email, created_at`
 )
@@ -7,17 +7,51 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/jwtkeys"
 	"github.com/AleksK1NG/api-mc/pkg/utils"
 )
 
 // Auth repository interface
 type UseCase interface {
 	Register(ctx context.Context, user *models.User) (*models.UserWithToken, error)
-	Login(ctx context.Context, user *models.User) (*models.UserWithToken, error)
+	Login(ctx context.Context, user *models.User, ipAddress string) (*models.UserWithToken, error)
 	Update(ctx context.Context, user *models.User) (*models.User, error)
 	Delete(ctx context.Context, userID uuid.UUID) error
+	Restore(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	PurgeDeletedUsers(ctx context.Context) (int64, error)
 	GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error)
 	FindByName(ctx context.Context, name string, query *utils.PaginationQuery) (*models.UsersList, error)
+	FindSuggestions(ctx context.Context, name string, limit int) ([]*models.UserSuggestion, error)
 	GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*models.UsersList, error)
 	UploadAvatar(ctx context.Context, userID uuid.UUID, file models.UploadInput) (*models.User, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (*models.UserWithToken, error)
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, resetToken string, password string) error
+	ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword string, newPassword string, currentSessionID string, currentRefreshToken string) error
+	VerifyEmail(ctx context.Context, verifyToken string) error
+	ResendVerification(ctx context.Context, email string) error
+	ChangeEmail(ctx context.Context, userID uuid.UUID, email string, password string) error
+	ConfirmEmailChange(ctx context.Context, changeToken string) error
+	RevertEmailChange(ctx context.Context, revertToken string) error
+	LoginTwoFactor(ctx context.Context, challenge string, code string) (*models.UserWithToken, error)
+	EnrollTOTP(ctx context.Context, user *models.User) (*models.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) (*models.RecoveryCodes, error)
+	DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error
+	Unlock(ctx context.Context, userID uuid.UUID) error
+	UpdateStatus(ctx context.Context, userID uuid.UUID, status *models.UserStatus) error
+	Impersonate(ctx context.Context, adminID uuid.UUID, userID uuid.UUID) (*models.User, error)
+	CreateAPIKey(ctx context.Context, apiKey *models.APIKey) (*models.APIKeyWithSecret, error)
+	GetAPIKeys(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error)
+	GetAPIKeyByID(ctx context.Context, userID uuid.UUID, apiKeyID uuid.UUID) (*models.APIKey, error)
+	UpdateAPIKey(ctx context.Context, apiKey *models.APIKey) (*models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, userID uuid.UUID, apiKeyID uuid.UUID) error
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.User, *models.APIKey, error)
+	GetJWKS(ctx context.Context) *jwtkeys.JWKS
+	GetPermissions(ctx context.Context, user *models.User) (*models.UserPermissions, error)
+	GetRoles(ctx context.Context) ([]*models.Role, error)
+	UpdateRole(ctx context.Context, userID uuid.UUID, role string) error
+	SendMagicLink(ctx context.Context, email string, ipAddress string) error
+	LoginMagicLink(ctx context.Context, loginToken string) (*models.UserWithToken, error)
+	OIDCAuthURL(ctx context.Context, provider string) (string, string, error)
+	OIDCLogin(ctx context.Context, provider string, state string, code string) (*models.UserWithToken, error)
 }
@@ -2,24 +2,72 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 
 	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/audit"
 	"github.com/AleksK1NG/api-mc/internal/auth"
 	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/session"
 	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/jwtkeys"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/mailer"
+	"github.com/AleksK1NG/api-mc/pkg/oidc"
+	"github.com/AleksK1NG/api-mc/pkg/passwordhash"
+	"github.com/AleksK1NG/api-mc/pkg/passwordpolicy"
+	"github.com/AleksK1NG/api-mc/pkg/totp"
 	"github.com/AleksK1NG/api-mc/pkg/utils"
 )
 
 const (
-	basePrefix    = "api-auth:"
-	cacheDuration = 3600
+	basePrefix           = "api-auth:"
+	refreshTokenPrefix   = "api-refresh-token:"
+	tokenFamilyPrefix    = "api-token-family:"
+	userFamiliesPrefix   = "api-user-token-families:"
+	passwordResetPrefix  = "api-password-reset:"
+	emailVerifyPrefix    = "api-email-verify:"
+	challengePrefix      = "api-2fa-challenge:"
+	emailAttemptsPrefix  = "api-login-attempts-email:"
+	ipAttemptsPrefix     = "api-login-attempts-ip:"
+	permissionsPrefix    = "api-auth-permissions:"
+	oidcStatePrefix      = "api-oidc-state:"
+	magicLinkPrefix      = "api-magic-link:"
+	emailChangePrefix    = "api-email-change:"
+	emailRevertPrefix    = "api-email-revert:"
+	magicLinkEmailPrefix = "api-magic-link-requests-email:"
+	magicLinkIPPrefix    = "api-magic-link-requests-ip:"
+	cacheDuration        = 3600
+	refreshTokenSize     = 32
+	userTokenSize        = 32
+	recoveryCodesCount   = 10
+	recoveryCodeSize     = 5
+	apiKeyPrefixSize     = 4
+	apiKeySecretSize     = 32
+	// Users first and last name validation length
+	maxNameLength = 30
+	// Minimal interval between API key last used updates
+	apiKeyLastUsedInterval = time.Minute
+	// Deleted users purge mode with hard deletion, other modes anonymize
+	purgeModeDelete       = "delete"
+	defaultPurgeBatchSize = 100
+	// Login attempts limits used when lockout is not configured
+	defaultLoginMaxAttempts   = 5
+	defaultLoginIPMaxAttempts = 100
+	// Users fuzzy search word similarity threshold, lower than pg_trgm default to tolerate typos
+	defaultSearchSimilarity = 0.3
+	defaultSuggestionsLimit = 10
+	maxSuggestionsLimit     = 20
 )
 
 // Auth UseCase
@@ -28,12 +76,44 @@ type authUC struct {
 	authRepo  auth.Repository
 	redisRepo auth.RedisRepository
 	awsRepo   auth.AWSRepository
+	sessRepo  session.SessRepository
+	mailer    mailer.Mailer
+	jwtKeys   *jwtkeys.KeySet
+	auditUC   audit.UseCase
 	logger    logger.Logger
+	// OpenID Connect providers by name
+	oidcProviders  map[string]*oidc.Provider
+	passwordPolicy *passwordpolicy.Policy
+	passwordHasher passwordhash.Hasher
 }
 
 // Auth UseCase constructor
-func NewAuthUseCase(cfg *config.Config, authRepo auth.Repository, redisRepo auth.RedisRepository, awsRepo auth.AWSRepository, log logger.Logger) auth.UseCase {
-	return &authUC{cfg: cfg, authRepo: authRepo, redisRepo: redisRepo, awsRepo: awsRepo, logger: log}
+func NewAuthUseCase(
+	cfg *config.Config,
+	authRepo auth.Repository,
+	redisRepo auth.RedisRepository,
+	awsRepo auth.AWSRepository,
+	sessRepo session.SessRepository,
+	mailSender mailer.Mailer,
+	jwtKeys *jwtkeys.KeySet,
+	passwordHasher passwordhash.Hasher,
+	auditUC audit.UseCase,
+	log logger.Logger,
+) auth.UseCase {
+	return &authUC{
+		cfg:            cfg,
+		authRepo:       authRepo,
+		redisRepo:      redisRepo,
+		awsRepo:        awsRepo,
+		sessRepo:       sessRepo,
+		mailer:         mailSender,
+		jwtKeys:        jwtKeys,
+		auditUC:        auditUC,
+		logger:         log,
+		oidcProviders:  oidc.NewProviders(cfg),
+		passwordPolicy: passwordpolicy.New(cfg.Password),
+		passwordHasher: passwordHasher,
+	}
 }
 
 // Create new user
@@ -46,7 +126,11 @@ func (u *authUC) Register(ctx context.Context, user *models.User) (*models.UserW
 		return nil, httpErrors.NewRestErrorWithMessage(http.StatusBadRequest, httpErrors.ErrEmailAlreadyExists, nil)
 	}
 
-	if err = user.PrepareCreate(); err != nil {
+	if err = u.validatePassword(strings.TrimSpace(user.Password)); err != nil {
+		return nil, err
+	}
+
+	if err = user.PrepareCreate(u.passwordHasher); err != nil {
 		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "authUC.Register.PrepareCreate"))
 	}
 
@@ -56,18 +140,28 @@ func (u *authUC) Register(ctx context.Context, user *models.User) (*models.UserW
 	}
 	createdUser.SanitizePassword()
 
-	token, err := utils.GenerateJWTToken(createdUser, u.cfg)
+	token, err := utils.GenerateJWTToken(createdUser, u.cfg, u.jwtKeys)
 	if err != nil {
 		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.Register.GenerateJWTToken"))
 	}
 
+	refreshToken, err := u.issueRefreshToken(ctx, createdUser.UserID, uuid.New())
+	if err != nil {
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.Register.issueRefreshToken"))
+	}
+
+	if err = u.sendVerificationEmail(ctx, createdUser); err != nil {
+		u.logger.Errorf("authUC.Register.sendVerificationEmail: %v", err)
+	}
+
 	return &models.UserWithToken{
-		User:  createdUser,
-		Token: token,
+		User:         createdUser,
+		Token:        token,
+		RefreshToken: refreshToken,
 	}, nil
 }
 
-// Update existing user
+// Update existing user, profile fields are writable by account owner, other fields require permission
 func (u *authUC) Update(ctx context.Context, user *models.User) (*models.User, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.Update")
 	defer span.Finish()
@@ -76,6 +170,25 @@ func (u *authUC) Update(ctx context.Context, user *models.User) (*models.User, e
 		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "authUC.Register.PrepareUpdate"))
 	}
 
+	if err := checkUpdatePermissions(ctx, user); err != nil {
+		return nil, err
+	}
+
+	currentUser, err := u.authRepo.GetByID(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Email != "" && currentUser.Email != user.Email {
+		return nil, httpErrors.NewRestError(http.StatusBadRequest, httpErrors.EmailChangeNotAllowed.Error(), nil)
+	}
+
+	// Role is not written by user update, it is assigned the same way as on role endpoint
+	if user.Role != nil && (currentUser.Role == nil || *currentUser.Role != *user.Role) {
+		if err = u.UpdateRole(ctx, user.UserID, *user.Role); err != nil {
+			return nil, err
+		}
+	}
+
 	updatedUser, err := u.authRepo.Update(ctx, user)
 	if err != nil {
 		return nil, err
@@ -87,12 +200,18 @@ func (u *authUC) Update(ctx context.Context, user *models.User) (*models.User, e
 		u.logger.Errorf("AuthUC.Update.DeleteUserCtx: %s", err)
 	}
 
-	updatedUser.SanitizePassword()
+	u.recordAuditEvent(ctx, &models.AuditEvent{
+		Action:     models.AuditActionUpdate,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.UserID.String(),
+		Before:     currentUser,
+		After:      updatedUser,
+	})
 
 	return updatedUser, nil
 }
 
-// Delete new user
+// Soft delete user and revoke all user sessions, user can be restored during grace period
 func (u *authUC) Delete(ctx context.Context, userID uuid.UUID) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.Delete")
 	defer span.Finish()
@@ -101,13 +220,73 @@ func (u *authUC) Delete(ctx context.Context, userID uuid.UUID) error {
 		return err
 	}
 
+	if err := u.sessRepo.DeleteAllByUserID(ctx, userID); err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.Delete.DeleteAllByUserID"))
+	}
+
 	if err := u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(userID.String())); err != nil {
 		u.logger.Errorf("AuthUC.Delete.DeleteUserCtx: %s", err)
 	}
+	if err := u.redisRepo.DeletePermissionsCtx(ctx, u.generatePermissionsKey(userID.String())); err != nil {
+		u.logger.Errorf("AuthUC.Delete.DeletePermissionsCtx: %s", err)
+	}
+
+	u.recordAuditEvent(ctx, &models.AuditEvent{
+		Action:     models.AuditActionDelete,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID.String(),
+	})
 
 	return nil
 }
 
+// Restore deleted user during grace period
+func (u *authUC) Restore(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.Restore")
+	defer span.Finish()
+
+	if err := u.authRepo.Restore(ctx, userID, time.Now().Add(-u.deletionGracePeriod())); err != nil {
+		return nil, err
+	}
+
+	u.recordAuditEvent(ctx, &models.AuditEvent{
+		Action:     models.AuditActionRestore,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID.String(),
+	})
+
+	return u.GetByID(ctx, userID)
+}
+
+// Purge one batch of users deleted longer than grace period ago, returns number of purged users
+func (u *authUC) PurgeDeletedUsers(ctx context.Context) (int64, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.PurgeDeletedUsers")
+	defer span.Finish()
+
+	deletedBefore := time.Now().Add(-u.deletionGracePeriod())
+	batchSize := u.cfg.Deletion.PurgeBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultPurgeBatchSize
+	}
+
+	var purged int64
+	if u.cfg.Deletion.PurgeMode == purgeModeDelete {
+		deleted, err := u.authRepo.HardDeleteDeletedUsers(ctx, deletedBefore, batchSize)
+		if err != nil {
+			return 0, err
+		}
+		purged += deleted
+	}
+
+	// News authors can not be hard deleted without their news, they are anonymized in any mode
+	anonymized, err := u.authRepo.AnonymizeDeletedUsers(ctx, deletedBefore, batchSize)
+	if err != nil {
+		return purged, err
+	}
+
+	return purged + anonymized, nil
+}
+
 // Get user by id
 func (u *authUC) GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.GetByID")
@@ -140,7 +319,22 @@ func (u *authUC) FindByName(ctx context.Context, name string, query *utils.Pagin
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.FindByName")
 	defer span.Finish()
 
-	return u.authRepo.FindByName(ctx, name, query)
+	return u.authRepo.FindByName(ctx, name, u.searchSimilarity(), query)
+}
+
+// Find user suggestions for typeahead by name or email
+func (u *authUC) FindSuggestions(ctx context.Context, name string, limit int) ([]*models.UserSuggestion, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.FindSuggestions")
+	defer span.Finish()
+
+	if limit <= 0 {
+		limit = defaultSuggestionsLimit
+	}
+	if limit > maxSuggestionsLimit {
+		limit = maxSuggestionsLimit
+	}
+
+	return u.authRepo.FindSuggestions(ctx, name, u.searchSimilarity(), limit)
 }
 
 // Get users with pagination
@@ -151,62 +345,1500 @@ func (u *authUC) GetUsers(ctx context.Context, pq *utils.PaginationQuery) (*mode
 	return u.authRepo.GetUsers(ctx, pq)
 }
 
-// Login user, returns user model with jwt token
-func (u *authUC) Login(ctx context.Context, user *models.User) (*models.UserWithToken, error) {
+// Login user, returns user model with jwt token.
+// Failed attempts are counted by email and client ip, exceeding the limits locks login with exponential back-off.
+func (u *authUC) Login(ctx context.Context, user *models.User, ipAddress string) (*models.UserWithToken, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.Login")
 	defer span.Finish()
 
+	ipAttempts, ttl, err := u.redisRepo.GetLoginAttemptsCtx(ctx, u.generateLoginAttemptsKey(ipAttemptsPrefix, ipAddress))
+	if err != nil {
+		u.logger.Errorf("authUC.Login.GetLoginAttemptsCtx: %v", err)
+	}
+	if ipAttempts >= int64(u.loginIPMaxAttempts()) {
+		return nil, httpErrors.NewLockoutError(ttl)
+	}
+
 	foundUser, err := u.authRepo.FindByEmail(ctx, user)
 	if err != nil {
+		u.registerFailedLogin(ctx, nil, ipAddress)
+		u.recordAuditEvent(ctx, &models.AuditEvent{
+			Action:     models.AuditActionLoginFailed,
+			TargetType: models.AuditTargetUser,
+			IPAddress:  ipAddress,
+			Metadata:   map[string]interface{}{"email": user.Email},
+		})
 		return nil, err
 	}
 
-	if err = foundUser.ComparePasswords(user.Password); err != nil {
+	if foundUser.IsLocked() {
+		return nil, httpErrors.NewLockoutError(time.Until(*foundUser.LockedUntil))
+	}
+
+	if err = foundUser.ComparePasswords(u.passwordHasher, user.Password); err != nil {
+		u.recordAuditEvent(ctx, &models.AuditEvent{
+			Action:     models.AuditActionLoginFailed,
+			TargetType: models.AuditTargetUser,
+			TargetID:   foundUser.UserID.String(),
+			IPAddress:  ipAddress,
+			Metadata:   map[string]interface{}{"email": foundUser.Email},
+		})
+		if lockedUntil := u.registerFailedLogin(ctx, foundUser, ipAddress); lockedUntil != nil {
+			return nil, httpErrors.NewLockoutError(time.Until(*lockedUntil))
+		}
 		return nil, httpErrors.NewUnauthorizedError(errors.Wrap(err, "authUC.GetUsers.ComparePasswords"))
 	}
 
-	foundUser.SanitizePassword()
+	if err = u.redisRepo.DeleteLoginAttemptsCtx(ctx, u.generateLoginAttemptsKey(emailAttemptsPrefix, foundUser.Email)); err != nil {
+		u.logger.Errorf("authUC.Login.DeleteLoginAttemptsCtx: %v", err)
+	}
+
+	if u.passwordHasher.NeedsRehash(foundUser.Password) {
+		u.rehashPassword(ctx, foundUser.UserID, user.Password)
+	}
+
+	return u.completeLogin(ctx, foundUser)
+}
+
+// Rotate refresh token, returns user model with new jwt and refresh tokens.
+// Presenting an already used refresh token revokes the whole token family.
+func (u *authUC) RefreshTokens(ctx context.Context, refreshToken string) (*models.UserWithToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.RefreshTokens")
+	defer span.Finish()
+
+	tokenKey := u.generateRefreshTokenKey(utils.HashToken(refreshToken))
+	storedToken, err := u.redisRepo.GetRefreshTokenCtx(ctx, tokenKey)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.Wrap(err, "authUC.RefreshTokens.GetRefreshTokenCtx"))
+	}
+
+	familyKey := u.generateTokenFamilyKey(storedToken.FamilyID.String())
+	if storedToken.Used {
+		return nil, u.revokeReusedTokenFamily(ctx, storedToken)
+	}
+
+	exists, err := u.redisRepo.ExistsTokenFamilyCtx(ctx, familyKey)
+	if err != nil {
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.RefreshTokens.ExistsTokenFamilyCtx"))
+	}
+	if !exists {
+		return nil, httpErrors.NewUnauthorizedError(httpErrors.InvalidRefreshToken)
+	}
+
+	// Only the request that flips the used flag may rotate, a concurrent request with the same token is a reuse
+	marked, err := u.redisRepo.MarkRefreshTokenUsedCtx(ctx, tokenKey)
+	if err != nil {
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.RefreshTokens.MarkRefreshTokenUsedCtx"))
+	}
+	if !marked {
+		return nil, u.revokeReusedTokenFamily(ctx, storedToken)
+	}
+
+	user, err := u.GetByID(ctx, storedToken.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	token, err := utils.GenerateJWTToken(user, u.cfg, u.jwtKeys)
+	if err != nil {
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.RefreshTokens.GenerateJWTToken"))
+	}
 
-	token, err := utils.GenerateJWTToken(foundUser, u.cfg)
+	newRefreshToken, err := u.issueRefreshToken(ctx, user.UserID, storedToken.FamilyID)
 	if err != nil {
-		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.GetUsers.GenerateJWTToken"))
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.RefreshTokens.issueRefreshToken"))
 	}
 
 	return &models.UserWithToken{
-		User:  foundUser,
-		Token: token,
+		User:         user,
+		Token:        token,
+		RefreshToken: newRefreshToken,
 	}, nil
 }
 
-// Upload user avatar
-func (u *authUC) UploadAvatar(ctx context.Context, userID uuid.UUID, file models.UploadInput) (*models.User, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.UploadAvatar")
+// Send password reset link to the user email.
+// Unknown emails are not reported to the caller to prevent user enumeration.
+func (u *authUC) ForgotPassword(ctx context.Context, email string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.ForgotPassword")
 	defer span.Finish()
 
-	uploadInfo, err := u.awsRepo.PutObject(ctx, file)
+	foundUser, err := u.authRepo.FindByEmail(ctx, &models.User{Email: strings.ToLower(strings.TrimSpace(email))})
 	if err != nil {
-		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.UploadAvatar.PutObject"))
+		u.logger.Infof("authUC.ForgotPassword.FindByEmail: %v", err)
+		return nil
 	}
 
-	avatarURL := u.generateAWSMinioURL(file.BucketName, uploadInfo.Key)
+	resetToken, err := utils.GenerateRandomToken(userTokenSize)
+	if err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ForgotPassword.GenerateRandomToken"))
+	}
 
-	updatedUser, err := u.authRepo.Update(ctx, &models.User{
-		UserID: userID,
-		Avatar: &avatarURL,
+	if err = u.redisRepo.SetUserTokenCtx(ctx, u.generatePasswordResetKey(utils.HashToken(resetToken)), u.cfg.Server.PasswordResetExpire, &models.UserToken{
+		UserID: foundUser.UserID,
+		Email:  foundUser.Email,
+	}); err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ForgotPassword.SetUserTokenCtx"))
+	}
+
+	if err = u.mailer.Send(ctx, &mailer.Message{
+		To:      []string{foundUser.Email},
+		Subject: "Password reset",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nUse the link below to reset your password, it expires in %d minutes:\n%s/reset-password?token=%s\n\nIf you did not request a password reset, you can ignore this email.\n",
+			foundUser.FirstName,
+			u.cfg.Server.PasswordResetExpire/60,
+			u.cfg.Server.BaseURL,
+			resetToken,
+		),
+	}); err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ForgotPassword.mailer.Send"))
+	}
+
+	return nil
+}
+
+// Set new user password using single use reset token and invalidate all user sessions and refresh tokens
+func (u *authUC) ResetPassword(ctx context.Context, resetToken string, password string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.ResetPassword")
+	defer span.Finish()
+
+	password = strings.TrimSpace(password)
+	if err := u.validatePassword(password); err != nil {
+		return err
+	}
+
+	userToken, err := u.redisRepo.ConsumeUserTokenCtx(ctx, u.generatePasswordResetKey(utils.HashToken(resetToken)))
+	if err != nil {
+		return httpErrors.NewUnauthorizedError(httpErrors.InvalidResetToken)
+	}
+
+	user := &models.User{UserID: userToken.UserID, Password: password}
+	if err = user.HashPassword(u.passwordHasher); err != nil {
+		return httpErrors.NewBadRequestError(errors.Wrap(err, "authUC.ResetPassword.HashPassword"))
+	}
+
+	if err = u.authRepo.UpdatePassword(ctx, user.UserID, user.Password); err != nil {
+		return err
+	}
+
+	if err = u.sessRepo.DeleteAllByUserID(ctx, user.UserID); err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ResetPassword.DeleteAllByUserID"))
+	}
+
+	if err = u.revokeTokenFamilies(ctx, user.UserID, uuid.Nil); err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ResetPassword.revokeTokenFamilies"))
+	}
+
+	if err = u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(user.UserID.String())); err != nil {
+		u.logger.Errorf("authUC.ResetPassword.DeleteUserCtx: %v", err)
+	}
+
+	u.recordAuditEvent(ctx, &models.AuditEvent{
+		Action:     models.AuditActionPasswordReset,
+		ActorID:    user.UserID,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.UserID.String(),
+	})
+
+	return nil
+}
+
+// Change password of logged in user, all sessions and refresh token families except current ones are deleted
+func (u *authUC) ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword string, newPassword string, currentSessionID string, currentRefreshToken string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.ChangePassword")
+	defer span.Finish()
+
+	user, err := u.authRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	foundUser, err := u.authRepo.FindByEmail(ctx, &models.User{Email: user.Email})
+	if err != nil {
+		return err
+	}
+	if err = foundUser.ComparePasswords(u.passwordHasher, currentPassword); err != nil {
+		return httpErrors.NewUnauthorizedError(errors.Wrap(err, "authUC.ChangePassword.ComparePasswords"))
+	}
+
+	updatedUser := &models.User{UserID: userID, Password: strings.TrimSpace(newPassword)}
+	if updatedUser.Password == currentPassword {
+		return httpErrors.NewBadRequestError(errors.New("authUC.ChangePassword: new password is the same as current"))
+	}
+	if err = u.validatePassword(updatedUser.Password); err != nil {
+		return err
+	}
+	if err = updatedUser.HashPassword(u.passwordHasher); err != nil {
+		return httpErrors.NewBadRequestError(errors.Wrap(err, "authUC.ChangePassword.HashPassword"))
+	}
+
+	if err = u.authRepo.UpdatePassword(ctx, userID, updatedUser.Password); err != nil {
+		return err
+	}
+
+	sessions, err := u.sessRepo.GetSessionsByUserID(ctx, userID)
+	if err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ChangePassword.GetSessionsByUserID"))
+	}
+	for _, sess := range sessions {
+		if currentSessionID != "" && sess.SessionID == currentSessionID {
+			continue
+		}
+		if err = u.sessRepo.DeleteSession(ctx, sess); err != nil {
+			return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ChangePassword.DeleteSession"))
+		}
+	}
+
+	if err = u.revokeTokenFamilies(ctx, userID, u.currentTokenFamily(ctx, userID, currentRefreshToken)); err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ChangePassword.revokeTokenFamilies"))
+	}
+
+	if err = u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(userID.String())); err != nil {
+		u.logger.Errorf("authUC.ChangePassword.DeleteUserCtx: %v", err)
+	}
+
+	u.recordAuditEvent(ctx, &models.AuditEvent{
+		Action:     models.AuditActionPasswordChange,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID.String(),
 	})
+
+	return nil
+}
+
+// Mark user email as verified using single use verification token
+func (u *authUC) VerifyEmail(ctx context.Context, verifyToken string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.VerifyEmail")
+	defer span.Finish()
+
+	userToken, err := u.redisRepo.ConsumeUserTokenCtx(ctx, u.generateEmailVerifyKey(utils.HashToken(verifyToken)))
 	if err != nil {
-		return nil, err
+		return httpErrors.NewUnauthorizedError(httpErrors.InvalidVerifyToken)
 	}
 
-	updatedUser.SanitizePassword()
+	if err = u.authRepo.VerifyEmail(ctx, userToken.UserID, userToken.Email); err != nil {
+		return httpErrors.NewUnauthorizedError(httpErrors.InvalidVerifyToken)
+	}
 
-	return updatedUser, nil
+	if err = u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(userToken.UserID.String())); err != nil {
+		u.logger.Errorf("authUC.VerifyEmail.DeleteUserCtx: %v", err)
+	}
+
+	return nil
 }
 
-func (u *authUC) GenerateUserKey(userID string) string {
-	return fmt.Sprintf("%s: %s", basePrefix, userID)
+// Start email change, new email is stored as pending until confirmed with token sent to the new address
+func (u *authUC) ChangeEmail(ctx context.Context, userID uuid.UUID, email string, password string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.ChangeEmail")
+	defer span.Finish()
+
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	user, err := u.authRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	foundUser, err := u.authRepo.FindByEmail(ctx, &models.User{Email: user.Email})
+	if err != nil {
+		return err
+	}
+	if err = foundUser.ComparePasswords(u.passwordHasher, password); err != nil {
+		return httpErrors.NewUnauthorizedError(errors.Wrap(err, "authUC.ChangeEmail.ComparePasswords"))
+	}
+
+	if email == user.Email {
+		return httpErrors.NewBadRequestError(errors.New("authUC.ChangeEmail: new email is the same as current"))
+	}
+	if existsUser, err := u.authRepo.FindByEmail(ctx, &models.User{Email: email}); existsUser != nil || err == nil {
+		return httpErrors.NewRestErrorWithMessage(http.StatusBadRequest, httpErrors.ErrEmailAlreadyExists, nil)
+	}
+
+	if err = u.authRepo.SetPendingEmail(ctx, userID, email); err != nil {
+		return err
+	}
+
+	changeToken, err := utils.GenerateRandomToken(userTokenSize)
+	if err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ChangeEmail.GenerateRandomToken"))
+	}
+
+	if err = u.redisRepo.SetUserTokenCtx(ctx, u.generateEmailChangeKey(utils.HashToken(changeToken)), u.cfg.Server.EmailChangeExpire, &models.UserToken{
+		UserID: userID,
+		Email:  email,
+	}); err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ChangeEmail.SetUserTokenCtx"))
+	}
+
+	if err = u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(userID.String())); err != nil {
+		u.logger.Errorf("authUC.ChangeEmail.DeleteUserCtx: %v", err)
+	}
+
+	if err = u.mailer.Send(ctx, &mailer.Message{
+		To:      []string{email},
+		Subject: "Confirm your new email",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nPlease confirm your new email address by opening the link below:\n%s/api/v1/auth/email/confirm?token=%s\n\nIf you did not request an email change, you can ignore this email.\n",
+			user.FirstName,
+			u.cfg.Server.BaseURL,
+			changeToken,
+		),
+	}); err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ChangeEmail.mailer.Send"))
+	}
+
+	return nil
 }
 
-func (u *authUC) generateAWSMinioURL(bucket string, key string) string {
-	return fmt.Sprintf("%s/minio/%s/%s", u.cfg.AWS.MinioEndpoint, bucket, key)
+// Confirm pending email with single use token, previous address is notified with link to revert the change
+func (u *authUC) ConfirmEmailChange(ctx context.Context, changeToken string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.ConfirmEmailChange")
+	defer span.Finish()
+
+	userToken, err := u.redisRepo.ConsumeUserTokenCtx(ctx, u.generateEmailChangeKey(utils.HashToken(changeToken)))
+	if err != nil {
+		return httpErrors.NewUnauthorizedError(httpErrors.InvalidEmailChange)
+	}
+
+	user, err := u.authRepo.GetByID(ctx, userToken.UserID)
+	if err != nil {
+		return err
+	}
+
+	revertToken, err := utils.GenerateRandomToken(userTokenSize)
+	if err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ConfirmEmailChange.GenerateRandomToken"))
+	}
+	if err = u.redisRepo.SetUserTokenCtx(ctx, u.generateEmailRevertKey(utils.HashToken(revertToken)), u.cfg.Server.EmailRevertExpire, &models.UserToken{
+		UserID: user.UserID,
+		Email:  user.Email,
+	}); err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ConfirmEmailChange.SetUserTokenCtx"))
+	}
+
+	if err = u.authRepo.ConfirmPendingEmail(ctx, user.UserID, userToken.Email); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return httpErrors.NewUnauthorizedError(httpErrors.InvalidEmailChange)
+		}
+		if httpErrors.IsUniqueViolation(err) {
+			return httpErrors.NewRestErrorWithMessage(http.StatusConflict, httpErrors.ErrEmailAlreadyExists, nil)
+		}
+		return err
+	}
+
+	if err = u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(user.UserID.String())); err != nil {
+		u.logger.Errorf("authUC.ConfirmEmailChange.DeleteUserCtx: %v", err)
+	}
+
+	u.recordAuditEvent(ctx, &models.AuditEvent{
+		Action:     models.AuditActionEmailChange,
+		ActorID:    user.UserID,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.UserID.String(),
+		Metadata:   map[string]interface{}{"old_email": user.Email, "new_email": userToken.Email},
+	})
+
+	if err = u.mailer.Send(ctx, &mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Your email was changed",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nThe email address of your account was changed to %s.\nIf you did not make this change, restore your email and log out all sessions by opening the link below, it expires in %d days:\n%s/api/v1/auth/email/revert?token=%s\n",
+			user.FirstName,
+			userToken.Email,
+			u.cfg.Server.EmailRevertExpire/86400,
+			u.cfg.Server.BaseURL,
+			revertToken,
+		),
+	}); err != nil {
+		u.logger.Errorf("authUC.ConfirmEmailChange.mailer.Send: %v", err)
+	}
+
+	return nil
+}
+
+// Restore previous email with single use token sent to it, all user sessions and refresh token families are invalidated
+func (u *authUC) RevertEmailChange(ctx context.Context, revertToken string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.RevertEmailChange")
+	defer span.Finish()
+
+	userToken, err := u.redisRepo.ConsumeUserTokenCtx(ctx, u.generateEmailRevertKey(utils.HashToken(revertToken)))
+	if err != nil {
+		return httpErrors.NewUnauthorizedError(httpErrors.InvalidEmailChange)
+	}
+
+	if err = u.authRepo.RevertEmail(ctx, userToken.UserID, userToken.Email); err != nil {
+		if httpErrors.IsUniqueViolation(err) {
+			return httpErrors.NewRestErrorWithMessage(http.StatusConflict, httpErrors.ErrEmailAlreadyExists, nil)
+		}
+		return err
+	}
+
+	if err = u.sessRepo.DeleteAllByUserID(ctx, userToken.UserID); err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.RevertEmailChange.DeleteAllByUserID"))
+	}
+
+	if err = u.revokeTokenFamilies(ctx, userToken.UserID, uuid.Nil); err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.RevertEmailChange.revokeTokenFamilies"))
+	}
+
+	if err = u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(userToken.UserID.String())); err != nil {
+		u.logger.Errorf("authUC.RevertEmailChange.DeleteUserCtx: %v", err)
+	}
+
+	u.recordAuditEvent(ctx, &models.AuditEvent{
+		Action:     models.AuditActionEmailRevert,
+		ActorID:    userToken.UserID,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userToken.UserID.String(),
+		Metadata:   map[string]interface{}{"email": userToken.Email},
+	})
+
+	return nil
+}
+
+// Send new verification link to the user email.
+// Unknown and already verified emails are not reported to the caller to prevent user enumeration.
+func (u *authUC) ResendVerification(ctx context.Context, email string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.ResendVerification")
+	defer span.Finish()
+
+	foundUser, err := u.authRepo.FindByEmail(ctx, &models.User{Email: strings.ToLower(strings.TrimSpace(email))})
+	if err != nil {
+		u.logger.Infof("authUC.ResendVerification.FindByEmail: %v", err)
+		return nil
+	}
+	if foundUser.IsVerified() {
+		return nil
+	}
+
+	if err = u.sendVerificationEmail(ctx, foundUser); err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ResendVerification.sendVerificationEmail"))
+	}
+
+	return nil
+}
+
+// Finish two step login with TOTP or recovery code, challenge can be used only once
+func (u *authUC) LoginTwoFactor(ctx context.Context, challenge string, code string) (*models.UserWithToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.LoginTwoFactor")
+	defer span.Finish()
+
+	userToken, err := u.redisRepo.ConsumeUserTokenCtx(ctx, u.generateChallengeKey(utils.HashToken(challenge)))
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(httpErrors.InvalidChallenge)
+	}
+
+	if err = u.verifySecondFactor(ctx, userToken.UserID, code); err != nil {
+		u.logger.Warnf("authUC.LoginTwoFactor.verifySecondFactor UserID: %s, Error: %v", userToken.UserID.String(), err)
+		u.recordAuditEvent(ctx, &models.AuditEvent{
+			Action:     models.AuditActionLoginFailed,
+			TargetType: models.AuditTargetUser,
+			TargetID:   userToken.UserID.String(),
+			Metadata:   map[string]interface{}{"email": userToken.Email, "second_factor": true},
+		})
+		return nil, httpErrors.NewUnauthorizedError(httpErrors.InvalidTwoFactorCode)
+	}
+
+	user, err := u.authRepo.GetByID(ctx, userToken.UserID)
+	if err != nil {
+		return nil, err
+	}
+	user.SanitizePassword()
+	if err = checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	return u.issueLoginTokens(ctx, user)
+}
+
+// Generate new TOTP secret, two factor is enabled only after code confirmation
+func (u *authUC) EnrollTOTP(ctx context.Context, user *models.User) (*models.TOTPEnrollment, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.EnrollTOTP")
+	defer span.Finish()
+
+	if user.TwoFactorEnabled {
+		return nil, httpErrors.NewBadRequestError(httpErrors.TwoFactorEnabled)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.EnrollTOTP.GenerateSecret"))
+	}
+
+	if err = u.authRepo.CreateTOTP(ctx, user.UserID, secret); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "authUC.EnrollTOTP.CreateTOTP"))
+	}
+
+	return &models.TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(u.cfg.TwoFactor.Issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm TOTP enrolment with the first code, returns new recovery codes
+func (u *authUC) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) (*models.RecoveryCodes, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.ConfirmTOTP")
+	defer span.Finish()
+
+	userTOTP, err := u.authRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, httpErrors.NewBadRequestError(httpErrors.TwoFactorNotEnrolled)
+	}
+	if userTOTP.ConfirmedAt != nil {
+		return nil, httpErrors.NewBadRequestError(httpErrors.TwoFactorEnabled)
+	}
+
+	step, ok := totp.Validate(code, userTOTP.Secret, time.Now())
+	if !ok {
+		return nil, httpErrors.NewUnauthorizedError(httpErrors.InvalidTwoFactorCode)
+	}
+	if err = u.authRepo.UpdateTOTPStep(ctx, userID, step); err != nil {
+		return nil, httpErrors.NewUnauthorizedError(httpErrors.InvalidTwoFactorCode)
+	}
+
+	codes, hashes, err := u.generateRecoveryCodes()
+	if err != nil {
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.ConfirmTOTP.generateRecoveryCodes"))
+	}
+
+	if err = u.authRepo.EnableTwoFactor(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	if err = u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(userID.String())); err != nil {
+		u.logger.Errorf("authUC.ConfirmTOTP.DeleteUserCtx: %v", err)
+	}
+
+	return &models.RecoveryCodes{Codes: codes}, nil
+}
+
+// Disable two factor, requires valid TOTP or recovery code
+func (u *authUC) DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.DisableTOTP")
+	defer span.Finish()
+
+	if err := u.verifySecondFactor(ctx, userID, code); err != nil {
+		return httpErrors.NewUnauthorizedError(httpErrors.InvalidTwoFactorCode)
+	}
+
+	if err := u.authRepo.DisableTwoFactor(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(userID.String())); err != nil {
+		u.logger.Errorf("authUC.DisableTOTP.DeleteUserCtx: %v", err)
+	}
+
+	return nil
+}
+
+// Unlock user account and reset failed login attempts
+func (u *authUC) Unlock(ctx context.Context, userID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.Unlock")
+	defer span.Finish()
+
+	user, err := u.authRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = u.authRepo.UpdateLockedUntil(ctx, userID, nil); err != nil {
+		return err
+	}
+
+	if err = u.redisRepo.DeleteLoginAttemptsCtx(ctx, u.generateLoginAttemptsKey(emailAttemptsPrefix, user.Email)); err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.Unlock.DeleteLoginAttemptsCtx"))
+	}
+
+	if err = u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(userID.String())); err != nil {
+		u.logger.Errorf("authUC.Unlock.DeleteUserCtx: %v", err)
+	}
+
+	return nil
+}
+
+// Change user account status, all sessions of suspended or banned user are revoked
+func (u *authUC) UpdateStatus(ctx context.Context, userID uuid.UUID, status *models.UserStatus) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.UpdateStatus")
+	defer span.Finish()
+
+	if status.Status == models.UserStatusActive {
+		status.Reason, status.Until = nil, nil
+	}
+	if status.Until != nil && !status.Until.After(time.Now()) {
+		return httpErrors.NewBadRequestError(httpErrors.InvalidStatusUntil)
+	}
+
+	if err := u.authRepo.UpdateStatus(ctx, userID, status); err != nil {
+		return err
+	}
+
+	if status.Status != models.UserStatusActive {
+		if err := u.sessRepo.DeleteAllByUserID(ctx, userID); err != nil {
+			return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.UpdateStatus.DeleteAllByUserID"))
+		}
+	}
+
+	if err := u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(userID.String())); err != nil {
+		u.logger.Errorf("authUC.UpdateStatus.DeleteUserCtx: %v", err)
+	}
+
+	u.recordAuditEvent(ctx, &models.AuditEvent{
+		Action:     models.AuditActionStatusChange,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID.String(),
+		Metadata:   map[string]interface{}{"status": status.Status, "reason": status.Reason, "until": status.Until},
+	})
+
+	return nil
+}
+
+// Get user to impersonate, users allowed to impersonate others and inactive users can not be impersonated
+func (u *authUC) Impersonate(ctx context.Context, adminID uuid.UUID, userID uuid.UUID) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.Impersonate")
+	defer span.Finish()
+
+	if adminID == userID {
+		return nil, httpErrors.NewBadRequestError(httpErrors.ImpersonationDenied)
+	}
+
+	user, err := u.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, httpErrors.NewRestError(http.StatusForbidden, httpErrors.ImpersonationDenied.Error(), nil)
+	}
+
+	permissions, err := u.GetPermissions(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if permissions.Has(models.PermissionUsersImpersonate) {
+		return nil, httpErrors.NewRestError(http.StatusForbidden, httpErrors.ImpersonationDenied.Error(), nil)
+	}
+
+	return user, nil
+}
+
+// Create API key, plain key is returned only once and stored as hash
+func (u *authUC) CreateAPIKey(ctx context.Context, apiKey *models.APIKey) (*models.APIKeyWithSecret, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.CreateAPIKey")
+	defer span.Finish()
+
+	if apiKey.IsExpired() {
+		return nil, httpErrors.NewBadRequestError(httpErrors.APIKeyExpirationError)
+	}
+
+	prefix := make([]byte, apiKeyPrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.CreateAPIKey.Read"))
+	}
+	secret, err := utils.GenerateRandomToken(apiKeySecretSize)
+	if err != nil {
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.CreateAPIKey.GenerateRandomToken"))
+	}
+
+	apiKey.Prefix = models.APIKeyPrefix + hex.EncodeToString(prefix)
+	key := fmt.Sprintf("%s.%s", apiKey.Prefix, secret)
+	apiKey.KeyHash = utils.HashToken(key)
+
+	createdKey, err := u.authRepo.CreateAPIKey(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.APIKeyWithSecret{
+		APIKey: createdKey,
+		Key:    key,
+	}, nil
+}
+
+// Get all API keys of user
+func (u *authUC) GetAPIKeys(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.GetAPIKeys")
+	defer span.Finish()
+
+	return u.authRepo.GetAPIKeysByUserID(ctx, userID)
+}
+
+// Get user API key by id
+func (u *authUC) GetAPIKeyByID(ctx context.Context, userID uuid.UUID, apiKeyID uuid.UUID) (*models.APIKey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.GetAPIKeyByID")
+	defer span.Finish()
+
+	return u.authRepo.GetAPIKeyByID(ctx, userID, apiKeyID)
+}
+
+// Update API key name and scopes
+func (u *authUC) UpdateAPIKey(ctx context.Context, apiKey *models.APIKey) (*models.APIKey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.UpdateAPIKey")
+	defer span.Finish()
+
+	return u.authRepo.UpdateAPIKey(ctx, apiKey)
+}
+
+// Delete user API key
+func (u *authUC) DeleteAPIKey(ctx context.Context, userID uuid.UUID, apiKeyID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.DeleteAPIKey")
+	defer span.Finish()
+
+	return u.authRepo.DeleteAPIKey(ctx, userID, apiKeyID)
+}
+
+// Authenticate request by plain API key, returns key owner and API key
+func (u *authUC) AuthenticateAPIKey(ctx context.Context, key string) (*models.User, *models.APIKey, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.AuthenticateAPIKey")
+	defer span.Finish()
+
+	apiKey, err := u.authRepo.GetAPIKeyByHash(ctx, utils.HashToken(key))
+	if err != nil {
+		return nil, nil, httpErrors.NewUnauthorizedError(httpErrors.InvalidAPIKey)
+	}
+
+	if apiKey.IsExpired() {
+		return nil, nil, httpErrors.NewUnauthorizedError(httpErrors.InvalidAPIKey)
+	}
+
+	if apiKey.LastUsedAt == nil || time.Since(*apiKey.LastUsedAt) > apiKeyLastUsedInterval {
+		if err = u.authRepo.UpdateAPIKeyLastUsed(ctx, apiKey.APIKeyID, time.Now()); err != nil {
+			u.logger.Errorf("authUC.AuthenticateAPIKey.UpdateAPIKeyLastUsed: %v", err)
+		}
+	}
+
+	user, err := u.GetByID(ctx, apiKey.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, apiKey, nil
+}
+
+// Get public JWT verification keys
+func (u *authUC) GetJWKS(ctx context.Context) *jwtkeys.JWKS {
+	span, _ := opentracing.StartSpanFromContext(ctx, "authUC.GetJWKS")
+	defer span.Finish()
+
+	return u.jwtKeys.JWKS()
+}
+
+// Get permissions of user role, cached per user together with resolved role
+func (u *authUC) GetPermissions(ctx context.Context, user *models.User) (*models.UserPermissions, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.GetPermissions")
+	defer span.Finish()
+
+	var role string
+	if user.Role != nil {
+		role = *user.Role
+	}
+
+	cachedPermissions, err := u.redisRepo.GetPermissionsCtx(ctx, u.generatePermissionsKey(user.UserID.String()))
+	if err != nil {
+		u.logger.Errorf("authUC.GetPermissions.GetPermissionsCtx: %v", err)
+	}
+	if cachedPermissions != nil && cachedPermissions.Role == role {
+		return cachedPermissions, nil
+	}
+
+	permissions, err := u.authRepo.GetPermissionsByRole(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+
+	userPermissions := &models.UserPermissions{Role: role, Permissions: permissions}
+	if err = u.redisRepo.SetPermissionsCtx(ctx, u.generatePermissionsKey(user.UserID.String()), cacheDuration, userPermissions); err != nil {
+		u.logger.Errorf("authUC.GetPermissions.SetPermissionsCtx: %v", err)
+	}
+
+	return userPermissions, nil
+}
+
+// Get all roles with permissions
+func (u *authUC) GetRoles(ctx context.Context) ([]*models.Role, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.GetRoles")
+	defer span.Finish()
+
+	return u.authRepo.GetRoles(ctx)
+}
+
+// Assign role to user
+func (u *authUC) UpdateRole(ctx context.Context, userID uuid.UUID, role string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.UpdateRole")
+	defer span.Finish()
+
+	if err := u.authRepo.UpdateRole(ctx, userID, role); err != nil {
+		return err
+	}
+
+	if err := u.redisRepo.DeleteUserCtx(ctx, u.GenerateUserKey(userID.String())); err != nil {
+		u.logger.Errorf("authUC.UpdateRole.DeleteUserCtx: %v", err)
+	}
+	if err := u.redisRepo.DeletePermissionsCtx(ctx, u.generatePermissionsKey(userID.String())); err != nil {
+		u.logger.Errorf("authUC.UpdateRole.DeletePermissionsCtx: %v", err)
+	}
+
+	u.recordAuditEvent(ctx, &models.AuditEvent{
+		Action:     models.AuditActionRoleChange,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID.String(),
+		Metadata:   map[string]interface{}{"role": role},
+	})
+
+	return nil
+}
+
+// Send single use login link to user email, requests are rate limited by email and client ip
+func (u *authUC) SendMagicLink(ctx context.Context, email string, ipAddress string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.SendMagicLink")
+	defer span.Finish()
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	window := time.Second * time.Duration(u.cfg.MagicLink.RequestsWindow)
+
+	ipRequests, err := u.redisRepo.IncrLoginAttemptsCtx(ctx, u.generateLoginAttemptsKey(magicLinkIPPrefix, ipAddress), u.cfg.MagicLink.RequestsWindow)
+	if err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.SendMagicLink.IncrLoginAttemptsCtx.ip"))
+	}
+	if ipRequests > int64(u.cfg.MagicLink.IPMaxRequests) {
+		return httpErrors.NewTooManyRequestsError(window)
+	}
+
+	emailRequests, err := u.redisRepo.IncrLoginAttemptsCtx(ctx, u.generateLoginAttemptsKey(magicLinkEmailPrefix, email), u.cfg.MagicLink.RequestsWindow)
+	if err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.SendMagicLink.IncrLoginAttemptsCtx.email"))
+	}
+	if emailRequests > int64(u.cfg.MagicLink.MaxRequests) {
+		return httpErrors.NewTooManyRequestsError(window)
+	}
+
+	foundUser, err := u.authRepo.FindByEmail(ctx, &models.User{Email: email})
+	if err != nil {
+		u.logger.Infof("authUC.SendMagicLink.FindByEmail: %v", err)
+		return nil
+	}
+
+	loginToken, err := utils.GenerateRandomToken(userTokenSize)
+	if err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.SendMagicLink.GenerateRandomToken"))
+	}
+
+	if err = u.redisRepo.SetUserTokenCtx(ctx, u.generateMagicLinkKey(utils.HashToken(loginToken)), u.cfg.MagicLink.Expire, &models.UserToken{
+		UserID: foundUser.UserID,
+		Email:  foundUser.Email,
+	}); err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.SendMagicLink.SetUserTokenCtx"))
+	}
+
+	if err = u.mailer.Send(ctx, &mailer.Message{
+		To:      []string{foundUser.Email},
+		Subject: "Your login link",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nUse the link below to log in, it can be used once and expires in %d minutes:\n%s/api/v1/auth/magic-link/callback?token=%s\n\nIf you did not request a login link, you can ignore this email.\n",
+			foundUser.FirstName,
+			u.cfg.MagicLink.Expire/60,
+			u.cfg.Server.BaseURL,
+			loginToken,
+		),
+	}); err != nil {
+		return httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.SendMagicLink.mailer.Send"))
+	}
+
+	return nil
+}
+
+// Login with single use magic link token, token is valid only for the email it was sent to
+func (u *authUC) LoginMagicLink(ctx context.Context, loginToken string) (*models.UserWithToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.LoginMagicLink")
+	defer span.Finish()
+
+	userToken, err := u.redisRepo.ConsumeUserTokenCtx(ctx, u.generateMagicLinkKey(utils.HashToken(loginToken)))
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(httpErrors.InvalidMagicLink)
+	}
+
+	user, err := u.authRepo.GetByID(ctx, userToken.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Email != userToken.Email {
+		return nil, httpErrors.NewUnauthorizedError(httpErrors.InvalidMagicLink)
+	}
+
+	if user.IsLocked() {
+		return nil, httpErrors.NewLockoutError(time.Until(*user.LockedUntil))
+	}
+
+	return u.completeLogin(ctx, user)
+}
+
+// Start OpenID Connect login, returns provider authorization url and state bound to it
+func (u *authUC) OIDCAuthURL(ctx context.Context, provider string) (string, string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.OIDCAuthURL")
+	defer span.Finish()
+
+	oidcProvider, ok := u.oidcProviders[provider]
+	if !ok {
+		return "", "", httpErrors.NewRestError(http.StatusNotFound, httpErrors.OIDCProviderNotFound.Error(), nil)
+	}
+
+	state, err := utils.GenerateRandomToken(userTokenSize)
+	if err != nil {
+		return "", "", httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.OIDCAuthURL.GenerateRandomToken.state"))
+	}
+	nonce, err := utils.GenerateRandomToken(userTokenSize)
+	if err != nil {
+		return "", "", httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.OIDCAuthURL.GenerateRandomToken.nonce"))
+	}
+	codeVerifier, err := oidc.GenerateCodeVerifier()
+	if err != nil {
+		return "", "", httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.OIDCAuthURL.GenerateCodeVerifier"))
+	}
+
+	authURL, err := oidcProvider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return "", "", httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.OIDCAuthURL.AuthCodeURL"))
+	}
+
+	if err = u.redisRepo.SetOIDCAuthRequestCtx(ctx, u.generateOIDCStateKey(utils.HashToken(state)), u.cfg.OIDC.StateExpire, &models.OIDCAuthRequest{
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+	}); err != nil {
+		return "", "", httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.OIDCAuthURL.SetOIDCAuthRequestCtx"))
+	}
+
+	return authURL, state, nil
+}
+
+// Complete OpenID Connect login, external identity is linked to existing user by verified email or new user is created
+func (u *authUC) OIDCLogin(ctx context.Context, provider string, state string, code string) (*models.UserWithToken, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.OIDCLogin")
+	defer span.Finish()
+
+	oidcProvider, ok := u.oidcProviders[provider]
+	if !ok {
+		return nil, httpErrors.NewRestError(http.StatusNotFound, httpErrors.OIDCProviderNotFound.Error(), nil)
+	}
+
+	authRequest, err := u.redisRepo.ConsumeOIDCAuthRequestCtx(ctx, u.generateOIDCStateKey(utils.HashToken(state)))
+	if err != nil || authRequest.Provider != provider {
+		return nil, httpErrors.NewUnauthorizedError(httpErrors.InvalidOIDCState)
+	}
+
+	tokenResponse, err := oidcProvider.Exchange(ctx, code, authRequest.CodeVerifier)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.Wrap(err, "authUC.OIDCLogin.Exchange"))
+	}
+
+	claims, err := oidcProvider.VerifyIDToken(ctx, tokenResponse.IDToken, authRequest.Nonce)
+	if err != nil {
+		return nil, httpErrors.NewUnauthorizedError(errors.Wrap(err, "authUC.OIDCLogin.VerifyIDToken"))
+	}
+
+	user, err := u.getOIDCUser(ctx, provider, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsLocked() {
+		return nil, httpErrors.NewLockoutError(time.Until(*user.LockedUntil))
+	}
+
+	return u.completeLogin(ctx, user)
+}
+
+// Upload user avatar
+func (u *authUC) UploadAvatar(ctx context.Context, userID uuid.UUID, file models.UploadInput) (*models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "authUC.UploadAvatar")
+	defer span.Finish()
+
+	uploadInfo, err := u.awsRepo.PutObject(ctx, file)
+	if err != nil {
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.UploadAvatar.PutObject"))
+	}
+
+	avatarURL := u.generateAWSMinioURL(file.BucketName, uploadInfo.Key)
+
+	updatedUser, err := u.authRepo.Update(ctx, &models.User{
+		UserID: userID,
+		Avatar: &avatarURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updatedUser.SanitizePassword()
+
+	return updatedUser, nil
+}
+
+// Check caller may write every field set in user update
+func checkUpdatePermissions(ctx context.Context, user *models.User) error {
+	if user.Role != nil && !utils.HasPermission(ctx, models.PermissionUsersRoles) {
+		return httpErrors.NewRestError(http.StatusForbidden, httpErrors.FieldUpdateDenied.Error(), "role")
+	}
+	if user.Avatar != nil && !utils.HasPermission(ctx, models.PermissionUsersUpdate) {
+		return httpErrors.NewRestError(http.StatusForbidden, httpErrors.FieldUpdateDenied.Error(), "avatar")
+	}
+	return nil
+}
+
+// Suspended or banned user can not log in or refresh tokens
+func checkUserStatus(user *models.User) error {
+	if user.IsActive() {
+		return nil
+	}
+	if user.Status == models.UserStatusBanned {
+		return httpErrors.NewRestError(http.StatusForbidden, httpErrors.AccountBanned.Error(), nil)
+	}
+	return httpErrors.NewRestError(http.StatusForbidden, httpErrors.AccountSuspended.Error(), nil)
+}
+
+// Re-hash password with current hashing parameters after successful login, failures are logged and login proceeds
+func (u *authUC) rehashPassword(ctx context.Context, userID uuid.UUID, password string) {
+	user := &models.User{UserID: userID, Password: password}
+	if err := user.HashPassword(u.passwordHasher); err != nil {
+		u.logger.Errorf("authUC.rehashPassword.HashPassword: %v", err)
+		return
+	}
+	if err := u.authRepo.UpdatePassword(ctx, userID, user.Password); err != nil {
+		u.logger.Errorf("authUC.rehashPassword.UpdatePassword: %v", err)
+	}
+}
+
+// Check password against password policy, breached password lookup failures are logged and the check is skipped
+func (u *authUC) validatePassword(password string) error {
+	violations, err := u.passwordPolicy.Validate(password)
+	if err != nil {
+		u.logger.Errorf("authUC.validatePassword.Validate: %v", err)
+	}
+	if len(violations) > 0 {
+		return httpErrors.NewPasswordPolicyError(violations)
+	}
+	return nil
+}
+
+func (u *authUC) GenerateUserKey(userID string) string {
+	return fmt.Sprintf("%s: %s", basePrefix, userID)
+}
+
+func (u *authUC) generateRefreshTokenKey(tokenHash string) string {
+	return fmt.Sprintf("%s: %s", refreshTokenPrefix, tokenHash)
+}
+
+func (u *authUC) generateTokenFamilyKey(familyID string) string {
+	return fmt.Sprintf("%s: %s", tokenFamilyPrefix, familyID)
+}
+
+func (u *authUC) generateUserFamiliesKey(userID string) string {
+	return fmt.Sprintf("%s: %s", userFamiliesPrefix, userID)
+}
+
+func (u *authUC) generatePasswordResetKey(tokenHash string) string {
+	return fmt.Sprintf("%s: %s", passwordResetPrefix, tokenHash)
+}
+
+func (u *authUC) generateEmailVerifyKey(tokenHash string) string {
+	return fmt.Sprintf("%s: %s", emailVerifyPrefix, tokenHash)
+}
+
+func (u *authUC) generateChallengeKey(tokenHash string) string {
+	return fmt.Sprintf("%s: %s", challengePrefix, tokenHash)
+}
+
+func (u *authUC) generateEmailChangeKey(tokenHash string) string {
+	return fmt.Sprintf("%s: %s", emailChangePrefix, tokenHash)
+}
+
+func (u *authUC) generateEmailRevertKey(tokenHash string) string {
+	return fmt.Sprintf("%s: %s", emailRevertPrefix, tokenHash)
+}
+
+func (u *authUC) generateMagicLinkKey(tokenHash string) string {
+	return fmt.Sprintf("%s: %s", magicLinkPrefix, tokenHash)
+}
+
+func (u *authUC) generateOIDCStateKey(stateHash string) string {
+	return fmt.Sprintf("%s: %s", oidcStatePrefix, stateHash)
+}
+
+func (u *authUC) generatePermissionsKey(userID string) string {
+	return fmt.Sprintf("%s: %s", permissionsPrefix, userID)
+}
+
+func (u *authUC) generateLoginAttemptsKey(prefix string, value string) string {
+	return fmt.Sprintf("%s: %s", prefix, strings.ToLower(value))
+}
+
+// Issue tokens for authenticated user or login challenge if two factor authentication is enabled
+func (u *authUC) completeLogin(ctx context.Context, user *models.User) (*models.UserWithToken, error) {
+	user.SanitizePassword()
+
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled {
+		challenge, err := utils.GenerateRandomToken(userTokenSize)
+		if err != nil {
+			return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.completeLogin.GenerateRandomToken"))
+		}
+		if err = u.redisRepo.SetUserTokenCtx(ctx, u.generateChallengeKey(utils.HashToken(challenge)), u.cfg.TwoFactor.ChallengeExpire, &models.UserToken{
+			UserID: user.UserID,
+			Email:  user.Email,
+		}); err != nil {
+			return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.completeLogin.SetUserTokenCtx"))
+		}
+		return &models.UserWithToken{
+			TwoFactorRequired: true,
+			Challenge:         challenge,
+		}, nil
+	}
+
+	return u.issueLoginTokens(ctx, user)
+}
+
+// Issue jwt and new refresh token family for fully authenticated user and record login
+func (u *authUC) issueLoginTokens(ctx context.Context, user *models.User) (*models.UserWithToken, error) {
+	token, err := utils.GenerateJWTToken(user, u.cfg, u.jwtKeys)
+	if err != nil {
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.issueLoginTokens.GenerateJWTToken"))
+	}
+
+	refreshToken, err := u.issueRefreshToken(ctx, user.UserID, uuid.New())
+	if err != nil {
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.issueLoginTokens.issueRefreshToken"))
+	}
+
+	u.recordAuditEvent(ctx, &models.AuditEvent{
+		Action:     models.AuditActionLogin,
+		ActorID:    user.UserID,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.UserID.String(),
+	})
+
+	return &models.UserWithToken{
+		User:         user,
+		Token:        token,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// Resolve user of external identity, links identity to user with the same verified email or registers new user
+func (u *authUC) getOIDCUser(ctx context.Context, provider string, claims *oidc.IDTokenClaims) (*models.User, error) {
+	identity, err := u.authRepo.GetUserIdentity(ctx, provider, claims.Subject)
+	if err == nil {
+		return u.authRepo.GetByID(ctx, identity.UserID)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" || !claims.EmailVerified {
+		return nil, httpErrors.NewRestError(http.StatusBadRequest, httpErrors.OIDCEmailNotVerified.Error(), nil)
+	}
+
+	user, err := u.authRepo.FindByEmail(ctx, &models.User{Email: email})
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		if user, err = u.registerOIDCUser(ctx, email, claims); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err = u.authRepo.CreateUserIdentity(ctx, &models.UserIdentity{
+		UserID:   user.UserID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    &email,
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Register user from external identity, random password can be replaced using password reset
+func (u *authUC) registerOIDCUser(ctx context.Context, email string, claims *oidc.IDTokenClaims) (*models.User, error) {
+	password, err := utils.GenerateRandomToken(userTokenSize)
+	if err != nil {
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "authUC.registerOIDCUser.GenerateRandomToken"))
+	}
+
+	firstName, lastName := oidcUserNames(email, claims)
+	user := &models.User{
+		FirstName: firstName,
+		LastName:  lastName,
+		Email:     email,
+		Password:  password,
+	}
+	if err = user.PrepareCreate(u.passwordHasher); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.Wrap(err, "authUC.registerOIDCUser.PrepareCreate"))
+	}
+
+	createdUser, err := u.authRepo.Register(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = u.authRepo.VerifyEmail(ctx, createdUser.UserID, createdUser.Email); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	createdUser.VerifiedAt = &now
+
+	return createdUser, nil
+}
+
+// First and last name from ID token claims, falls back to email local part
+func oidcUserNames(email string, claims *oidc.IDTokenClaims) (string, string) {
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" {
+		if parts := strings.Fields(claims.Name); len(parts) > 0 {
+			firstName = parts[0]
+			if lastName == "" && len(parts) > 1 {
+				lastName = strings.Join(parts[1:], " ")
+			}
+		}
+	}
+	if firstName == "" {
+		firstName = strings.SplitN(email, "@", 2)[0]
+	}
+	if lastName == "" {
+		lastName = firstName
+	}
+	return truncateName(firstName), truncateName(lastName)
+}
+
+func truncateName(name string) string {
+	if runes := []rune(name); len(runes) > maxNameLength {
+		return string(runes[:maxNameLength])
+	}
+	return name
+}
+
+// Count failed login by client ip and by user email, locks user account when attempts limit exceeded.
+// Returns lock expiration if the account was locked by this attempt.
+func (u *authUC) registerFailedLogin(ctx context.Context, user *models.User, ipAddress string) *time.Time {
+	if _, err := u.redisRepo.IncrLoginAttemptsCtx(ctx, u.generateLoginAttemptsKey(ipAttemptsPrefix, ipAddress), u.cfg.Lockout.AttemptsWindow); err != nil {
+		u.logger.Errorf("authUC.registerFailedLogin.IncrLoginAttemptsCtx: %v", err)
+	}
+	if user == nil {
+		return nil
+	}
+
+	attempts, err := u.redisRepo.IncrLoginAttemptsCtx(ctx, u.generateLoginAttemptsKey(emailAttemptsPrefix, user.Email), u.cfg.Lockout.AttemptsWindow)
+	if err != nil {
+		u.logger.Errorf("authUC.registerFailedLogin.IncrLoginAttemptsCtx: %v", err)
+		return nil
+	}
+	if attempts < int64(u.loginMaxAttempts()) {
+		return nil
+	}
+
+	lockedUntil := time.Now().Add(u.lockoutDelay(attempts))
+	if err = u.authRepo.UpdateLockedUntil(ctx, user.UserID, &lockedUntil); err != nil {
+		u.logger.Errorf("authUC.registerFailedLogin.UpdateLockedUntil: %v", err)
+		return nil
+	}
+
+	u.logger.Warnf("authUC.registerFailedLogin account locked, UserID: %s, Attempts: %d, LockedUntil: %s",
+		user.UserID.String(),
+		attempts,
+		lockedUntil.String(),
+	)
+	return &lockedUntil
+}
+
+// Record audit event, failures are logged and do not fail the action
+func (u *authUC) recordAuditEvent(ctx context.Context, event *models.AuditEvent) {
+	if err := u.auditUC.Record(ctx, event); err != nil {
+		u.logger.Errorf("authUC.recordAuditEvent Action: %s, TargetID: %s, Error: %v", event.Action, event.TargetID, err)
+	}
+}
+
+// Lockout delay doubles with every failed attempt over the limit up to max delay
+func (u *authUC) lockoutDelay(attempts int64) time.Duration {
+	maxDelay := time.Second * time.Duration(u.cfg.Lockout.MaxDelay)
+	exp := attempts - int64(u.loginMaxAttempts())
+	if exp > 30 {
+		return maxDelay
+	}
+	delay := time.Second * time.Duration(u.cfg.Lockout.BaseDelay) << uint(exp)
+	if delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
+
+// Check TOTP code of confirmed secret or fall back to single use recovery code
+func (u *authUC) verifySecondFactor(ctx context.Context, userID uuid.UUID, code string) error {
+	userTOTP, err := u.authRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if userTOTP.ConfirmedAt == nil {
+		return httpErrors.TwoFactorNotEnrolled
+	}
+
+	if step, ok := totp.Validate(code, userTOTP.Secret, time.Now()); ok {
+		return u.authRepo.UpdateTOTPStep(ctx, userID, step)
+	}
+
+	return u.authRepo.UseRecoveryCode(ctx, userID, utils.HashToken(normalizeRecoveryCode(code)))
+}
+
+// Generate recovery codes formatted as xxxxx-xxxxx and their hashes
+func (u *authUC) generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([]string, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		b := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := hex.EncodeToString(b)
+		codes = append(codes, code[:recoveryCodeSize]+"-"+code[recoveryCodeSize:])
+		hashes = append(hashes, utils.HashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// Store new email verification token and send verification link to the user
+func (u *authUC) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	verifyToken, err := utils.GenerateRandomToken(userTokenSize)
+	if err != nil {
+		return err
+	}
+
+	if err = u.redisRepo.SetUserTokenCtx(ctx, u.generateEmailVerifyKey(utils.HashToken(verifyToken)), u.cfg.Server.EmailVerificationExpire, &models.UserToken{
+		UserID: user.UserID,
+		Email:  user.Email,
+	}); err != nil {
+		return err
+	}
+
+	return u.mailer.Send(ctx, &mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Verify your email",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nPlease confirm your email address by opening the link below:\n%s/api/v1/auth/verify?token=%s\n",
+			user.FirstName,
+			u.cfg.Server.BaseURL,
+			verifyToken,
+		),
+	})
+}
+
+// Revoke the whole family of a refresh token presented more than once
+func (u *authUC) revokeReusedTokenFamily(ctx context.Context, storedToken *models.RefreshToken) error {
+	if err := u.redisRepo.DeleteTokenFamilyCtx(ctx, u.generateTokenFamilyKey(storedToken.FamilyID.String())); err != nil {
+		u.logger.Errorf("authUC.RefreshTokens.DeleteTokenFamilyCtx: %v", err)
+	}
+	u.logger.Warnf("authUC.RefreshTokens refresh token reuse detected, UserID: %s, FamilyID: %s",
+		storedToken.UserID.String(),
+		storedToken.FamilyID.String(),
+	)
+	return httpErrors.NewUnauthorizedError(httpErrors.RefreshTokenReused)
+}
+
+// Revoke all refresh token families of the user except the kept one, pass uuid.Nil to revoke every family
+func (u *authUC) revokeTokenFamilies(ctx context.Context, userID uuid.UUID, keepFamilyID uuid.UUID) error {
+	familiesKey := u.generateUserFamiliesKey(userID.String())
+	familyIDs, err := u.redisRepo.GetUserTokenFamiliesCtx(ctx, familiesKey)
+	if err != nil {
+		return err
+	}
+
+	revoked := make([]string, 0, len(familyIDs))
+	for _, familyID := range familyIDs {
+		if keepFamilyID != uuid.Nil && familyID == keepFamilyID.String() {
+			continue
+		}
+		if err = u.redisRepo.DeleteTokenFamilyCtx(ctx, u.generateTokenFamilyKey(familyID)); err != nil {
+			return err
+		}
+		revoked = append(revoked, familyID)
+	}
+
+	return u.redisRepo.RemoveUserTokenFamiliesCtx(ctx, familiesKey, revoked)
+}
+
+// Get token family of the user refresh token, returns uuid.Nil if token is empty, unknown or belongs to another user
+func (u *authUC) currentTokenFamily(ctx context.Context, userID uuid.UUID, refreshToken string) uuid.UUID {
+	if refreshToken == "" {
+		return uuid.Nil
+	}
+	storedToken, err := u.redisRepo.GetRefreshTokenCtx(ctx, u.generateRefreshTokenKey(utils.HashToken(refreshToken)))
+	if err != nil || storedToken.UserID != userID {
+		return uuid.Nil
+	}
+	return storedToken.FamilyID
+}
+
+// Store new refresh token of the family and extend the family lifetime
+func (u *authUC) issueRefreshToken(ctx context.Context, userID uuid.UUID, familyID uuid.UUID) (string, error) {
+	refreshToken, err := utils.GenerateRandomToken(refreshTokenSize)
+	if err != nil {
+		return "", err
+	}
+
+	if err = u.redisRepo.SetTokenFamilyCtx(ctx, u.generateTokenFamilyKey(familyID.String()), u.cfg.Server.RefreshTokenExpire); err != nil {
+		return "", err
+	}
+
+	if err = u.redisRepo.AddUserTokenFamilyCtx(ctx, u.generateUserFamiliesKey(userID.String()), familyID.String(), u.cfg.Server.RefreshTokenExpire); err != nil {
+		return "", err
+	}
+
+	if err = u.redisRepo.SetRefreshTokenCtx(ctx, u.generateRefreshTokenKey(utils.HashToken(refreshToken)), u.cfg.Server.RefreshTokenExpire, &models.RefreshToken{
+		FamilyID:  familyID,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(time.Second * time.Duration(u.cfg.Server.RefreshTokenExpire)),
+	}); err != nil {
+		return "", err
+	}
+
+	return refreshToken, nil
+}
+
+func (u *authUC) generateAWSMinioURL(bucket string, key string) string {
+	return fmt.Sprintf("%s/minio/%s/%s", u.cfg.AWS.MinioEndpoint, bucket, key)
+}
+
+func (u *authUC) loginMaxAttempts() int {
+	if u.cfg.Lockout.MaxAttempts <= 0 {
+		return defaultLoginMaxAttempts
+	}
+	return u.cfg.Lockout.MaxAttempts
+}
+
+func (u *authUC) loginIPMaxAttempts() int {
+	if u.cfg.Lockout.IPMaxAttempts <= 0 {
+		return defaultLoginIPMaxAttempts
+	}
+	return u.cfg.Lockout.IPMaxAttempts
+}
+
+func (u *authUC) deletionGracePeriod() time.Duration {
+	return time.Duration(u.cfg.Deletion.GracePeriod) * time.Second
+}
+
+// Configured users search word similarity threshold
+func (u *authUC) searchSimilarity() float64 {
+	if u.cfg.UserSearch.Similarity <= 0 || u.cfg.UserSearch.Similarity > 1 {
+		return defaultSearchSimilarity
+	}
+	return u.cfg.UserSearch.Similarity
 }
@@ -2,24 +2,59 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
 	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/dgrijalva/jwt-go"
+	"github.com/go-redis/redis/v8"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
 	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/AleksK1NG/api-mc/config"
+	auditMock "github.com/AleksK1NG/api-mc/internal/audit/mock"
 	"github.com/AleksK1NG/api-mc/internal/auth/mock"
 	"github.com/AleksK1NG/api-mc/internal/models"
+	sessionMock "github.com/AleksK1NG/api-mc/internal/session/mock"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/jwtkeys"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/mailer"
+	"github.com/AleksK1NG/api-mc/pkg/oidc"
+	"github.com/AleksK1NG/api-mc/pkg/passwordhash"
+	"github.com/AleksK1NG/api-mc/pkg/totp"
 	"github.com/AleksK1NG/api-mc/pkg/utils"
 )
 
+func newTestHasher(t *testing.T, cfg *config.Config) passwordhash.Hasher {
+	t.Helper()
+
+	hasher, err := passwordhash.New(cfg.PasswordHash)
+	require.NoError(t, err)
+	return hasher
+}
+
 func TestAuthUC_Register(t *testing.T) {
 	t.Parallel()
 
@@ -40,7 +75,11 @@ func TestAuthUC_Register(t *testing.T) {
 
 	apiLogger := logger.NewApiLogger(cfg)
 	mockAuthRepo := mock.NewMockRepository(ctrl)
-	authUC := NewAuthUseCase(cfg, mockAuthRepo, nil, nil, apiLogger)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	memoryMailer := mailer.NewMemoryMailer()
+	jwtKeys, err := jwtkeys.NewKeySet(cfg)
+	require.NoError(t, err)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, memoryMailer, jwtKeys, newTestHasher(t, cfg), nil, apiLogger)
 
 	user := &models.User{
 		Password: "123456",
@@ -53,11 +92,45 @@ func TestAuthUC_Register(t *testing.T) {
 
 	mockAuthRepo.EXPECT().FindByEmail(ctxWithTrace, gomock.Eq(user)).Return(nil, sql.ErrNoRows)
 	mockAuthRepo.EXPECT().Register(ctxWithTrace, gomock.Eq(user)).Return(user, nil)
+	mockRedisRepo.EXPECT().SetTokenFamilyCtx(ctxWithTrace, gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+	mockRedisRepo.EXPECT().AddUserTokenFamilyCtx(ctxWithTrace, gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+	mockRedisRepo.EXPECT().SetRefreshTokenCtx(ctxWithTrace, gomock.Any(), cfg.Server.RefreshTokenExpire, gomock.Any()).Return(nil)
+	mockRedisRepo.EXPECT().SetUserTokenCtx(ctxWithTrace, gomock.Any(), cfg.Server.EmailVerificationExpire, gomock.Any()).Return(nil)
 
 	createdUSer, err := authUC.Register(ctx, user)
 	require.NoError(t, err)
 	require.NotNil(t, createdUSer)
 	require.Nil(t, err)
+
+	msg, ok := memoryMailer.LastMessage()
+	require.True(t, ok)
+	require.Equal(t, []string{user.Email}, msg.To)
+
+	t.Run("Role is ignored", func(t *testing.T) {
+		role := "admin"
+		userWithRole := &models.User{
+			Password: "123456",
+			Email:    "admin@gmail.com",
+			Role:     &role,
+		}
+
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows)
+		mockAuthRepo.EXPECT().Register(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, user *models.User) (*models.User, error) {
+				require.Nil(t, user.Role)
+				defaultRole := "user"
+				return &models.User{UserID: uuid.New(), Email: user.Email, Role: &defaultRole}, nil
+			},
+		)
+		mockRedisRepo.EXPECT().SetTokenFamilyCtx(gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+		mockRedisRepo.EXPECT().AddUserTokenFamilyCtx(gomock.Any(), gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+		mockRedisRepo.EXPECT().SetRefreshTokenCtx(gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire, gomock.Any()).Return(nil)
+		mockRedisRepo.EXPECT().SetUserTokenCtx(gomock.Any(), gomock.Any(), cfg.Server.EmailVerificationExpire, gomock.Any()).Return(nil)
+
+		createdUser, err := authUC.Register(context.Background(), userWithRole)
+		require.NoError(t, err)
+		require.Equal(t, "user", *createdUser.User.Role)
+	})
 }
 
 func TestAuthUC_Update(t *testing.T) {
@@ -82,7 +155,9 @@ func TestAuthUC_Update(t *testing.T) {
 
 	mockAuthRepo := mock.NewMockRepository(ctrl)
 	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
-	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, apiLogger)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, newTestHasher(t, cfg), mockAuditUC, apiLogger)
 
 	user := &models.User{
 		Password: "123456",
@@ -94,6 +169,7 @@ func TestAuthUC_Update(t *testing.T) {
 	span, ctxWithTrace := opentracing.StartSpanFromContext(ctx, "authUC.Update")
 	defer span.Finish()
 
+	mockAuthRepo.EXPECT().GetByID(ctxWithTrace, user.UserID).Return(&models.User{Email: user.Email}, nil)
 	mockAuthRepo.EXPECT().Update(ctxWithTrace, gomock.Eq(user)).Return(user, nil)
 	mockRedisRepo.EXPECT().DeleteUserCtx(ctxWithTrace, key).Return(nil)
 
@@ -125,7 +201,9 @@ func TestAuthUC_Delete(t *testing.T) {
 
 	mockAuthRepo := mock.NewMockRepository(ctrl)
 	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
-	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, apiLogger)
+	mockSessRepo := sessionMock.NewMockSessRepository(ctrl)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, mockSessRepo, nil, nil, newTestHasher(t, cfg), mockAuditUC, apiLogger)
 
 	user := &models.User{
 		Password: "123456",
@@ -138,13 +216,118 @@ func TestAuthUC_Delete(t *testing.T) {
 	defer span.Finish()
 
 	mockAuthRepo.EXPECT().Delete(ctxWithTrace, gomock.Eq(user.UserID)).Return(nil)
+	mockSessRepo.EXPECT().DeleteAllByUserID(ctxWithTrace, user.UserID).Return(nil)
 	mockRedisRepo.EXPECT().DeleteUserCtx(ctxWithTrace, key).Return(nil)
+	mockRedisRepo.EXPECT().DeletePermissionsCtx(ctxWithTrace, fmt.Sprintf("%s: %s", permissionsPrefix, user.UserID)).Return(nil)
+	mockAuditUC.EXPECT().Record(ctxWithTrace, gomock.Any()).DoAndReturn(func(ctx context.Context, event *models.AuditEvent) error {
+		require.Equal(t, models.AuditActionDelete, event.Action)
+		require.Equal(t, user.UserID.String(), event.TargetID)
+		return nil
+	})
 
 	err := authUC.Delete(ctx, user.UserID)
 	require.NoError(t, err)
 	require.Nil(t, err)
 }
 
+func TestAuthUC_Restore(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			JwtSecretKey: "secret",
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+		Deletion: config.Deletion{
+			GracePeriod: 3600,
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, newTestHasher(t, cfg), mockAuditUC, apiLogger)
+
+	user := &models.User{UserID: uuid.New(), Email: "email@gmail.com"}
+	key := fmt.Sprintf("%s: %s", basePrefix, user.UserID)
+
+	t.Run("Restore", func(t *testing.T) {
+		mockAuthRepo.EXPECT().Restore(gomock.Any(), user.UserID, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, userID uuid.UUID, deletedAfter time.Time) error {
+				require.WithinDuration(t, time.Now().Add(-time.Hour), deletedAfter, time.Minute)
+				return nil
+			})
+		mockRedisRepo.EXPECT().GetByIDCtx(gomock.Any(), key).Return(nil, nil)
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil)
+		mockRedisRepo.EXPECT().SetUserCtx(gomock.Any(), key, cacheDuration, user).Return(nil)
+
+		restored, err := authUC.Restore(context.Background(), user.UserID)
+		require.NoError(t, err)
+		require.Equal(t, user.UserID, restored.UserID)
+	})
+
+	t.Run("Grace period is over", func(t *testing.T) {
+		mockAuthRepo.EXPECT().Restore(gomock.Any(), user.UserID, gomock.Any()).Return(errors.Wrap(sql.ErrNoRows, "authRepo.Restore.rowsAffected"))
+
+		restored, err := authUC.Restore(context.Background(), user.UserID)
+		require.Nil(t, restored)
+		require.Equal(t, http.StatusNotFound, httpErrors.ParseErrors(err).Status())
+	})
+}
+
+func TestAuthUC_PurgeDeletedUsers(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Logger: config.Logger{
+			Development: true,
+			Encoding:    "json",
+		},
+		Deletion: config.Deletion{
+			GracePeriod:    3600,
+			PurgeBatchSize: 10,
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, nil, nil, nil, nil, nil, newTestHasher(t, cfg), nil, apiLogger)
+
+	t.Run("Anonymize", func(t *testing.T) {
+		mockAuthRepo.EXPECT().AnonymizeDeletedUsers(gomock.Any(), gomock.Any(), 10).Return(int64(3), nil)
+
+		purged, err := authUC.PurgeDeletedUsers(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, int64(3), purged)
+	})
+
+	t.Run("Hard delete", func(t *testing.T) {
+		deleteCfg := *cfg
+		deleteCfg.Deletion.PurgeMode = purgeModeDelete
+		deleteUC := NewAuthUseCase(&deleteCfg, mockAuthRepo, nil, nil, nil, nil, nil, newTestHasher(t, &deleteCfg), nil, apiLogger)
+
+		mockAuthRepo.EXPECT().HardDeleteDeletedUsers(gomock.Any(), gomock.Any(), 10).Return(int64(2), nil)
+		mockAuthRepo.EXPECT().AnonymizeDeletedUsers(gomock.Any(), gomock.Any(), 10).Return(int64(1), nil)
+
+		purged, err := deleteUC.PurgeDeletedUsers(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, int64(3), purged)
+	})
+}
+
 func TestAuthUC_GetByID(t *testing.T) {
 	t.Parallel()
 
@@ -166,7 +349,7 @@ func TestAuthUC_GetByID(t *testing.T) {
 	apiLogger := logger.NewApiLogger(cfg)
 	mockAuthRepo := mock.NewMockRepository(ctrl)
 	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
-	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, apiLogger)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, newTestHasher(t, cfg), nil, apiLogger)
 
 	user := &models.User{
 		Password: "123456",
@@ -209,7 +392,7 @@ func TestAuthUC_FindByName(t *testing.T) {
 	apiLogger := logger.NewApiLogger(cfg)
 	mockAuthRepo := mock.NewMockRepository(ctrl)
 	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
-	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, apiLogger)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, newTestHasher(t, cfg), nil, apiLogger)
 
 	userName := "name"
 	query := &utils.PaginationQuery{
@@ -223,7 +406,7 @@ func TestAuthUC_FindByName(t *testing.T) {
 
 	usersList := &models.UsersList{}
 
-	mockAuthRepo.EXPECT().FindByName(ctxWithTrace, gomock.Eq(userName), query).Return(usersList, nil)
+	mockAuthRepo.EXPECT().FindByName(ctxWithTrace, gomock.Eq(userName), defaultSearchSimilarity, query).Return(usersList, nil)
 
 	userList, err := authUC.FindByName(ctx, userName, query)
 	require.NoError(t, err)
@@ -231,6 +414,45 @@ func TestAuthUC_FindByName(t *testing.T) {
 	require.NotNil(t, userList)
 }
 
+func TestAuthUC_FindSuggestions(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		UserSearch: config.UserSearch{
+			Similarity: 0.4,
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, nil, nil, nil, nil, nil, newTestHasher(t, cfg), nil, apiLogger)
+
+	ctx := context.Background()
+	span, ctxWithTrace := opentracing.StartSpanFromContext(ctx, "authUC.FindSuggestions")
+	defer span.Finish()
+
+	suggestions := []*models.UserSuggestion{{UserID: uuid.New(), FirstName: "Alex", LastName: "Bryksin"}}
+
+	t.Run("Default limit", func(t *testing.T) {
+		mockAuthRepo.EXPECT().FindSuggestions(ctxWithTrace, "alx", 0.4, defaultSuggestionsLimit).Return(suggestions, nil)
+
+		result, err := authUC.FindSuggestions(ctx, "alx", 0)
+		require.NoError(t, err)
+		require.Equal(t, suggestions, result)
+	})
+
+	t.Run("Max limit", func(t *testing.T) {
+		mockAuthRepo.EXPECT().FindSuggestions(ctxWithTrace, "alx", 0.4, maxSuggestionsLimit).Return(suggestions, nil)
+
+		result, err := authUC.FindSuggestions(ctx, "alx", 1000)
+		require.NoError(t, err)
+		require.Equal(t, suggestions, result)
+	})
+}
+
 func TestAuthUC_GetUsers(t *testing.T) {
 	t.Parallel()
 
@@ -252,7 +474,7 @@ func TestAuthUC_GetUsers(t *testing.T) {
 	apiLogger := logger.NewApiLogger(cfg)
 	mockAuthRepo := mock.NewMockRepository(ctrl)
 	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
-	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, apiLogger)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, newTestHasher(t, cfg), nil, apiLogger)
 
 	query := &utils.PaginationQuery{
 		Size:    10,
@@ -289,12 +511,21 @@ func TestAuthUC_Login(t *testing.T) {
 			DisableStacktrace: false,
 			Encoding:          "json",
 		},
+		Lockout: config.Lockout{
+			MaxAttempts:    5,
+			IPMaxAttempts:  100,
+			AttemptsWindow: 3600,
+		},
 	}
 
 	apiLogger := logger.NewApiLogger(cfg)
 	mockAuthRepo := mock.NewMockRepository(ctrl)
 	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
-	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, apiLogger)
+	jwtKeys, err := jwtkeys.NewKeySet(cfg)
+	require.NoError(t, err)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, jwtKeys, newTestHasher(t, cfg), mockAuditUC, apiLogger)
 
 	ctx := context.Background()
 	span, ctxWithTrace := opentracing.StartSpanFromContext(ctx, "authUC.Login")
@@ -313,12 +544,143 @@ func TestAuthUC_Login(t *testing.T) {
 		Password: string(hashPassword),
 	}
 
+	ipAddress := "127.0.0.1"
+
+	mockRedisRepo.EXPECT().GetLoginAttemptsCtx(ctxWithTrace, fmt.Sprintf("%s: %s", ipAttemptsPrefix, ipAddress)).Return(int64(0), time.Duration(0), nil)
 	mockAuthRepo.EXPECT().FindByEmail(ctxWithTrace, gomock.Eq(user)).Return(mockUser, nil)
+	mockRedisRepo.EXPECT().DeleteLoginAttemptsCtx(ctxWithTrace, fmt.Sprintf("%s: %s", emailAttemptsPrefix, mockUser.Email)).Return(nil)
+	mockRedisRepo.EXPECT().SetTokenFamilyCtx(ctxWithTrace, gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+	mockRedisRepo.EXPECT().AddUserTokenFamilyCtx(ctxWithTrace, gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+	mockRedisRepo.EXPECT().SetRefreshTokenCtx(ctxWithTrace, gomock.Any(), cfg.Server.RefreshTokenExpire, gomock.Any()).Return(nil)
 
-	userWithToken, err := authUC.Login(ctx, user)
+	userWithToken, err := authUC.Login(ctx, user, ipAddress)
 	require.NoError(t, err)
 	require.Nil(t, err)
 	require.NotNil(t, userWithToken)
+	require.NotEmpty(t, userWithToken.RefreshToken)
+
+	t.Run("Default lockout limits", func(t *testing.T) {
+		defaultCfg := *cfg
+		defaultCfg.Lockout = config.Lockout{}
+		defaultAuthUC := NewAuthUseCase(&defaultCfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, jwtKeys, newTestHasher(t, &defaultCfg), mockAuditUC, apiLogger)
+
+		mockRedisRepo.EXPECT().GetLoginAttemptsCtx(gomock.Any(), gomock.Any()).Return(int64(1), time.Minute, nil)
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Eq(user)).Return(&models.User{Email: user.Email, Password: string(hashPassword)}, nil)
+		mockRedisRepo.EXPECT().DeleteLoginAttemptsCtx(gomock.Any(), gomock.Any()).Return(nil)
+		mockRedisRepo.EXPECT().SetTokenFamilyCtx(gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+		mockRedisRepo.EXPECT().AddUserTokenFamilyCtx(gomock.Any(), gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+		mockRedisRepo.EXPECT().SetRefreshTokenCtx(gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire, gomock.Any()).Return(nil)
+
+		userWithToken, err := defaultAuthUC.Login(context.Background(), user, ipAddress)
+		require.NoError(t, err)
+		require.NotNil(t, userWithToken)
+	})
+}
+
+func TestAuthUC_RefreshTokens(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			JwtSecretKey:       "secret",
+			AccessTokenExpire:  60,
+			RefreshTokenExpire: 3600,
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	apiLogger.InitLogger()
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	jwtKeys, err := jwtkeys.NewKeySet(cfg)
+	require.NoError(t, err)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, jwtKeys, newTestHasher(t, cfg), nil, apiLogger)
+
+	refreshToken := "refresh token"
+	tokenKey := fmt.Sprintf("%s: %s", refreshTokenPrefix, utils.HashToken(refreshToken))
+
+	t.Run("Rotate", func(t *testing.T) {
+		user := &models.User{
+			UserID: uuid.New(),
+			Email:  "email@gmail.com",
+		}
+		storedToken := &models.RefreshToken{
+			FamilyID: uuid.New(),
+			UserID:   user.UserID,
+		}
+		familyKey := fmt.Sprintf("%s: %s", tokenFamilyPrefix, storedToken.FamilyID)
+
+		mockRedisRepo.EXPECT().GetRefreshTokenCtx(gomock.Any(), tokenKey).Return(storedToken, nil)
+		mockRedisRepo.EXPECT().ExistsTokenFamilyCtx(gomock.Any(), familyKey).Return(true, nil)
+		mockRedisRepo.EXPECT().MarkRefreshTokenUsedCtx(gomock.Any(), tokenKey).Return(true, nil)
+		mockRedisRepo.EXPECT().GetByIDCtx(gomock.Any(), gomock.Any()).Return(user, nil)
+		mockRedisRepo.EXPECT().SetTokenFamilyCtx(gomock.Any(), familyKey, cfg.Server.RefreshTokenExpire).Return(nil)
+		mockRedisRepo.EXPECT().AddUserTokenFamilyCtx(gomock.Any(), gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+		mockRedisRepo.EXPECT().SetRefreshTokenCtx(gomock.Any(), gomock.Not(tokenKey), cfg.Server.RefreshTokenExpire, gomock.Any()).Return(nil)
+
+		userWithToken, err := authUC.RefreshTokens(context.Background(), refreshToken)
+		require.NoError(t, err)
+		require.NotNil(t, userWithToken)
+		require.NotEmpty(t, userWithToken.Token)
+		require.NotEqual(t, refreshToken, userWithToken.RefreshToken)
+	})
+
+	t.Run("Reuse revokes family", func(t *testing.T) {
+		storedToken := &models.RefreshToken{
+			FamilyID: uuid.New(),
+			UserID:   uuid.New(),
+			Used:     true,
+		}
+		familyKey := fmt.Sprintf("%s: %s", tokenFamilyPrefix, storedToken.FamilyID)
+
+		mockRedisRepo.EXPECT().GetRefreshTokenCtx(gomock.Any(), tokenKey).Return(storedToken, nil)
+		mockRedisRepo.EXPECT().DeleteTokenFamilyCtx(gomock.Any(), familyKey).Return(nil)
+
+		userWithToken, err := authUC.RefreshTokens(context.Background(), refreshToken)
+		require.Error(t, err)
+		require.Nil(t, userWithToken)
+	})
+
+	t.Run("Concurrent use revokes family", func(t *testing.T) {
+		storedToken := &models.RefreshToken{
+			FamilyID: uuid.New(),
+			UserID:   uuid.New(),
+		}
+		familyKey := fmt.Sprintf("%s: %s", tokenFamilyPrefix, storedToken.FamilyID)
+
+		mockRedisRepo.EXPECT().GetRefreshTokenCtx(gomock.Any(), tokenKey).Return(storedToken, nil)
+		mockRedisRepo.EXPECT().ExistsTokenFamilyCtx(gomock.Any(), familyKey).Return(true, nil)
+		mockRedisRepo.EXPECT().MarkRefreshTokenUsedCtx(gomock.Any(), tokenKey).Return(false, nil)
+		mockRedisRepo.EXPECT().DeleteTokenFamilyCtx(gomock.Any(), familyKey).Return(nil)
+
+		userWithToken, err := authUC.RefreshTokens(context.Background(), refreshToken)
+		require.Error(t, err)
+		require.Nil(t, userWithToken)
+	})
+
+	t.Run("Revoked family", func(t *testing.T) {
+		storedToken := &models.RefreshToken{
+			FamilyID: uuid.New(),
+			UserID:   uuid.New(),
+		}
+		familyKey := fmt.Sprintf("%s: %s", tokenFamilyPrefix, storedToken.FamilyID)
+
+		mockRedisRepo.EXPECT().GetRefreshTokenCtx(gomock.Any(), tokenKey).Return(storedToken, nil)
+		mockRedisRepo.EXPECT().ExistsTokenFamilyCtx(gomock.Any(), familyKey).Return(false, nil)
+
+		userWithToken, err := authUC.RefreshTokens(context.Background(), refreshToken)
+		require.Error(t, err)
+		require.Nil(t, userWithToken)
+	})
 }
 
 func TestAuthUC_UploadAvatar(t *testing.T) {
@@ -343,7 +705,7 @@ func TestAuthUC_UploadAvatar(t *testing.T) {
 	mockAuthRepo := mock.NewMockRepository(ctrl)
 	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
 	mockAWSRepo := mock.NewMockAWSRepository(ctrl)
-	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, mockAWSRepo, apiLogger)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, mockAWSRepo, nil, nil, nil, newTestHasher(t, cfg), nil, apiLogger)
 
 	ctx := context.Background()
 	span, ctxWithTrace := opentracing.StartSpanFromContext(ctx, "authUC.UploadAvatar")
@@ -367,3 +729,1676 @@ func TestAuthUC_UploadAvatar(t *testing.T) {
 	require.Nil(t, err)
 	require.NotNil(t, updatedUser)
 }
+
+func TestAuthUC_ForgotPassword(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			JwtSecretKey:        "secret",
+			BaseURL:             "http://localhost:5000",
+			PasswordResetExpire: 3600,
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	apiLogger.InitLogger()
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	memoryMailer := mailer.NewMemoryMailer()
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, memoryMailer, nil, newTestHasher(t, cfg), nil, apiLogger)
+
+	t.Run("Send reset link", func(t *testing.T) {
+		user := &models.User{
+			UserID: uuid.New(),
+			Email:  "email@gmail.com",
+		}
+
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Eq(&models.User{Email: user.Email})).Return(user, nil)
+		mockRedisRepo.EXPECT().SetUserTokenCtx(gomock.Any(), gomock.Any(), cfg.Server.PasswordResetExpire, gomock.Eq(&models.UserToken{
+			UserID: user.UserID,
+			Email:  user.Email,
+		})).Return(nil)
+
+		err := authUC.ForgotPassword(context.Background(), " Email@gmail.com ")
+		require.NoError(t, err)
+
+		msg, ok := memoryMailer.LastMessage()
+		require.True(t, ok)
+		require.Equal(t, []string{user.Email}, msg.To)
+		require.Contains(t, msg.Body, cfg.Server.BaseURL+"/reset-password?token=")
+	})
+
+	t.Run("Unknown email", func(t *testing.T) {
+		sent := len(memoryMailer.Messages())
+
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows)
+
+		err := authUC.ForgotPassword(context.Background(), "unknown@gmail.com")
+		require.NoError(t, err)
+		require.Len(t, memoryMailer.Messages(), sent)
+	})
+}
+
+func TestAuthUC_ResetPassword(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			JwtSecretKey: "secret",
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	mockSessRepo := sessionMock.NewMockSessRepository(ctrl)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, mockSessRepo, nil, nil, newTestHasher(t, cfg), mockAuditUC, apiLogger)
+
+	resetToken := "reset token"
+	resetKey := fmt.Sprintf("%s: %s", passwordResetPrefix, utils.HashToken(resetToken))
+
+	t.Run("Reset", func(t *testing.T) {
+		userToken := &models.UserToken{
+			UserID: uuid.New(),
+			Email:  "email@gmail.com",
+		}
+
+		mockRedisRepo.EXPECT().ConsumeUserTokenCtx(gomock.Any(), resetKey).Return(userToken, nil)
+		mockAuthRepo.EXPECT().UpdatePassword(gomock.Any(), userToken.UserID, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, userID uuid.UUID, password string) error {
+				return bcrypt.CompareHashAndPassword([]byte(password), []byte("new password"))
+			},
+		)
+		familyID := uuid.New().String()
+		familiesKey := fmt.Sprintf("%s: %s", userFamiliesPrefix, userToken.UserID)
+		mockSessRepo.EXPECT().DeleteAllByUserID(gomock.Any(), userToken.UserID).Return(nil)
+		mockRedisRepo.EXPECT().GetUserTokenFamiliesCtx(gomock.Any(), familiesKey).Return([]string{familyID}, nil)
+		mockRedisRepo.EXPECT().DeleteTokenFamilyCtx(gomock.Any(), fmt.Sprintf("%s: %s", tokenFamilyPrefix, familyID)).Return(nil)
+		mockRedisRepo.EXPECT().RemoveUserTokenFamiliesCtx(gomock.Any(), familiesKey, []string{familyID}).Return(nil)
+		mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), gomock.Any()).Return(nil)
+
+		err := authUC.ResetPassword(context.Background(), resetToken, "new password")
+		require.NoError(t, err)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		mockRedisRepo.EXPECT().ConsumeUserTokenCtx(gomock.Any(), resetKey).Return(nil, redis.Nil)
+
+		err := authUC.ResetPassword(context.Background(), resetToken, "new password")
+		require.Error(t, err)
+	})
+}
+
+func TestAuthUC_VerifyEmail(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			JwtSecretKey: "secret",
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, newTestHasher(t, cfg), nil, apiLogger)
+
+	verifyToken := "verify token"
+	verifyKey := fmt.Sprintf("%s: %s", emailVerifyPrefix, utils.HashToken(verifyToken))
+
+	t.Run("Verify", func(t *testing.T) {
+		userToken := &models.UserToken{
+			UserID: uuid.New(),
+			Email:  "email@gmail.com",
+		}
+
+		mockRedisRepo.EXPECT().ConsumeUserTokenCtx(gomock.Any(), verifyKey).Return(userToken, nil)
+		mockAuthRepo.EXPECT().VerifyEmail(gomock.Any(), userToken.UserID, userToken.Email).Return(nil)
+		mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), fmt.Sprintf("%s: %s", basePrefix, userToken.UserID.String())).Return(nil)
+
+		err := authUC.VerifyEmail(context.Background(), verifyToken)
+		require.NoError(t, err)
+	})
+
+	t.Run("Email changed", func(t *testing.T) {
+		userToken := &models.UserToken{
+			UserID: uuid.New(),
+			Email:  "old@gmail.com",
+		}
+
+		mockRedisRepo.EXPECT().ConsumeUserTokenCtx(gomock.Any(), verifyKey).Return(userToken, nil)
+		mockAuthRepo.EXPECT().VerifyEmail(gomock.Any(), userToken.UserID, userToken.Email).Return(sql.ErrNoRows)
+
+		err := authUC.VerifyEmail(context.Background(), verifyToken)
+		require.Error(t, err)
+	})
+}
+
+func TestAuthUC_ResendVerification(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			JwtSecretKey:            "secret",
+			EmailVerificationExpire: 86400,
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	memoryMailer := mailer.NewMemoryMailer()
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, memoryMailer, nil, newTestHasher(t, cfg), nil, apiLogger)
+
+	t.Run("Resend", func(t *testing.T) {
+		user := &models.User{
+			UserID: uuid.New(),
+			Email:  "email@gmail.com",
+		}
+
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Eq(&models.User{Email: user.Email})).Return(user, nil)
+		mockRedisRepo.EXPECT().SetUserTokenCtx(gomock.Any(), gomock.Any(), cfg.Server.EmailVerificationExpire, gomock.Eq(&models.UserToken{
+			UserID: user.UserID,
+			Email:  user.Email,
+		})).Return(nil)
+
+		err := authUC.ResendVerification(context.Background(), user.Email)
+		require.NoError(t, err)
+		require.Len(t, memoryMailer.Messages(), 1)
+	})
+
+	t.Run("Already verified", func(t *testing.T) {
+		verifiedAt := time.Now()
+		user := &models.User{
+			UserID:     uuid.New(),
+			Email:      "verified@gmail.com",
+			VerifiedAt: &verifiedAt,
+		}
+
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Eq(&models.User{Email: user.Email})).Return(user, nil)
+
+		err := authUC.ResendVerification(context.Background(), user.Email)
+		require.NoError(t, err)
+		require.Len(t, memoryMailer.Messages(), 1)
+	})
+}
+
+func TestAuthUC_LoginTwoFactor(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			JwtSecretKey: "secret",
+		},
+		TwoFactor: config.TwoFactor{
+			ChallengeExpire: 300,
+		},
+		Lockout: config.Lockout{
+			MaxAttempts:    5,
+			IPMaxAttempts:  100,
+			AttemptsWindow: 3600,
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	apiLogger.InitLogger()
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	jwtKeys, err := jwtkeys.NewKeySet(cfg)
+	require.NoError(t, err)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, jwtKeys, newTestHasher(t, cfg), mockAuditUC, apiLogger)
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	confirmedAt := time.Now()
+
+	t.Run("Login returns challenge", func(t *testing.T) {
+		user := &models.User{
+			Password: "123456",
+			Email:    "email@gmail.com",
+		}
+		hashPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+		require.NoError(t, err)
+
+		mockUser := &models.User{
+			UserID:           uuid.New(),
+			Email:            user.Email,
+			Password:         string(hashPassword),
+			TwoFactorEnabled: true,
+		}
+
+		mockRedisRepo.EXPECT().GetLoginAttemptsCtx(gomock.Any(), gomock.Any()).Return(int64(0), time.Duration(0), nil)
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Eq(user)).Return(mockUser, nil)
+		mockRedisRepo.EXPECT().DeleteLoginAttemptsCtx(gomock.Any(), gomock.Any()).Return(nil)
+		mockRedisRepo.EXPECT().SetUserTokenCtx(gomock.Any(), gomock.Any(), cfg.TwoFactor.ChallengeExpire, gomock.Eq(&models.UserToken{
+			UserID: mockUser.UserID,
+			Email:  mockUser.Email,
+		})).Return(nil)
+
+		userWithToken, err := authUC.Login(context.Background(), user, "127.0.0.1")
+		require.NoError(t, err)
+		require.True(t, userWithToken.TwoFactorRequired)
+		require.NotEmpty(t, userWithToken.Challenge)
+		require.Nil(t, userWithToken.User)
+		require.Empty(t, userWithToken.Token)
+	})
+
+	t.Run("TOTP code", func(t *testing.T) {
+		userID := uuid.New()
+		challenge := "challenge"
+		code, err := totp.GenerateCode(secret, totp.Step(time.Now()))
+		require.NoError(t, err)
+
+		mockRedisRepo.EXPECT().ConsumeUserTokenCtx(gomock.Any(), fmt.Sprintf("%s: %s", challengePrefix, utils.HashToken(challenge))).Return(&models.UserToken{UserID: userID}, nil)
+		mockAuthRepo.EXPECT().GetTOTP(gomock.Any(), userID).Return(&models.UserTOTP{UserID: userID, Secret: secret, ConfirmedAt: &confirmedAt}, nil)
+		mockAuthRepo.EXPECT().UpdateTOTPStep(gomock.Any(), userID, gomock.Any()).Return(nil)
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), userID).Return(&models.User{UserID: userID}, nil)
+		mockRedisRepo.EXPECT().SetTokenFamilyCtx(gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+		mockRedisRepo.EXPECT().AddUserTokenFamilyCtx(gomock.Any(), gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+		mockRedisRepo.EXPECT().SetRefreshTokenCtx(gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire, gomock.Any()).Return(nil)
+		mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event *models.AuditEvent) error {
+			require.Equal(t, models.AuditActionLogin, event.Action)
+			require.Equal(t, userID.String(), event.TargetID)
+			return nil
+		})
+
+		userWithToken, err := authUC.LoginTwoFactor(context.Background(), challenge, code)
+		require.NoError(t, err)
+		require.NotNil(t, userWithToken.User)
+		require.NotEmpty(t, userWithToken.Token)
+	})
+
+	t.Run("Recovery code", func(t *testing.T) {
+		userID := uuid.New()
+		challenge := "challenge"
+		recoveryCode := "ABCDE-12345"
+
+		mockRedisRepo.EXPECT().ConsumeUserTokenCtx(gomock.Any(), gomock.Any()).Return(&models.UserToken{UserID: userID}, nil)
+		mockAuthRepo.EXPECT().GetTOTP(gomock.Any(), userID).Return(&models.UserTOTP{UserID: userID, Secret: secret, ConfirmedAt: &confirmedAt}, nil)
+		mockAuthRepo.EXPECT().UseRecoveryCode(gomock.Any(), userID, utils.HashToken("abcde12345")).Return(nil)
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), userID).Return(&models.User{UserID: userID}, nil)
+		mockRedisRepo.EXPECT().SetTokenFamilyCtx(gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+		mockRedisRepo.EXPECT().AddUserTokenFamilyCtx(gomock.Any(), gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+		mockRedisRepo.EXPECT().SetRefreshTokenCtx(gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire, gomock.Any()).Return(nil)
+		mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event *models.AuditEvent) error {
+			require.Equal(t, models.AuditActionLogin, event.Action)
+			require.Equal(t, userID.String(), event.TargetID)
+			return nil
+		})
+
+		userWithToken, err := authUC.LoginTwoFactor(context.Background(), challenge, recoveryCode)
+		require.NoError(t, err)
+		require.NotNil(t, userWithToken.User)
+	})
+
+	t.Run("Invalid code", func(t *testing.T) {
+		userID := uuid.New()
+
+		mockRedisRepo.EXPECT().ConsumeUserTokenCtx(gomock.Any(), gomock.Any()).Return(&models.UserToken{UserID: userID}, nil)
+		mockAuthRepo.EXPECT().GetTOTP(gomock.Any(), userID).Return(&models.UserTOTP{UserID: userID, Secret: secret, ConfirmedAt: &confirmedAt}, nil)
+		mockAuthRepo.EXPECT().UseRecoveryCode(gomock.Any(), userID, gomock.Any()).Return(sql.ErrNoRows)
+		mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event *models.AuditEvent) error {
+			require.Equal(t, models.AuditActionLoginFailed, event.Action)
+			require.Equal(t, userID.String(), event.TargetID)
+			return nil
+		})
+
+		userWithToken, err := authUC.LoginTwoFactor(context.Background(), "challenge", "invalid")
+		require.Error(t, err)
+		require.Nil(t, userWithToken)
+	})
+}
+
+func TestAuthUC_ConfirmTOTP(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			JwtSecretKey: "secret",
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, newTestHasher(t, cfg), nil, apiLogger)
+
+	userID := uuid.New()
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	code, err := totp.GenerateCode(secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+
+	mockAuthRepo.EXPECT().GetTOTP(gomock.Any(), userID).Return(&models.UserTOTP{UserID: userID, Secret: secret}, nil)
+	mockAuthRepo.EXPECT().UpdateTOTPStep(gomock.Any(), userID, gomock.Any()).Return(nil)
+	mockAuthRepo.EXPECT().EnableTwoFactor(gomock.Any(), userID, gomock.Len(recoveryCodesCount)).Return(nil)
+	mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), fmt.Sprintf("%s: %s", basePrefix, userID.String())).Return(nil)
+
+	recoveryCodes, err := authUC.ConfirmTOTP(context.Background(), userID, code)
+	require.NoError(t, err)
+	require.Len(t, recoveryCodes.Codes, recoveryCodesCount)
+}
+
+func TestAuthUC_LoginLockout(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			JwtSecretKey: "secret",
+		},
+		Lockout: config.Lockout{
+			MaxAttempts:    5,
+			IPMaxAttempts:  100,
+			AttemptsWindow: 3600,
+			BaseDelay:      30,
+			MaxDelay:       3600,
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	apiLogger.InitLogger()
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, newTestHasher(t, cfg), mockAuditUC, apiLogger)
+
+	ipAddress := "127.0.0.1"
+	ipKey := fmt.Sprintf("%s: %s", ipAttemptsPrefix, ipAddress)
+
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+
+	t.Run("IP limit exceeded", func(t *testing.T) {
+		mockRedisRepo.EXPECT().GetLoginAttemptsCtx(gomock.Any(), ipKey).Return(int64(100), time.Minute, nil)
+
+		userWithToken, err := authUC.Login(context.Background(), &models.User{Email: "email@gmail.com", Password: "123456"}, ipAddress)
+		require.Nil(t, userWithToken)
+		var lockoutErr httpErrors.LockoutError
+		require.True(t, errors.As(err, &lockoutErr))
+		require.Equal(t, 60, lockoutErr.RetryAfter)
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		user := &models.User{Email: "email@gmail.com", Password: "wrong"}
+		mockUser := &models.User{UserID: uuid.New(), Email: user.Email, Password: string(hashPassword)}
+
+		mockRedisRepo.EXPECT().GetLoginAttemptsCtx(gomock.Any(), ipKey).Return(int64(0), time.Duration(0), nil)
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Eq(user)).Return(mockUser, nil)
+		mockRedisRepo.EXPECT().IncrLoginAttemptsCtx(gomock.Any(), ipKey, cfg.Lockout.AttemptsWindow).Return(int64(1), nil)
+		mockRedisRepo.EXPECT().IncrLoginAttemptsCtx(gomock.Any(), fmt.Sprintf("%s: %s", emailAttemptsPrefix, user.Email), cfg.Lockout.AttemptsWindow).Return(int64(1), nil)
+
+		userWithToken, err := authUC.Login(context.Background(), user, ipAddress)
+		require.Nil(t, userWithToken)
+		require.Error(t, err)
+		var restErr httpErrors.RestErr
+		require.True(t, errors.As(err, &restErr))
+		require.Equal(t, http.StatusUnauthorized, restErr.Status())
+	})
+
+	t.Run("Attempts limit locks account", func(t *testing.T) {
+		user := &models.User{Email: "email@gmail.com", Password: "wrong"}
+		mockUser := &models.User{UserID: uuid.New(), Email: user.Email, Password: string(hashPassword)}
+
+		mockRedisRepo.EXPECT().GetLoginAttemptsCtx(gomock.Any(), ipKey).Return(int64(0), time.Duration(0), nil)
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Eq(user)).Return(mockUser, nil)
+		mockRedisRepo.EXPECT().IncrLoginAttemptsCtx(gomock.Any(), ipKey, cfg.Lockout.AttemptsWindow).Return(int64(6), nil)
+		mockRedisRepo.EXPECT().IncrLoginAttemptsCtx(gomock.Any(), fmt.Sprintf("%s: %s", emailAttemptsPrefix, user.Email), cfg.Lockout.AttemptsWindow).Return(int64(6), nil)
+		mockAuthRepo.EXPECT().UpdateLockedUntil(gomock.Any(), mockUser.UserID, gomock.Any()).Return(nil)
+
+		userWithToken, err := authUC.Login(context.Background(), user, ipAddress)
+		require.Nil(t, userWithToken)
+		var lockoutErr httpErrors.LockoutError
+		require.True(t, errors.As(err, &lockoutErr))
+		require.Equal(t, http.StatusTooManyRequests, lockoutErr.Status())
+		require.Equal(t, 60, lockoutErr.RetryAfter)
+	})
+
+	t.Run("Locked account", func(t *testing.T) {
+		user := &models.User{Email: "email@gmail.com", Password: "123456"}
+		lockedUntil := time.Now().Add(time.Minute)
+		mockUser := &models.User{UserID: uuid.New(), Email: user.Email, Password: string(hashPassword), LockedUntil: &lockedUntil}
+
+		mockRedisRepo.EXPECT().GetLoginAttemptsCtx(gomock.Any(), ipKey).Return(int64(0), time.Duration(0), nil)
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Eq(user)).Return(mockUser, nil)
+
+		userWithToken, err := authUC.Login(context.Background(), user, ipAddress)
+		require.Nil(t, userWithToken)
+		var lockoutErr httpErrors.LockoutError
+		require.True(t, errors.As(err, &lockoutErr))
+	})
+}
+
+func TestAuthUC_Unlock(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, newTestHasher(t, cfg), nil, apiLogger)
+
+	user := &models.User{UserID: uuid.New(), Email: "email@gmail.com"}
+
+	mockAuthRepo.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil)
+	mockAuthRepo.EXPECT().UpdateLockedUntil(gomock.Any(), user.UserID, nil).Return(nil)
+	mockRedisRepo.EXPECT().DeleteLoginAttemptsCtx(gomock.Any(), fmt.Sprintf("%s: %s", emailAttemptsPrefix, user.Email)).Return(nil)
+	mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), fmt.Sprintf("%s: %s", basePrefix, user.UserID.String())).Return(nil)
+
+	err := authUC.Unlock(context.Background(), user.UserID)
+	require.NoError(t, err)
+}
+
+func TestAuthUC_CreateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, nil, nil, nil, nil, nil, newTestHasher(t, cfg), nil, apiLogger)
+
+	t.Run("CreateAPIKey", func(t *testing.T) {
+		apiKey := &models.APIKey{
+			UserID: uuid.New(),
+			Name:   "ci",
+			Scopes: models.APIKeyScopes{models.APIKeyScopeNewsWrite},
+		}
+
+		mockAuthRepo.EXPECT().CreateAPIKey(gomock.Any(), gomock.Eq(apiKey)).DoAndReturn(
+			func(ctx context.Context, k *models.APIKey) (*models.APIKey, error) {
+				return k, nil
+			})
+
+		created, err := authUC.CreateAPIKey(context.Background(), apiKey)
+		require.NoError(t, err)
+		require.NotEmpty(t, created.Key)
+		require.True(t, strings.HasPrefix(created.Key, created.APIKey.Prefix+"."))
+		require.True(t, strings.HasPrefix(created.APIKey.Prefix, models.APIKeyPrefix))
+		require.Equal(t, utils.HashToken(created.Key), created.APIKey.KeyHash)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Minute)
+
+		created, err := authUC.CreateAPIKey(context.Background(), &models.APIKey{Name: "ci", ExpiresAt: &expiresAt})
+		require.Error(t, err)
+		require.Nil(t, created)
+	})
+}
+
+func TestAuthUC_AuthenticateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, newTestHasher(t, cfg), nil, apiLogger)
+
+	key := models.APIKeyPrefix + "01020304.secret"
+
+	t.Run("AuthenticateAPIKey", func(t *testing.T) {
+		user := &models.User{UserID: uuid.New()}
+		apiKey := &models.APIKey{APIKeyID: uuid.New(), UserID: user.UserID, Scopes: models.APIKeyScopes{models.APIKeyScopeNewsWrite}}
+
+		mockAuthRepo.EXPECT().GetAPIKeyByHash(gomock.Any(), utils.HashToken(key)).Return(apiKey, nil)
+		mockAuthRepo.EXPECT().UpdateAPIKeyLastUsed(gomock.Any(), apiKey.APIKeyID, gomock.Any()).Return(nil)
+		mockRedisRepo.EXPECT().GetByIDCtx(gomock.Any(), fmt.Sprintf("%s: %s", basePrefix, user.UserID.String())).Return(user, nil)
+
+		foundUser, foundKey, err := authUC.AuthenticateAPIKey(context.Background(), key)
+		require.NoError(t, err)
+		require.Equal(t, user, foundUser)
+		require.Equal(t, apiKey, foundKey)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Minute)
+		apiKey := &models.APIKey{APIKeyID: uuid.New(), UserID: uuid.New(), ExpiresAt: &expiresAt}
+
+		mockAuthRepo.EXPECT().GetAPIKeyByHash(gomock.Any(), utils.HashToken(key)).Return(apiKey, nil)
+
+		foundUser, foundKey, err := authUC.AuthenticateAPIKey(context.Background(), key)
+		require.Error(t, err)
+		require.Nil(t, foundUser)
+		require.Nil(t, foundKey)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockAuthRepo.EXPECT().GetAPIKeyByHash(gomock.Any(), utils.HashToken(key)).Return(nil, sql.ErrNoRows)
+
+		foundUser, foundKey, err := authUC.AuthenticateAPIKey(context.Background(), key)
+		require.Error(t, err)
+		require.Nil(t, foundUser)
+		require.Nil(t, foundKey)
+	})
+}
+
+func TestAuthUC_GetPermissions(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, newTestHasher(t, cfg), nil, apiLogger)
+
+	role := "editor"
+	user := &models.User{UserID: uuid.New(), Role: &role}
+	key := fmt.Sprintf("%s: %s", permissionsPrefix, user.UserID.String())
+
+	t.Run("Cached", func(t *testing.T) {
+		cached := &models.UserPermissions{Role: role, Permissions: []string{models.PermissionNewsPublish}}
+		mockRedisRepo.EXPECT().GetPermissionsCtx(gomock.Any(), key).Return(cached, nil)
+
+		permissions, err := authUC.GetPermissions(context.Background(), user)
+		require.NoError(t, err)
+		require.True(t, permissions.Has(models.PermissionNewsPublish))
+	})
+
+	t.Run("Role changed", func(t *testing.T) {
+		cached := &models.UserPermissions{Role: "admin", Permissions: []string{models.PermissionUsersRoles}}
+		mockRedisRepo.EXPECT().GetPermissionsCtx(gomock.Any(), key).Return(cached, nil)
+		mockAuthRepo.EXPECT().GetPermissionsByRole(gomock.Any(), role).Return([]string{models.PermissionNewsPublish, models.PermissionNewsModerate}, nil)
+		mockRedisRepo.EXPECT().SetPermissionsCtx(gomock.Any(), key, cacheDuration, gomock.Any()).Return(nil)
+
+		permissions, err := authUC.GetPermissions(context.Background(), user)
+		require.NoError(t, err)
+		require.Equal(t, role, permissions.Role)
+		require.True(t, permissions.Has(models.PermissionNewsModerate))
+		require.False(t, permissions.Has(models.PermissionUsersRoles))
+	})
+}
+
+func TestAuthUC_UpdateRole(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, newTestHasher(t, cfg), mockAuditUC, apiLogger)
+
+	userID := uuid.New()
+
+	mockAuthRepo.EXPECT().UpdateRole(gomock.Any(), userID, "moderator").Return(nil)
+	mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), fmt.Sprintf("%s: %s", basePrefix, userID.String())).Return(nil)
+	mockRedisRepo.EXPECT().DeletePermissionsCtx(gomock.Any(), fmt.Sprintf("%s: %s", permissionsPrefix, userID.String())).Return(nil)
+
+	err := authUC.UpdateRole(context.Background(), userID, "moderator")
+	require.NoError(t, err)
+}
+
+// Local OpenID provider issuing RS256 ID tokens for authorization codes
+type stubOIDCProvider struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	clientID string
+	subject  string
+	email    string
+	mu       sync.Mutex
+	codes    map[string]url.Values
+}
+
+func newStubOIDCProvider(t *testing.T, clientID string, subject string, email string) *stubOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &stubOIDCProvider{key: key, clientID: clientID, subject: subject, email: email, codes: map[string]url.Values{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(oidc.Discovery{
+			Issuer:                p.server.URL,
+			AuthorizationEndpoint: p.server.URL + "/authorize",
+			TokenEndpoint:         p.server.URL + "/token",
+			JwksURI:               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jwtkeys.JWKS{Keys: []jwtkeys.JWK{{
+			Kty: "RSA",
+			Kid: "stub-key",
+			Use: "sig",
+			Alg: jwtkeys.RS256,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", p.token)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	return p
+}
+
+// Simulate user consent, returns state and authorization code for callback
+func (p *stubOIDCProvider) authorize(t *testing.T, authURL string) (string, string) {
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	params := u.Query()
+	require.Equal(t, p.clientID, params.Get("client_id"))
+	require.Equal(t, "S256", params.Get("code_challenge_method"))
+
+	code := uuid.New().String()
+	p.mu.Lock()
+	p.codes[code] = params
+	p.mu.Unlock()
+
+	return params.Get("state"), code
+}
+
+func (p *stubOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	params, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+	if !ok || oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != params.Get("code_challenge") {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            p.server.URL,
+		"aud":            p.clientID,
+		"sub":            p.subject,
+		"email":          p.email,
+		"email_verified": true,
+		"name":           "Alex Bryksin",
+		"nonce":          params.Get("nonce"),
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Minute).Unix(),
+	})
+	token.Header["kid"] = "stub-key"
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(oidc.TokenResponse{AccessToken: "access", TokenType: "Bearer", IDToken: idToken})
+}
+
+func TestAuthUC_OIDCLogin(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := &models.User{UserID: uuid.New(), Email: "email@gmail.com", Password: "hashed"}
+	provider := newStubOIDCProvider(t, "client-id", "stub-subject", user.Email)
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			JwtSecretKey:       "secret",
+			RefreshTokenExpire: 60,
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+		OIDC: config.OIDC{
+			StateExpire: 600,
+			Providers: []config.OIDCProvider{{
+				Name:         "stub",
+				Issuer:       provider.server.URL,
+				ClientID:     "client-id",
+				ClientSecret: "client-secret",
+				RedirectURL:  "http://localhost:5000/api/v1/auth/oidc/stub/callback",
+				Scopes:       []string{"email", "profile"},
+			}},
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	jwtKeys, err := jwtkeys.NewKeySet(cfg)
+	require.NoError(t, err)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, jwtKeys, newTestHasher(t, cfg), mockAuditUC, apiLogger)
+
+	t.Run("Link existing user", func(t *testing.T) {
+		var authRequest *models.OIDCAuthRequest
+		mockRedisRepo.EXPECT().SetOIDCAuthRequestCtx(gomock.Any(), gomock.Any(), cfg.OIDC.StateExpire, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, key string, seconds int, r *models.OIDCAuthRequest) error {
+				authRequest = r
+				return nil
+			})
+
+		authURL, state, err := authUC.OIDCAuthURL(context.Background(), "stub")
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(authURL, provider.server.URL+"/authorize?"))
+
+		callbackState, code := provider.authorize(t, authURL)
+		require.Equal(t, state, callbackState)
+
+		mockRedisRepo.EXPECT().ConsumeOIDCAuthRequestCtx(gomock.Any(), fmt.Sprintf("%s: %s", oidcStatePrefix, utils.HashToken(state))).
+			DoAndReturn(func(ctx context.Context, key string) (*models.OIDCAuthRequest, error) {
+				return authRequest, nil
+			})
+		mockAuthRepo.EXPECT().GetUserIdentity(gomock.Any(), "stub", "stub-subject").Return(nil, errors.Wrap(sql.ErrNoRows, "GetContext"))
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Any()).Return(user, nil)
+		mockAuthRepo.EXPECT().CreateUserIdentity(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, identity *models.UserIdentity) (*models.UserIdentity, error) {
+				require.Equal(t, user.UserID, identity.UserID)
+				require.Equal(t, "stub-subject", identity.Subject)
+				return identity, nil
+			})
+		mockRedisRepo.EXPECT().SetTokenFamilyCtx(gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+		mockRedisRepo.EXPECT().AddUserTokenFamilyCtx(gomock.Any(), gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+		mockRedisRepo.EXPECT().SetRefreshTokenCtx(gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire, gomock.Any()).Return(nil)
+
+		userWithToken, err := authUC.OIDCLogin(context.Background(), "stub", state, code)
+		require.NoError(t, err)
+		require.Equal(t, user.UserID, userWithToken.User.UserID)
+		require.NotEmpty(t, userWithToken.Token)
+	})
+
+	t.Run("Invalid state", func(t *testing.T) {
+		mockRedisRepo.EXPECT().ConsumeOIDCAuthRequestCtx(gomock.Any(), gomock.Any()).Return(nil, redis.Nil)
+
+		_, err := authUC.OIDCLogin(context.Background(), "stub", "state", "code")
+		require.Error(t, err)
+	})
+
+	t.Run("Unknown provider", func(t *testing.T) {
+		_, _, err := authUC.OIDCAuthURL(context.Background(), "unknown")
+		require.Error(t, err)
+	})
+}
+
+func TestAuthUC_SendMagicLink(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			BaseURL: "http://localhost:5000",
+		},
+		MagicLink: config.MagicLink{
+			Expire:         900,
+			MaxRequests:    3,
+			IPMaxRequests:  30,
+			RequestsWindow: 3600,
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	memoryMailer := mailer.NewMemoryMailer()
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, memoryMailer, nil, newTestHasher(t, cfg), nil, apiLogger)
+
+	user := &models.User{UserID: uuid.New(), Email: "email@gmail.com"}
+	ipKey := fmt.Sprintf("%s: %s", magicLinkIPPrefix, "127.0.0.1")
+	emailKey := fmt.Sprintf("%s: %s", magicLinkEmailPrefix, user.Email)
+
+	t.Run("Send magic link", func(t *testing.T) {
+		mockRedisRepo.EXPECT().IncrLoginAttemptsCtx(gomock.Any(), ipKey, cfg.MagicLink.RequestsWindow).Return(int64(1), nil)
+		mockRedisRepo.EXPECT().IncrLoginAttemptsCtx(gomock.Any(), emailKey, cfg.MagicLink.RequestsWindow).Return(int64(1), nil)
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Eq(&models.User{Email: user.Email})).Return(user, nil)
+		mockRedisRepo.EXPECT().SetUserTokenCtx(gomock.Any(), gomock.Any(), cfg.MagicLink.Expire, gomock.Eq(&models.UserToken{
+			UserID: user.UserID,
+			Email:  user.Email,
+		})).Return(nil)
+
+		err := authUC.SendMagicLink(context.Background(), " Email@gmail.com ", "127.0.0.1")
+		require.NoError(t, err)
+
+		msg, ok := memoryMailer.LastMessage()
+		require.True(t, ok)
+		require.Equal(t, []string{user.Email}, msg.To)
+		require.Contains(t, msg.Body, cfg.Server.BaseURL+"/api/v1/auth/magic-link/callback?token=")
+	})
+
+	t.Run("Rate limited", func(t *testing.T) {
+		sent := len(memoryMailer.Messages())
+
+		mockRedisRepo.EXPECT().IncrLoginAttemptsCtx(gomock.Any(), ipKey, cfg.MagicLink.RequestsWindow).Return(int64(2), nil)
+		mockRedisRepo.EXPECT().IncrLoginAttemptsCtx(gomock.Any(), emailKey, cfg.MagicLink.RequestsWindow).Return(int64(4), nil)
+
+		err := authUC.SendMagicLink(context.Background(), user.Email, "127.0.0.1")
+		require.Error(t, err)
+
+		var lockoutErr httpErrors.LockoutError
+		require.True(t, errors.As(err, &lockoutErr))
+		require.Equal(t, http.StatusTooManyRequests, lockoutErr.Status())
+		require.Equal(t, cfg.MagicLink.RequestsWindow, lockoutErr.RetryAfter)
+		require.Len(t, memoryMailer.Messages(), sent)
+	})
+}
+
+func TestAuthUC_LoginMagicLink(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			JwtSecretKey:       "secret",
+			RefreshTokenExpire: 60,
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	jwtKeys, err := jwtkeys.NewKeySet(cfg)
+	require.NoError(t, err)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, jwtKeys, newTestHasher(t, cfg), mockAuditUC, apiLogger)
+
+	user := &models.User{UserID: uuid.New(), Email: "email@gmail.com", Password: "hashed"}
+	loginToken := "magic-link-token"
+	key := fmt.Sprintf("%s: %s", magicLinkPrefix, utils.HashToken(loginToken))
+
+	t.Run("Login", func(t *testing.T) {
+		mockRedisRepo.EXPECT().ConsumeUserTokenCtx(gomock.Any(), key).Return(&models.UserToken{UserID: user.UserID, Email: user.Email}, nil)
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil)
+		mockRedisRepo.EXPECT().SetTokenFamilyCtx(gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+		mockRedisRepo.EXPECT().AddUserTokenFamilyCtx(gomock.Any(), gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+		mockRedisRepo.EXPECT().SetRefreshTokenCtx(gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire, gomock.Any()).Return(nil)
+
+		userWithToken, err := authUC.LoginMagicLink(context.Background(), loginToken)
+		require.NoError(t, err)
+		require.Equal(t, user.UserID, userWithToken.User.UserID)
+		require.NotEmpty(t, userWithToken.Token)
+		require.Empty(t, userWithToken.User.Password)
+	})
+
+	t.Run("Used token", func(t *testing.T) {
+		mockRedisRepo.EXPECT().ConsumeUserTokenCtx(gomock.Any(), key).Return(nil, redis.Nil)
+
+		_, err := authUC.LoginMagicLink(context.Background(), loginToken)
+		require.Error(t, err)
+	})
+
+	t.Run("Email changed", func(t *testing.T) {
+		mockRedisRepo.EXPECT().ConsumeUserTokenCtx(gomock.Any(), key).Return(&models.UserToken{UserID: user.UserID, Email: "old@gmail.com"}, nil)
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), user.UserID).Return(&models.User{UserID: user.UserID, Email: user.Email}, nil)
+
+		_, err := authUC.LoginMagicLink(context.Background(), loginToken)
+		require.Error(t, err)
+	})
+}
+
+func TestAuthUC_UpdateEmailRejected(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, newTestHasher(t, cfg), nil, apiLogger)
+
+	user := &models.User{UserID: uuid.New(), Email: "attacker@gmail.com"}
+
+	mockAuthRepo.EXPECT().GetByID(gomock.Any(), user.UserID).Return(&models.User{UserID: user.UserID, Email: "email@gmail.com"}, nil)
+
+	_, err := authUC.Update(context.Background(), user)
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadRequest, httpErrors.ParseErrors(err).Status())
+}
+
+func TestAuthUC_UpdateFieldPermissions(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, newTestHasher(t, cfg), mockAuditUC, apiLogger)
+
+	userRole, adminRole := "user", "admin"
+	owner := &models.User{UserID: uuid.New(), Role: &userRole}
+	ownerCtx := context.WithValue(context.Background(), utils.UserCtxKey{}, owner)
+	ownerCtx = context.WithValue(ownerCtx, utils.PermissionsCtxKey{}, &models.UserPermissions{
+		Role:        userRole,
+		Permissions: []string{models.PermissionNewsPublish},
+	})
+
+	t.Run("Regular user can not change own role", func(t *testing.T) {
+		role := adminRole
+		user := &models.User{UserID: owner.UserID, FirstName: "Alex", Role: &role}
+
+		updatedUser, err := authUC.Update(ownerCtx, user)
+		require.Error(t, err)
+		require.Nil(t, updatedUser)
+		require.Equal(t, http.StatusForbidden, httpErrors.ParseErrors(err).Status())
+		require.Contains(t, httpErrors.ParseErrors(err).Error(), httpErrors.FieldUpdateDenied.Error())
+	})
+
+	t.Run("Regular user can not set avatar url", func(t *testing.T) {
+		avatar := "https://example.com/avatar.png"
+		user := &models.User{UserID: owner.UserID, Avatar: &avatar}
+
+		_, err := authUC.Update(ownerCtx, user)
+		require.Error(t, err)
+		require.Equal(t, http.StatusForbidden, httpErrors.ParseErrors(err).Status())
+	})
+
+	t.Run("Self-service payload never carries role", func(t *testing.T) {
+		input := &models.UserUpdate{}
+		err := json.Unmarshal([]byte(`{"first_name":"Alex","role":"admin"}`), input)
+		require.NoError(t, err)
+
+		user := input.ToUser(owner.UserID)
+		require.Nil(t, user.Role)
+
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), owner.UserID).Return(owner, nil)
+		mockAuthRepo.EXPECT().Update(gomock.Any(), user).Return(owner, nil)
+		mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), fmt.Sprintf("%s: %s", basePrefix, owner.UserID)).Return(nil)
+
+		updatedUser, err := authUC.Update(ownerCtx, user)
+		require.NoError(t, err)
+		require.Equal(t, userRole, *updatedUser.Role)
+	})
+
+	t.Run("Role is assigned with roles permission", func(t *testing.T) {
+		adminCtx := context.WithValue(context.Background(), utils.PermissionsCtxKey{}, &models.UserPermissions{
+			Role:        adminRole,
+			Permissions: []string{models.PermissionUsersUpdate, models.PermissionUsersRoles},
+		})
+		role := "moderator"
+		user := &models.User{UserID: owner.UserID, Role: &role}
+
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), owner.UserID).Return(owner, nil)
+		mockAuthRepo.EXPECT().UpdateRole(gomock.Any(), owner.UserID, role).Return(nil)
+		mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), fmt.Sprintf("%s: %s", basePrefix, owner.UserID)).Return(nil).Times(2)
+		mockRedisRepo.EXPECT().DeletePermissionsCtx(gomock.Any(), fmt.Sprintf("%s: %s", permissionsPrefix, owner.UserID)).Return(nil)
+		mockAuthRepo.EXPECT().Update(gomock.Any(), user).Return(&models.User{UserID: owner.UserID, Role: &role}, nil)
+
+		updatedUser, err := authUC.Update(adminCtx, user)
+		require.NoError(t, err)
+		require.Equal(t, role, *updatedUser.Role)
+	})
+}
+
+func TestAuthUC_ChangeEmail(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			BaseURL:           "http://localhost:5000",
+			EmailChangeExpire: 86400,
+			EmailRevertExpire: 604800,
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	mockSessRepo := sessionMock.NewMockSessRepository(ctrl)
+	memoryMailer := mailer.NewMemoryMailer()
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, mockSessRepo, memoryMailer, nil, newTestHasher(t, cfg), mockAuditUC, apiLogger)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	user := &models.User{UserID: uuid.New(), Email: "email@gmail.com"}
+	userKey := fmt.Sprintf("%s: %s", basePrefix, user.UserID.String())
+	familiesKey := fmt.Sprintf("%s: %s", userFamiliesPrefix, user.UserID)
+	familyID := uuid.New()
+	newEmail := "new@gmail.com"
+
+	t.Run("Send confirmation to new email", func(t *testing.T) {
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil)
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Eq(&models.User{Email: user.Email})).
+			Return(&models.User{UserID: user.UserID, Email: user.Email, Password: string(hashedPassword)}, nil)
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Eq(&models.User{Email: newEmail})).Return(nil, sql.ErrNoRows)
+		mockAuthRepo.EXPECT().SetPendingEmail(gomock.Any(), user.UserID, newEmail).Return(nil)
+		mockRedisRepo.EXPECT().SetUserTokenCtx(gomock.Any(), gomock.Any(), cfg.Server.EmailChangeExpire, gomock.Eq(&models.UserToken{
+			UserID: user.UserID,
+			Email:  newEmail,
+		})).Return(nil)
+		mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), userKey).Return(nil)
+
+		err := authUC.ChangeEmail(context.Background(), user.UserID, " New@gmail.com ", "123456")
+		require.NoError(t, err)
+
+		msg, ok := memoryMailer.LastMessage()
+		require.True(t, ok)
+		require.Equal(t, []string{newEmail}, msg.To)
+		require.Contains(t, msg.Body, cfg.Server.BaseURL+"/api/v1/auth/email/confirm?token=")
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil)
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Eq(&models.User{Email: user.Email})).
+			Return(&models.User{UserID: user.UserID, Email: user.Email, Password: string(hashedPassword)}, nil)
+
+		err := authUC.ChangeEmail(context.Background(), user.UserID, newEmail, "wrong-password")
+		require.Error(t, err)
+	})
+
+	t.Run("Confirm and notify old email", func(t *testing.T) {
+		changeToken := "change-token"
+		mockRedisRepo.EXPECT().ConsumeUserTokenCtx(gomock.Any(), fmt.Sprintf("%s: %s", emailChangePrefix, utils.HashToken(changeToken))).
+			Return(&models.UserToken{UserID: user.UserID, Email: newEmail}, nil)
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil)
+		mockRedisRepo.EXPECT().SetUserTokenCtx(gomock.Any(), gomock.Any(), cfg.Server.EmailRevertExpire, gomock.Eq(&models.UserToken{
+			UserID: user.UserID,
+			Email:  user.Email,
+		})).Return(nil)
+		mockAuthRepo.EXPECT().ConfirmPendingEmail(gomock.Any(), user.UserID, newEmail).Return(nil)
+		mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), userKey).Return(nil)
+		mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event *models.AuditEvent) error {
+			require.Equal(t, models.AuditActionEmailChange, event.Action)
+			require.Equal(t, user.UserID, event.ActorID)
+			require.Equal(t, user.UserID.String(), event.TargetID)
+			require.Equal(t, user.Email, event.Metadata["old_email"])
+			require.Equal(t, newEmail, event.Metadata["new_email"])
+			return nil
+		})
+
+		err := authUC.ConfirmEmailChange(context.Background(), changeToken)
+		require.NoError(t, err)
+
+		msg, ok := memoryMailer.LastMessage()
+		require.True(t, ok)
+		require.Equal(t, []string{user.Email}, msg.To)
+		require.Contains(t, msg.Body, cfg.Server.BaseURL+"/api/v1/auth/email/revert?token=")
+	})
+
+	t.Run("Confirm email taken by other user", func(t *testing.T) {
+		changeToken := "taken-token"
+		mockRedisRepo.EXPECT().ConsumeUserTokenCtx(gomock.Any(), fmt.Sprintf("%s: %s", emailChangePrefix, utils.HashToken(changeToken))).
+			Return(&models.UserToken{UserID: user.UserID, Email: newEmail}, nil)
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil)
+		mockRedisRepo.EXPECT().SetUserTokenCtx(gomock.Any(), gomock.Any(), cfg.Server.EmailRevertExpire, gomock.Any()).Return(nil)
+		mockAuthRepo.EXPECT().ConfirmPendingEmail(gomock.Any(), user.UserID, newEmail).
+			Return(errors.Wrap(errors.New(`ERROR: duplicate key value violates unique constraint "users_email_key" (SQLSTATE 23505)`), "authRepo.ConfirmPendingEmail.ExecContext"))
+
+		err := authUC.ConfirmEmailChange(context.Background(), changeToken)
+		require.Error(t, err)
+		require.Equal(t, http.StatusConflict, httpErrors.ParseErrors(err).Status())
+	})
+
+	t.Run("Revert", func(t *testing.T) {
+		revertToken := "revert-token"
+		mockRedisRepo.EXPECT().ConsumeUserTokenCtx(gomock.Any(), fmt.Sprintf("%s: %s", emailRevertPrefix, utils.HashToken(revertToken))).
+			Return(&models.UserToken{UserID: user.UserID, Email: user.Email}, nil)
+		mockAuthRepo.EXPECT().RevertEmail(gomock.Any(), user.UserID, user.Email).Return(nil)
+		mockSessRepo.EXPECT().DeleteAllByUserID(gomock.Any(), user.UserID).Return(nil)
+		mockRedisRepo.EXPECT().GetUserTokenFamiliesCtx(gomock.Any(), familiesKey).Return([]string{familyID.String()}, nil)
+		mockRedisRepo.EXPECT().DeleteTokenFamilyCtx(gomock.Any(), fmt.Sprintf("%s: %s", tokenFamilyPrefix, familyID)).Return(nil)
+		mockRedisRepo.EXPECT().RemoveUserTokenFamiliesCtx(gomock.Any(), familiesKey, []string{familyID.String()}).Return(nil)
+		mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), userKey).Return(nil)
+		mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event *models.AuditEvent) error {
+			require.Equal(t, models.AuditActionEmailRevert, event.Action)
+			require.Equal(t, user.UserID, event.ActorID)
+			require.Equal(t, user.UserID.String(), event.TargetID)
+			require.Equal(t, user.Email, event.Metadata["email"])
+			return nil
+		})
+
+		err := authUC.RevertEmailChange(context.Background(), revertToken)
+		require.NoError(t, err)
+	})
+}
+
+func TestAuthUC_ChangePassword(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	mockSessRepo := sessionMock.NewMockSessRepository(ctrl)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, mockSessRepo, nil, nil, newTestHasher(t, cfg), mockAuditUC, apiLogger)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	user := &models.User{UserID: uuid.New(), Email: "email@gmail.com"}
+	userKey := fmt.Sprintf("%s: %s", basePrefix, user.UserID.String())
+	currentSess := &models.Session{SessionID: "current", UserID: user.UserID}
+	otherSess := &models.Session{SessionID: "other", UserID: user.UserID}
+	refreshToken := "refresh token"
+	refreshTokenKey := fmt.Sprintf("%s: %s", refreshTokenPrefix, utils.HashToken(refreshToken))
+	familiesKey := fmt.Sprintf("%s: %s", userFamiliesPrefix, user.UserID)
+	currentFamilyID := uuid.New()
+	otherFamilyID := uuid.New()
+
+	t.Run("Change password and delete other sessions", func(t *testing.T) {
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil)
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Eq(&models.User{Email: user.Email})).
+			Return(&models.User{UserID: user.UserID, Email: user.Email, Password: string(hashedPassword)}, nil)
+		mockAuthRepo.EXPECT().UpdatePassword(gomock.Any(), user.UserID, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, userID uuid.UUID, password string) error {
+				require.NoError(t, bcrypt.CompareHashAndPassword([]byte(password), []byte("new-password")))
+				return nil
+			})
+		mockSessRepo.EXPECT().GetSessionsByUserID(gomock.Any(), user.UserID).Return([]*models.Session{currentSess, otherSess}, nil)
+		mockSessRepo.EXPECT().DeleteSession(gomock.Any(), otherSess).Return(nil)
+		mockRedisRepo.EXPECT().GetRefreshTokenCtx(gomock.Any(), refreshTokenKey).
+			Return(&models.RefreshToken{FamilyID: currentFamilyID, UserID: user.UserID}, nil)
+		mockRedisRepo.EXPECT().GetUserTokenFamiliesCtx(gomock.Any(), familiesKey).
+			Return([]string{currentFamilyID.String(), otherFamilyID.String()}, nil)
+		mockRedisRepo.EXPECT().DeleteTokenFamilyCtx(gomock.Any(), fmt.Sprintf("%s: %s", tokenFamilyPrefix, otherFamilyID)).Return(nil)
+		mockRedisRepo.EXPECT().RemoveUserTokenFamiliesCtx(gomock.Any(), familiesKey, []string{otherFamilyID.String()}).Return(nil)
+		mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), userKey).Return(nil)
+
+		err := authUC.ChangePassword(context.Background(), user.UserID, "123456", "new-password", currentSess.SessionID, refreshToken)
+		require.NoError(t, err)
+	})
+
+	t.Run("Wrong current password", func(t *testing.T) {
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil)
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Eq(&models.User{Email: user.Email})).
+			Return(&models.User{UserID: user.UserID, Email: user.Email, Password: string(hashedPassword)}, nil)
+
+		err := authUC.ChangePassword(context.Background(), user.UserID, "wrong-password", "new-password", currentSess.SessionID, refreshToken)
+		require.Error(t, err)
+	})
+}
+
+func TestAuthUC_PasswordPolicy(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	breachedPassword := "breached123"
+	hashes := make([]string, 0, 4)
+	for _, password := range []string{breachedPassword, "123456789", "abcdefgh1", "iloveyou1"} {
+		sum := sha1.Sum([]byte(password))
+		hashes = append(hashes, strings.ToUpper(hex.EncodeToString(sum[:]))+":42")
+	}
+	sort.Strings(hashes)
+	breachedFile := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, ioutil.WriteFile(breachedFile, []byte(strings.Join(hashes, "\n")+"\n"), 0o600))
+
+	cfg := &config.Config{
+		Password: config.PasswordPolicy{
+			MinLength:          8,
+			RequireDigit:       true,
+			BannedWords:        []string{"Password"},
+			BreachedHashesFile: breachedFile,
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, newTestHasher(t, cfg), nil, apiLogger)
+
+	violatedRules := func(t *testing.T, err error) []string {
+		var policyErr httpErrors.PasswordPolicyError
+		require.True(t, errors.As(err, &policyErr))
+		require.Equal(t, http.StatusBadRequest, policyErr.Status())
+		rules := make([]string, 0, len(policyErr.Violations))
+		for _, violation := range policyErr.Violations {
+			rules = append(rules, violation.Rule)
+		}
+		return rules
+	}
+
+	cases := []struct {
+		name     string
+		password string
+		rules    []string
+	}{
+		{name: "Too short without digit", password: "short", rules: []string{"min_length", "digit"}},
+		{name: "Banned word", password: "myPassword1", rules: []string{"banned_word"}},
+		{name: "Breached", password: breachedPassword, rules: []string{"breached"}},
+		{name: "Too long for bcrypt", password: strings.Repeat("a", 72) + "1", rules: []string{"max_length"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			user := &models.User{Email: "email@gmail.com", Password: tc.password}
+			mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows)
+
+			_, err := authUC.Register(context.Background(), user)
+			require.Equal(t, tc.rules, violatedRules(t, err))
+		})
+	}
+
+	t.Run("Reset password is rejected before token is consumed", func(t *testing.T) {
+		err := authUC.ResetPassword(context.Background(), "reset-token", breachedPassword)
+		require.Equal(t, []string{"breached"}, violatedRules(t, err))
+	})
+
+	t.Run("Strong password passes policy", func(t *testing.T) {
+		mockRedisRepo.EXPECT().ConsumeUserTokenCtx(gomock.Any(), gomock.Any()).Return(nil, redis.Nil)
+
+		err := authUC.ResetPassword(context.Background(), "reset-token", "correct horse battery 9")
+		var policyErr httpErrors.PasswordPolicyError
+		require.False(t, errors.As(err, &policyErr))
+		require.Equal(t, http.StatusUnauthorized, httpErrors.ParseErrors(err).Status())
+	})
+}
+
+func TestAuthUC_LoginRehashPassword(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			JwtSecretKey: "secret",
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+		Lockout: config.Lockout{
+			MaxAttempts:    5,
+			IPMaxAttempts:  100,
+			AttemptsWindow: 3600,
+		},
+		PasswordHash: config.PasswordHash{
+			Algorithm:     passwordhash.Argon2id,
+			Argon2Time:    1,
+			Argon2Memory:  1024,
+			Argon2Threads: 1,
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	jwtKeys, err := jwtkeys.NewKeySet(cfg)
+	require.NoError(t, err)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	hasher := newTestHasher(t, cfg)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, jwtKeys, hasher, mockAuditUC, apiLogger)
+
+	ipAddress := "127.0.0.1"
+	user := &models.User{Email: "email@gmail.com", Password: "123456"}
+
+	login := func(t *testing.T, storedHash string) {
+		mockRedisRepo.EXPECT().GetLoginAttemptsCtx(gomock.Any(), fmt.Sprintf("%s: %s", ipAttemptsPrefix, ipAddress)).Return(int64(0), time.Duration(0), nil)
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Eq(user)).
+			Return(&models.User{UserID: uuid.New(), Email: user.Email, Password: storedHash}, nil)
+		mockRedisRepo.EXPECT().DeleteLoginAttemptsCtx(gomock.Any(), gomock.Any()).Return(nil)
+		mockRedisRepo.EXPECT().SetTokenFamilyCtx(gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+		mockRedisRepo.EXPECT().AddUserTokenFamilyCtx(gomock.Any(), gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire).Return(nil)
+		mockRedisRepo.EXPECT().SetRefreshTokenCtx(gomock.Any(), gomock.Any(), cfg.Server.RefreshTokenExpire, gomock.Any()).Return(nil)
+
+		userWithToken, err := authUC.Login(context.Background(), user, ipAddress)
+		require.NoError(t, err)
+		require.NotNil(t, userWithToken)
+	}
+
+	t.Run("Outdated bcrypt hash is upgraded to argon2id", func(t *testing.T) {
+		bcryptHash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.MinCost)
+		require.NoError(t, err)
+
+		mockAuthRepo.EXPECT().UpdatePassword(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, userID uuid.UUID, password string) error {
+				require.True(t, strings.HasPrefix(password, "$argon2id$v=19$m=1024,t=1,p=1$"))
+				require.NoError(t, hasher.Compare(password, user.Password))
+				require.False(t, hasher.NeedsRehash(password))
+				return nil
+			})
+
+		login(t, string(bcryptHash))
+	})
+
+	t.Run("Current hash is kept", func(t *testing.T) {
+		currentHash, err := hasher.Hash(user.Password)
+		require.NoError(t, err)
+
+		login(t, currentHash)
+	})
+}
+
+func TestAuthUC_UpdateStatus(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			JwtSecretKey: "secret",
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+		Lockout: config.Lockout{
+			MaxAttempts:    5,
+			IPMaxAttempts:  100,
+			AttemptsWindow: 3600,
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	mockSessRepo := sessionMock.NewMockSessRepository(ctrl)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, mockSessRepo, nil, nil, newTestHasher(t, cfg), mockAuditUC, apiLogger)
+
+	userID := uuid.New()
+	userKey := fmt.Sprintf("%s: %s", basePrefix, userID.String())
+	reason := "spam"
+
+	t.Run("Suspend revokes sessions", func(t *testing.T) {
+		until := time.Now().Add(time.Hour)
+		status := &models.UserStatus{Status: models.UserStatusSuspended, Reason: &reason, Until: &until}
+
+		mockAuthRepo.EXPECT().UpdateStatus(gomock.Any(), userID, status).Return(nil)
+		mockSessRepo.EXPECT().DeleteAllByUserID(gomock.Any(), userID).Return(nil)
+		mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), userKey).Return(nil)
+
+		err := authUC.UpdateStatus(context.Background(), userID, status)
+		require.NoError(t, err)
+	})
+
+	t.Run("Reinstate clears reason and expiry", func(t *testing.T) {
+		until := time.Now().Add(time.Hour)
+		status := &models.UserStatus{Status: models.UserStatusActive, Reason: &reason, Until: &until}
+
+		mockAuthRepo.EXPECT().UpdateStatus(gomock.Any(), userID, gomock.Eq(&models.UserStatus{Status: models.UserStatusActive})).Return(nil)
+		mockRedisRepo.EXPECT().DeleteUserCtx(gomock.Any(), userKey).Return(nil)
+
+		err := authUC.UpdateStatus(context.Background(), userID, status)
+		require.NoError(t, err)
+	})
+
+	t.Run("Expiry in the past", func(t *testing.T) {
+		until := time.Now().Add(-time.Hour)
+		status := &models.UserStatus{Status: models.UserStatusBanned, Until: &until}
+
+		err := authUC.UpdateStatus(context.Background(), userID, status)
+		require.Error(t, err)
+		require.Equal(t, http.StatusBadRequest, httpErrors.ParseErrors(err).Status())
+	})
+
+	t.Run("Banned user can not log in", func(t *testing.T) {
+		user := &models.User{Email: "email@gmail.com", Password: "123456"}
+		hashPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+		require.NoError(t, err)
+		ipAddress := "127.0.0.1"
+
+		mockRedisRepo.EXPECT().GetLoginAttemptsCtx(gomock.Any(), gomock.Any()).Return(int64(0), time.Duration(0), nil)
+		mockAuthRepo.EXPECT().FindByEmail(gomock.Any(), gomock.Eq(user)).Return(&models.User{
+			UserID:   userID,
+			Email:    user.Email,
+			Password: string(hashPassword),
+			Status:   models.UserStatusBanned,
+		}, nil)
+		mockRedisRepo.EXPECT().DeleteLoginAttemptsCtx(gomock.Any(), gomock.Any()).Return(nil)
+
+		userWithToken, err := authUC.Login(context.Background(), user, ipAddress)
+		require.Nil(t, userWithToken)
+		require.Equal(t, http.StatusForbidden, httpErrors.ParseErrors(err).Status())
+		require.Contains(t, err.Error(), httpErrors.AccountBanned.Error())
+	})
+}
+
+func TestAuthUC_Impersonate(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			JwtSecretKey: "secret",
+		},
+		Logger: config.Logger{
+			Development:       true,
+			DisableCaller:     false,
+			DisableStacktrace: false,
+			Encoding:          "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	mockAuthRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	authUC := NewAuthUseCase(cfg, mockAuthRepo, mockRedisRepo, nil, nil, nil, nil, newTestHasher(t, cfg), nil, apiLogger)
+
+	adminID := uuid.New()
+	role := "user"
+	user := &models.User{UserID: uuid.New(), Role: &role, Status: models.UserStatusActive}
+	userKey := fmt.Sprintf("%s: %s", basePrefix, user.UserID.String())
+	permissionsKey := fmt.Sprintf("%s: %s", permissionsPrefix, user.UserID.String())
+
+	t.Run("Impersonate self", func(t *testing.T) {
+		impersonated, err := authUC.Impersonate(context.Background(), adminID, adminID)
+		require.Nil(t, impersonated)
+		require.Equal(t, http.StatusBadRequest, httpErrors.ParseErrors(err).Status())
+	})
+
+	t.Run("Target can impersonate", func(t *testing.T) {
+		cached := &models.UserPermissions{Role: role, Permissions: []string{models.PermissionUsersImpersonate}}
+		mockRedisRepo.EXPECT().GetByIDCtx(gomock.Any(), userKey).Return(user, nil)
+		mockRedisRepo.EXPECT().GetPermissionsCtx(gomock.Any(), permissionsKey).Return(cached, nil)
+
+		impersonated, err := authUC.Impersonate(context.Background(), adminID, user.UserID)
+		require.Nil(t, impersonated)
+		require.Equal(t, http.StatusForbidden, httpErrors.ParseErrors(err).Status())
+	})
+
+	t.Run("Target is suspended", func(t *testing.T) {
+		suspended := &models.User{UserID: user.UserID, Role: &role, Status: models.UserStatusSuspended}
+		mockRedisRepo.EXPECT().GetByIDCtx(gomock.Any(), userKey).Return(suspended, nil)
+
+		impersonated, err := authUC.Impersonate(context.Background(), adminID, user.UserID)
+		require.Nil(t, impersonated)
+		require.Equal(t, http.StatusForbidden, httpErrors.ParseErrors(err).Status())
+	})
+
+	t.Run("Impersonate", func(t *testing.T) {
+		cached := &models.UserPermissions{Role: role, Permissions: []string{models.PermissionNewsPublish}}
+		mockRedisRepo.EXPECT().GetByIDCtx(gomock.Any(), userKey).Return(user, nil)
+		mockRedisRepo.EXPECT().GetPermissionsCtx(gomock.Any(), permissionsKey).Return(cached, nil)
+
+		impersonated, err := authUC.Impersonate(context.Background(), adminID, user.UserID)
+		require.NoError(t, err)
+		require.Equal(t, user.UserID, impersonated.UserID)
+	})
+}
@@ -13,9 +13,11 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/require"
 
+	auditMock "github.com/AleksK1NG/api-mc/internal/audit/mock"
 	"github.com/AleksK1NG/api-mc/internal/comments/mock"
 	"github.com/AleksK1NG/api-mc/internal/comments/usecase"
 	"github.com/AleksK1NG/api-mc/internal/models"
+	newsMock "github.com/AleksK1NG/api-mc/internal/news/mock"
 	"github.com/AleksK1NG/api-mc/pkg/converter"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 	"github.com/AleksK1NG/api-mc/pkg/utils"
@@ -29,7 +31,8 @@ func TestCommentsHandlers_Create(t *testing.T) {
 
 	apiLogger := logger.NewApiLogger(nil)
 	mockCommUC := mock.NewMockUseCase(ctrl)
-	commUC := usecase.NewCommentsUseCase(nil, mockCommUC, apiLogger)
+	mockNewsUC := newsMock.NewMockUseCase(ctrl)
+	commUC := usecase.NewCommentsUseCase(nil, mockCommUC, mockNewsUC, nil, apiLogger)
 
 	commHandlers := NewCommentsHandlers(nil, commUC, apiLogger)
 	handlerFunc := commHandlers.Create()
@@ -68,6 +71,7 @@ func TestCommentsHandlers_Create(t *testing.T) {
 	fmt.Printf("COMMENT: %#v\n", comment)
 	fmt.Printf("MOCK COMMENT: %#v\n", mockComm)
 
+	mockNewsUC.EXPECT().GetNewsByID(gomock.Any(), newsUID).Return(&models.NewsBase{NewsID: newsUID}, nil)
 	mockCommUC.EXPECT().Create(gomock.Any(), gomock.Any()).Return(mockComm, nil)
 
 	err = handlerFunc(ctx)
@@ -82,7 +86,7 @@ func TestCommentsHandlers_GetByID(t *testing.T) {
 
 	apiLogger := logger.NewApiLogger(nil)
 	mockCommUC := mock.NewMockUseCase(ctrl)
-	commUC := usecase.NewCommentsUseCase(nil, mockCommUC, apiLogger)
+	commUC := usecase.NewCommentsUseCase(nil, mockCommUC, nil, nil, apiLogger)
 
 	commHandlers := NewCommentsHandlers(nil, commUC, apiLogger)
 	handlerFunc := commHandlers.GetByID()
@@ -110,7 +114,9 @@ func TestCommentsHandlers_Delete(t *testing.T) {
 
 	apiLogger := logger.NewApiLogger(nil)
 	mockCommUC := mock.NewMockUseCase(ctrl)
-	commUC := usecase.NewCommentsUseCase(nil, mockCommUC, apiLogger)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	commUC := usecase.NewCommentsUseCase(nil, mockCommUC, nil, mockAuditUC, apiLogger)
 
 	commHandlers := NewCommentsHandlers(nil, commUC, apiLogger)
 	handlerFunc := commHandlers.Delete()
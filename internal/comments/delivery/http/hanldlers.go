@@ -34,6 +34,7 @@ func NewCommentsHandlers(cfg *config.Config, comUC comments.UseCase, logger logg
 // @Accept  json
 // @Produce  json
 // @Success 201 {object} models.Comment
+// @Failure 404 {object} httpErrors.RestErr
 // @Failure 500 {object} httpErrors.RestErr
 // @Router /comments [post]
 func (h *commentsHandlers) Create() echo.HandlerFunc {
@@ -182,6 +183,7 @@ func (h *commentsHandlers) GetByID() echo.HandlerFunc {
 // @Param size query int false "number of elements per page" Format(size)
 // @Param orderBy query int false "filter name" Format(orderBy)
 // @Success 200 {object} models.CommentsList
+// @Failure 404 {object} httpErrors.RestErr
 // @Failure 500 {object} httpErrors.RestErr
 // @Router /comments/byNewsId/{id} [get]
 func (h *commentsHandlers) GetAllByNewsID() echo.HandlerFunc {
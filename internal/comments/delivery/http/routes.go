@@ -5,13 +5,14 @@ import (
 
 	"github.com/AleksK1NG/api-mc/internal/comments"
 	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/models"
 )
 
 // Map comments routes
 func MapCommentsRoutes(commGroup *echo.Group, h comments.Handlers, mw *middleware.MiddlewareManager) {
-	commGroup.POST("", h.Create(), mw.AuthSessionMiddleware, mw.CSRF)
-	commGroup.DELETE("/:comment_id", h.Delete(), mw.AuthSessionMiddleware, mw.CSRF)
-	commGroup.PUT("/:comment_id", h.Update(), mw.AuthSessionMiddleware, mw.CSRF)
+	commGroup.POST("", h.Create(), mw.ScopedAuthMiddleware(models.APIKeyScopeCommentsWrite), mw.CSRF)
+	commGroup.DELETE("/:comment_id", h.Delete(), mw.ScopedAuthMiddleware(models.APIKeyScopeCommentsWrite), mw.CSRF)
+	commGroup.PUT("/:comment_id", h.Update(), mw.ScopedAuthMiddleware(models.APIKeyScopeCommentsWrite), mw.CSRF)
 	commGroup.GET("/:comment_id", h.GetByID())
-	commGroup.GET("/byNewsId/:news_id", h.GetAllByNewsID())
+	commGroup.GET("/byNewsId/:news_id", h.GetAllByNewsID(), mw.OptionalAuthMiddleware)
 }
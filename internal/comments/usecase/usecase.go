@@ -9,8 +9,10 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/audit"
 	"github.com/AleksK1NG/api-mc/internal/comments"
 	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/news"
 	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 	"github.com/AleksK1NG/api-mc/pkg/utils"
@@ -20,18 +22,25 @@ import (
 type commentsUC struct {
 	cfg      *config.Config
 	commRepo comments.Repository
+	newsUC   news.UseCase
+	auditUC  audit.UseCase
 	logger   logger.Logger
 }
 
 // Comments UseCase constructor
-func NewCommentsUseCase(cfg *config.Config, commRepo comments.Repository, logger logger.Logger) comments.UseCase {
-	return &commentsUC{cfg: cfg, commRepo: commRepo, logger: logger}
+func NewCommentsUseCase(cfg *config.Config, commRepo comments.Repository, newsUC news.UseCase, auditUC audit.UseCase, logger logger.Logger) comments.UseCase {
+	return &commentsUC{cfg: cfg, commRepo: commRepo, newsUC: newsUC, auditUC: auditUC, logger: logger}
 }
 
-// Create comment
+// Create comment, news must be visible to the author
 func (u *commentsUC) Create(ctx context.Context, comment *models.Comment) (*models.Comment, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "commentsUC.Create")
 	defer span.Finish()
+
+	if _, err := u.newsUC.GetNewsByID(ctx, comment.NewsID); err != nil {
+		return nil, err
+	}
+
 	return u.commRepo.Create(ctx, comment)
 }
 
@@ -45,7 +54,7 @@ func (u *commentsUC) Update(ctx context.Context, comment *models.Comment) (*mode
 		return nil, err
 	}
 
-	if err = utils.ValidateIsOwner(ctx, comm.AuthorID.String(), u.logger); err != nil {
+	if err = utils.ValidateIsOwnerOrPermission(ctx, comm.AuthorID.String(), models.PermissionCommentsModerate, u.logger); err != nil {
 		return nil, httpErrors.NewRestError(http.StatusForbidden, "Forbidden", errors.Wrap(err, "commentsUC.Update.ValidateIsOwner"))
 	}
 
@@ -54,6 +63,14 @@ func (u *commentsUC) Update(ctx context.Context, comment *models.Comment) (*mode
 		return nil, err
 	}
 
+	u.recordAuditEvent(ctx, &models.AuditEvent{
+		Action:     models.AuditActionCommentUpdate,
+		TargetType: models.AuditTargetComment,
+		TargetID:   comment.CommentID.String(),
+		Before:     comm,
+		After:      updatedComment,
+	})
+
 	return updatedComment, nil
 }
 
@@ -67,7 +84,7 @@ func (u *commentsUC) Delete(ctx context.Context, commentID uuid.UUID) error {
 		return err
 	}
 
-	if err = utils.ValidateIsOwner(ctx, comm.AuthorID.String(), u.logger); err != nil {
+	if err = utils.ValidateIsOwnerOrPermission(ctx, comm.AuthorID.String(), models.PermissionCommentsModerate, u.logger); err != nil {
 		return httpErrors.NewRestError(http.StatusForbidden, "Forbidden", errors.Wrap(err, "commentsUC.Delete.ValidateIsOwner"))
 	}
 
@@ -75,6 +92,13 @@ func (u *commentsUC) Delete(ctx context.Context, commentID uuid.UUID) error {
 		return err
 	}
 
+	u.recordAuditEvent(ctx, &models.AuditEvent{
+		Action:     models.AuditActionCommentDelete,
+		TargetType: models.AuditTargetComment,
+		TargetID:   commentID.String(),
+		Before:     comm,
+	})
+
 	return nil
 }
 
@@ -86,10 +110,21 @@ func (u *commentsUC) GetByID(ctx context.Context, commentID uuid.UUID) (*models.
 	return u.commRepo.GetByID(ctx, commentID)
 }
 
-// GetAllByNewsID comments
+// GetAllByNewsID comments, comments of news not visible to context user are hidden as not existing
 func (u *commentsUC) GetAllByNewsID(ctx context.Context, newsID uuid.UUID, query *utils.PaginationQuery) (*models.CommentsList, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "commentsUC.GetAllByNewsID")
 	defer span.Finish()
 
+	if _, err := u.newsUC.GetNewsByID(ctx, newsID); err != nil {
+		return nil, err
+	}
+
 	return u.commRepo.GetAllByNewsID(ctx, newsID, query)
 }
+
+// Record audit event, failures are logged and do not fail the action
+func (u *commentsUC) recordAuditEvent(ctx context.Context, event *models.AuditEvent) {
+	if err := u.auditUC.Record(ctx, event); err != nil {
+		u.logger.Errorf("commentsUC.recordAuditEvent Action: %s, TargetID: %s, Error: %v", event.Action, event.TargetID, err)
+	}
+}
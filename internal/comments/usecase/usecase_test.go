@@ -2,15 +2,20 @@ package usecase
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
+	auditMock "github.com/AleksK1NG/api-mc/internal/audit/mock"
 	"github.com/AleksK1NG/api-mc/internal/comments/mock"
 	"github.com/AleksK1NG/api-mc/internal/models"
+	newsMock "github.com/AleksK1NG/api-mc/internal/news/mock"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 	"github.com/AleksK1NG/api-mc/pkg/utils"
 )
@@ -23,18 +28,30 @@ func TestCommentsUC_Create(t *testing.T) {
 
 	apiLogger := logger.NewApiLogger(nil)
 	mockCommRepo := mock.NewMockRepository(ctrl)
-	commUC := NewCommentsUseCase(nil, mockCommRepo, apiLogger)
+	mockNewsUC := newsMock.NewMockUseCase(ctrl)
+	commUC := NewCommentsUseCase(nil, mockCommRepo, mockNewsUC, nil, apiLogger)
 
-	comm := &models.Comment{}
+	comm := &models.Comment{NewsID: uuid.New()}
 
 	span, ctx := opentracing.StartSpanFromContext(context.Background(), "commentsUC.Create")
 	defer span.Finish()
 
+	mockNewsUC.EXPECT().GetNewsByID(ctx, comm.NewsID).Return(&models.NewsBase{NewsID: comm.NewsID}, nil)
 	mockCommRepo.EXPECT().Create(ctx, gomock.Eq(comm)).Return(comm, nil)
 
 	createdComment, err := commUC.Create(context.Background(), comm)
 	require.NoError(t, err)
 	require.NotNil(t, createdComment)
+
+	t.Run("News not visible", func(t *testing.T) {
+		hidden := &models.Comment{NewsID: uuid.New()}
+		mockNewsUC.EXPECT().GetNewsByID(gomock.Any(), hidden.NewsID).
+			Return(nil, httpErrors.NewNotFoundError(errors.New("newsUC.GetNewsByID: news is not published")))
+
+		createdComment, err := commUC.Create(context.Background(), hidden)
+		require.Nil(t, createdComment)
+		require.Equal(t, http.StatusNotFound, httpErrors.ParseErrors(err).Status())
+	})
 }
 
 func TestCommentsUC_Update(t *testing.T) {
@@ -45,7 +62,9 @@ func TestCommentsUC_Update(t *testing.T) {
 
 	apiLogger := logger.NewApiLogger(nil)
 	mockCommRepo := mock.NewMockRepository(ctrl)
-	commUC := NewCommentsUseCase(nil, mockCommRepo, apiLogger)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	commUC := NewCommentsUseCase(nil, mockCommRepo, nil, mockAuditUC, apiLogger)
 
 	authorUID := uuid.New()
 
@@ -82,7 +101,8 @@ func TestCommentsUC_Delete(t *testing.T) {
 
 	apiLogger := logger.NewApiLogger(nil)
 	mockCommRepo := mock.NewMockRepository(ctrl)
-	commUC := NewCommentsUseCase(nil, mockCommRepo, apiLogger)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	commUC := NewCommentsUseCase(nil, mockCommRepo, nil, mockAuditUC, apiLogger)
 
 	authorUID := uuid.New()
 
@@ -105,6 +125,12 @@ func TestCommentsUC_Delete(t *testing.T) {
 
 	mockCommRepo.EXPECT().GetByID(ctxWithTrace, gomock.Eq(comm.CommentID)).Return(baseComm, nil)
 	mockCommRepo.EXPECT().Delete(ctxWithTrace, gomock.Eq(comm.CommentID)).Return(nil)
+	mockAuditUC.EXPECT().Record(ctxWithTrace, gomock.Any()).DoAndReturn(func(ctx context.Context, event *models.AuditEvent) error {
+		require.Equal(t, models.AuditActionCommentDelete, event.Action)
+		require.Equal(t, models.AuditTargetComment, event.TargetType)
+		require.Equal(t, comm.CommentID.String(), event.TargetID)
+		return nil
+	})
 
 	err := commUC.Delete(ctx, comm.CommentID)
 	require.NoError(t, err)
@@ -119,7 +145,7 @@ func TestCommentsUC_GetByID(t *testing.T) {
 
 	apiLogger := logger.NewApiLogger(nil)
 	mockCommRepo := mock.NewMockRepository(ctrl)
-	commUC := NewCommentsUseCase(nil, mockCommRepo, apiLogger)
+	commUC := NewCommentsUseCase(nil, mockCommRepo, nil, nil, apiLogger)
 
 	comm := &models.Comment{
 		CommentID: uuid.New(),
@@ -147,7 +173,8 @@ func TestCommentsUC_GetAllByNewsID(t *testing.T) {
 
 	apiLogger := logger.NewApiLogger(nil)
 	mockCommRepo := mock.NewMockRepository(ctrl)
-	commUC := NewCommentsUseCase(nil, mockCommRepo, apiLogger)
+	mockNewsUC := newsMock.NewMockUseCase(ctrl)
+	commUC := NewCommentsUseCase(nil, mockCommRepo, mockNewsUC, nil, apiLogger)
 
 	newsUID := uuid.New()
 
@@ -168,10 +195,21 @@ func TestCommentsUC_GetAllByNewsID(t *testing.T) {
 		OrderBy: "",
 	}
 
+	mockNewsUC.EXPECT().GetNewsByID(ctxWithTrace, comm.NewsID).Return(&models.NewsBase{NewsID: comm.NewsID}, nil)
 	mockCommRepo.EXPECT().GetAllByNewsID(ctxWithTrace, gomock.Eq(comm.NewsID), query).Return(commentsList, nil)
 
 	commList, err := commUC.GetAllByNewsID(ctx, comm.NewsID, query)
 	require.NoError(t, err)
 	require.Nil(t, err)
 	require.NotNil(t, commList)
+
+	t.Run("News not visible", func(t *testing.T) {
+		hiddenNewsID := uuid.New()
+		mockNewsUC.EXPECT().GetNewsByID(gomock.Any(), hiddenNewsID).
+			Return(nil, httpErrors.NewNotFoundError(errors.New("newsUC.GetNewsByID: news is not published")))
+
+		commList, err := commUC.GetAllByNewsID(ctx, hiddenNewsID, query)
+		require.Nil(t, commList)
+		require.Equal(t, http.StatusNotFound, httpErrors.ParseErrors(err).Status())
+	})
 }
@@ -0,0 +1,14 @@
+//go:generate mockgen -source aws_repository.go -destination mock/aws_repository_mock.go -package mock
+package export
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Personal data export object store
+type AWSRepository interface {
+	PutObject(ctx context.Context, bucket string, key string, reader io.Reader, size int64, contentType string) error
+	PresignedGetObject(ctx context.Context, bucket string, key string, expire time.Duration) (string, error)
+}
@@ -0,0 +1,9 @@
+package export
+
+import "github.com/labstack/echo/v4"
+
+// Personal data export HTTP Handlers interface
+type Handlers interface {
+	CreateExport() echo.HandlerFunc
+	GetExport() echo.HandlerFunc
+}
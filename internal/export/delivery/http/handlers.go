@@ -0,0 +1,96 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/export"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+// Personal data export handlers
+type exportHandlers struct {
+	cfg      *config.Config
+	exportUC export.UseCase
+	logger   logger.Logger
+}
+
+// Personal data export handlers constructor
+func NewExportHandlers(cfg *config.Config, exportUC export.UseCase, log logger.Logger) export.Handlers {
+	return &exportHandlers{cfg: cfg, exportUC: exportUC, logger: log}
+}
+
+// CreateExport godoc
+// @Summary Request personal data export
+// @Description start building ZIP of user profile, news, comments, sessions and avatar, poll export for download link
+// @Tags Export
+// @Accept json
+// @Produce json
+// @Param id path string true "user_id"
+// @Success 202 {object} models.DataExport
+// @Failure 404 {object} httpErrors.RestError
+// @Failure 409 {object} httpErrors.RestError
+// @Router /exports/{id} [post]
+func (h *exportHandlers) CreateExport() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "exportHandlers.CreateExport")
+		defer span.Finish()
+
+		uID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		dataExport, err := h.exportUC.CreateExport(ctx, uID)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusAccepted, dataExport)
+	}
+}
+
+// GetExport godoc
+// @Summary Get personal data export
+// @Description get export status, time limited download url is returned when export is ready
+// @Tags Export
+// @Accept json
+// @Produce json
+// @Param id path string true "user_id"
+// @Param export_id path string true "export_id"
+// @Success 200 {object} models.DataExport
+// @Failure 404 {object} httpErrors.RestError
+// @Router /exports/{id}/{export_id} [get]
+func (h *exportHandlers) GetExport() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "exportHandlers.GetExport")
+		defer span.Finish()
+
+		uID, err := uuid.Parse(c.Param("user_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+		exportID, err := uuid.Parse(c.Param("export_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		dataExport, err := h.exportUC.GetExport(ctx, uID, exportID)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, dataExport)
+	}
+}
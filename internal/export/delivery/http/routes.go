@@ -0,0 +1,16 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/AleksK1NG/api-mc/internal/export"
+	"github.com/AleksK1NG/api-mc/internal/middleware"
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Map personal data export routes
+func MapExportRoutes(exportGroup *echo.Group, h export.Handlers, mw *middleware.MiddlewareManager) {
+	exportGroup.Use(mw.AuthMiddleware)
+	exportGroup.POST("/:user_id", h.CreateExport(), mw.CSRF, mw.NotImpersonatingMiddleware, mw.OwnerOrPermissionMiddleware(models.PermissionUsersExport))
+	exportGroup.GET("/:user_id/:export_id", h.GetExport(), mw.OwnerOrPermissionMiddleware(models.PermissionUsersExport))
+}
@@ -0,0 +1,16 @@
+//go:generate mockgen -source pg_repository.go -destination mock/pg_repository_mock.go -package mock
+package export
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Personal data export repository
+type Repository interface {
+	GetNewsByAuthorID(ctx context.Context, authorID uuid.UUID) ([]*models.News, error)
+	GetCommentsByAuthorID(ctx context.Context, authorID uuid.UUID) ([]*models.Comment, error)
+}
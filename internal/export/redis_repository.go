@@ -0,0 +1,16 @@
+//go:generate mockgen -source redis_repository.go -destination mock/redis_repository_mock.go -package mock
+package export
+
+import (
+	"context"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Personal data export redis repository
+type RedisRepository interface {
+	GetExportCtx(ctx context.Context, key string) (*models.DataExport, error)
+	SetExportCtx(ctx context.Context, key string, seconds int, export *models.DataExport) error
+	SetExportLockCtx(ctx context.Context, key string, seconds int, exportID string) (bool, error)
+	DeleteExportLockCtx(ctx context.Context, key string) error
+}
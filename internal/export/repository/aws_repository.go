@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/export"
+)
+
+// Personal data export AWS S3 repository
+type exportAWSRepository struct {
+	client *minio.Client
+}
+
+// Personal data export AWS S3 repository constructor
+func NewExportAWSRepository(awsClient *minio.Client) export.AWSRepository {
+	return &exportAWSRepository{client: awsClient}
+}
+
+// Upload private object with given key
+func (aws *exportAWSRepository) PutObject(ctx context.Context, bucket string, key string, reader io.Reader, size int64, contentType string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "exportAWSRepository.PutObject")
+	defer span.Finish()
+
+	if _, err := aws.client.PutObject(ctx, bucket, key, reader, size, minio.PutObjectOptions{ContentType: contentType}); err != nil {
+		return errors.Wrap(err, "exportAWSRepository.PutObject")
+	}
+	return nil
+}
+
+// Time limited download url of object
+func (aws *exportAWSRepository) PresignedGetObject(ctx context.Context, bucket string, key string, expire time.Duration) (string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "exportAWSRepository.PresignedGetObject")
+	defer span.Finish()
+
+	presignedURL, err := aws.client.PresignedGetObject(ctx, bucket, key, expire, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "exportAWSRepository.PresignedGetObject")
+	}
+	return presignedURL.String(), nil
+}
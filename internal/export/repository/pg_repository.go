@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/export"
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Personal data export repository
+type exportRepo struct {
+	db *sqlx.DB
+}
+
+// Personal data export repository constructor
+func NewExportRepository(db *sqlx.DB) export.Repository {
+	return &exportRepo{db: db}
+}
+
+// Get all news of author
+func (r *exportRepo) GetNewsByAuthorID(ctx context.Context, authorID uuid.UUID) ([]*models.News, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "exportRepo.GetNewsByAuthorID")
+	defer span.Finish()
+
+	news := make([]*models.News, 0)
+	if err := r.db.SelectContext(ctx, &news, getNewsByAuthorID, authorID); err != nil {
+		return nil, errors.Wrap(err, "exportRepo.GetNewsByAuthorID.SelectContext")
+	}
+	return news, nil
+}
+
+// Get all comments of author
+func (r *exportRepo) GetCommentsByAuthorID(ctx context.Context, authorID uuid.UUID) ([]*models.Comment, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "exportRepo.GetCommentsByAuthorID")
+	defer span.Finish()
+
+	comments := make([]*models.Comment, 0)
+	if err := r.db.SelectContext(ctx, &comments, getCommentsByAuthorID, authorID); err != nil {
+		return nil, errors.Wrap(err, "exportRepo.GetCommentsByAuthorID.SelectContext")
+	}
+	return comments, nil
+}
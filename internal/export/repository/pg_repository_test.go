@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportRepo_GetNewsByAuthorID(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	exportRepo := NewExportRepository(sqlxDB)
+
+	authorUID := uuid.New()
+	rows := sqlmock.NewRows([]string{"news_id", "author_id", "title", "content"}).
+		AddRow(uuid.New(), authorUID, "title", "content").
+		AddRow(uuid.New(), authorUID, "other title", "other content")
+
+	mock.ExpectQuery(getNewsByAuthorID).WithArgs(authorUID).WillReturnRows(rows)
+
+	news, err := exportRepo.GetNewsByAuthorID(context.Background(), authorUID)
+	require.NoError(t, err)
+	require.Len(t, news, 2)
+	require.Equal(t, authorUID, news[0].AuthorID)
+}
+
+func TestExportRepo_GetCommentsByAuthorID(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	exportRepo := NewExportRepository(sqlxDB)
+
+	authorUID := uuid.New()
+	rows := sqlmock.NewRows([]string{"comment_id", "author_id", "news_id", "message"}).
+		AddRow(uuid.New(), authorUID, uuid.New(), "message")
+
+	mock.ExpectQuery(getCommentsByAuthorID).WithArgs(authorUID).WillReturnRows(rows)
+
+	comments, err := exportRepo.GetCommentsByAuthorID(context.Background(), authorUID)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	require.Equal(t, "message", comments[0].Message)
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/export"
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Personal data export redis repository
+type exportRedisRepo struct {
+	redisClient *redis.Client
+}
+
+// Personal data export redis repository constructor
+func NewExportRedisRepo(redisClient *redis.Client) export.RedisRepository {
+	return &exportRedisRepo{redisClient: redisClient}
+}
+
+// Get export by key
+func (e *exportRedisRepo) GetExportCtx(ctx context.Context, key string) (*models.DataExport, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "exportRedisRepo.GetExportCtx")
+	defer span.Finish()
+
+	exportBytes, err := e.redisClient.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "exportRedisRepo.GetExportCtx.redisClient.Get")
+	}
+	dataExport := &models.DataExport{}
+	if err = json.Unmarshal(exportBytes, dataExport); err != nil {
+		return nil, errors.Wrap(err, "exportRedisRepo.GetExportCtx.json.Unmarshal")
+	}
+	return dataExport, nil
+}
+
+// Set export with duration in seconds
+func (e *exportRedisRepo) SetExportCtx(ctx context.Context, key string, seconds int, dataExport *models.DataExport) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "exportRedisRepo.SetExportCtx")
+	defer span.Finish()
+
+	exportBytes, err := json.Marshal(dataExport)
+	if err != nil {
+		return errors.Wrap(err, "exportRedisRepo.SetExportCtx.json.Marshal")
+	}
+	if err = e.redisClient.Set(ctx, key, exportBytes, time.Second*time.Duration(seconds)).Err(); err != nil {
+		return errors.Wrap(err, "exportRedisRepo.SetExportCtx.redisClient.Set")
+	}
+	return nil
+}
+
+// Set pending export lock with duration in seconds, returns false if lock is already held
+func (e *exportRedisRepo) SetExportLockCtx(ctx context.Context, key string, seconds int, exportID string) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "exportRedisRepo.SetExportLockCtx")
+	defer span.Finish()
+
+	acquired, err := e.redisClient.SetNX(ctx, key, exportID, time.Second*time.Duration(seconds)).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "exportRedisRepo.SetExportLockCtx.redisClient.SetNX")
+	}
+	return acquired, nil
+}
+
+// Delete pending export lock
+func (e *exportRedisRepo) DeleteExportLockCtx(ctx context.Context, key string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "exportRedisRepo.DeleteExportLockCtx")
+	defer span.Finish()
+
+	if err := e.redisClient.Del(ctx, key).Err(); err != nil {
+		return errors.Wrap(err, "exportRedisRepo.DeleteExportLockCtx.redisClient.Del")
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/api-mc/internal/export"
+)
+
+func SetupRedis() (export.RedisRepository, *miniredis.Miniredis) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		log.Fatal(err)
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	exportRedisRepo := NewExportRedisRepo(client)
+	return exportRedisRepo, mr
+}
+
+func TestExportRedisRepo_SetExportLockCtx(t *testing.T) {
+	t.Parallel()
+
+	exportRedisRepo, mr := SetupRedis()
+	defer mr.Close()
+
+	key := "lock"
+
+	t.Run("Lock is held until deleted", func(t *testing.T) {
+		acquired, err := exportRedisRepo.SetExportLockCtx(context.Background(), key, 10, uuid.New().String())
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		acquired, err = exportRedisRepo.SetExportLockCtx(context.Background(), key, 10, uuid.New().String())
+		require.NoError(t, err)
+		require.False(t, acquired)
+
+		require.NoError(t, exportRedisRepo.DeleteExportLockCtx(context.Background(), key))
+
+		acquired, err = exportRedisRepo.SetExportLockCtx(context.Background(), key, 10, uuid.New().String())
+		require.NoError(t, err)
+		require.True(t, acquired)
+		require.NoError(t, exportRedisRepo.DeleteExportLockCtx(context.Background(), key))
+	})
+
+	t.Run("Lock expires", func(t *testing.T) {
+		acquired, err := exportRedisRepo.SetExportLockCtx(context.Background(), key, 10, uuid.New().String())
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		mr.FastForward(11 * time.Second)
+
+		acquired, err = exportRedisRepo.SetExportLockCtx(context.Background(), key, 10, uuid.New().String())
+		require.NoError(t, err)
+		require.True(t, acquired)
+	})
+}
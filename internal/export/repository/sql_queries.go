@@ -0,0 +1,13 @@
+package repository
+
+const (
+	getNewsByAuthorID = `SELECT news_id, author_id, title, content, image_url, category, created_at, updated_at 
+						FROM news 
+						WHERE author_id = $1 
+						ORDER BY created_at`
+
+	getCommentsByAuthorID = `SELECT comment_id, author_id, news_id, message, likes, created_at, updated_at 
+						FROM comments 
+						WHERE author_id = $1 
+						ORDER BY created_at`
+)
@@ -0,0 +1,16 @@
+//go:generate mockgen -source usecase.go -destination mock/usecase_mock.go -package mock
+package export
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+)
+
+// Personal data export use case
+type UseCase interface {
+	CreateExport(ctx context.Context, userID uuid.UUID) (*models.DataExport, error)
+	GetExport(ctx context.Context, userID uuid.UUID, exportID uuid.UUID) (*models.DataExport, error)
+}
@@ -0,0 +1,324 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/audit"
+	"github.com/AleksK1NG/api-mc/internal/auth"
+	"github.com/AleksK1NG/api-mc/internal/export"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/internal/session"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+const (
+	exportPrefix      = "api-data-export:"
+	exportLockPrefix  = "api-data-export-lock:"
+	exportContentType = "application/zip"
+	// Export building time limit used when not configured
+	defaultExportTimeout = 300
+	// Download link lifetime used when not configured
+	defaultExportLinkExpire = 86400
+)
+
+// Personal data export UseCase
+type exportUC struct {
+	cfg         *config.Config
+	exportRepo  export.Repository
+	redisRepo   export.RedisRepository
+	awsRepo     export.AWSRepository
+	authRepo    auth.Repository
+	authAWSRepo auth.AWSRepository
+	sessRepo    session.SessRepository
+	auditUC     audit.UseCase
+	logger      logger.Logger
+}
+
+// Personal data export UseCase constructor
+func NewExportUseCase(
+	cfg *config.Config,
+	exportRepo export.Repository,
+	redisRepo export.RedisRepository,
+	awsRepo export.AWSRepository,
+	authRepo auth.Repository,
+	authAWSRepo auth.AWSRepository,
+	sessRepo session.SessRepository,
+	auditUC audit.UseCase,
+	log logger.Logger,
+) export.UseCase {
+	return &exportUC{
+		cfg:         cfg,
+		exportRepo:  exportRepo,
+		redisRepo:   redisRepo,
+		awsRepo:     awsRepo,
+		authRepo:    authRepo,
+		authAWSRepo: authAWSRepo,
+		sessRepo:    sessRepo,
+		auditUC:     auditUC,
+		logger:      log,
+	}
+}
+
+// Start building personal data export in background, export status is polled with GetExport.
+// Only one export of the user can be pending at a time.
+func (u *exportUC) CreateExport(ctx context.Context, userID uuid.UUID) (*models.DataExport, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "exportUC.CreateExport")
+	defer span.Finish()
+
+	if _, err := u.authRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	dataExport := &models.DataExport{
+		ExportID:  uuid.New(),
+		UserID:    userID,
+		Status:    models.DataExportPending,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	lockKey := u.generateExportLockKey(userID.String())
+	acquired, err := u.redisRepo.SetExportLockCtx(ctx, lockKey, u.exportTimeout(), dataExport.ExportID.String())
+	if err != nil {
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "exportUC.CreateExport.SetExportLockCtx"))
+	}
+	if !acquired {
+		return nil, httpErrors.NewRestError(http.StatusConflict, httpErrors.ExportInProgress.Error(), nil)
+	}
+
+	if err = u.redisRepo.SetExportCtx(ctx, u.generateExportKey(dataExport.ExportID.String()), u.exportTimeout()+u.linkExpire(), dataExport); err != nil {
+		u.releaseExportLock(lockKey)
+		return nil, httpErrors.NewInternalServerError(errors.Wrap(err, "exportUC.CreateExport.SetExportCtx"))
+	}
+
+	u.recordAuditEvent(ctx, &models.AuditEvent{
+		Action:     models.AuditActionDataExport,
+		ActorID:    userID,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID.String(),
+		Metadata:   map[string]interface{}{"export_id": dataExport.ExportID.String()},
+	})
+
+	pendingExport := *dataExport
+	go u.runExport(&pendingExport)
+
+	return dataExport, nil
+}
+
+// Get export of user, download url is set when export is ready
+func (u *exportUC) GetExport(ctx context.Context, userID uuid.UUID, exportID uuid.UUID) (*models.DataExport, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "exportUC.GetExport")
+	defer span.Finish()
+
+	dataExport, err := u.redisRepo.GetExportCtx(ctx, u.generateExportKey(exportID.String()))
+	if err != nil {
+		return nil, httpErrors.NewNotFoundError(errors.Wrap(sql.ErrNoRows, "exportUC.GetExport.GetExportCtx"))
+	}
+	if dataExport.UserID != userID {
+		return nil, httpErrors.NewNotFoundError(errors.Wrap(sql.ErrNoRows, "exportUC.GetExport.UserID"))
+	}
+
+	return dataExport, nil
+}
+
+// Build export detached from request and store its final status, failures are logged
+func (u *exportUC) runExport(dataExport *models.DataExport) {
+	defer u.releaseExportLock(u.generateExportLockKey(dataExport.UserID.String()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(u.exportTimeout())*time.Second)
+	defer cancel()
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, "exportUC.runExport")
+	defer span.Finish()
+
+	if err := u.buildExport(ctx, dataExport); err != nil {
+		u.logger.Errorf("exportUC.runExport.buildExport ExportID: %s, ERROR: %v", dataExport.ExportID.String(), err)
+		completedAt := time.Now().UTC()
+		dataExport.Status = models.DataExportFailed
+		dataExport.CompletedAt = &completedAt
+	}
+
+	// Export context may be already expired, final status is stored anyway
+	if err := u.redisRepo.SetExportCtx(context.Background(), u.generateExportKey(dataExport.ExportID.String()), u.linkExpire(), dataExport); err != nil {
+		u.logger.Errorf("exportUC.runExport.SetExportCtx: %v", err)
+	}
+}
+
+// Assemble ZIP of JSON files with profile, news, comments and sessions metadata and the avatar object,
+// upload it to export bucket and set time limited download url
+func (u *exportUC) buildExport(ctx context.Context, dataExport *models.DataExport) error {
+	user, err := u.authRepo.GetByID(ctx, dataExport.UserID)
+	if err != nil {
+		return errors.Wrap(err, "exportUC.buildExport.GetByID")
+	}
+	user.SanitizePassword()
+
+	news, err := u.exportRepo.GetNewsByAuthorID(ctx, dataExport.UserID)
+	if err != nil {
+		return err
+	}
+	comments, err := u.exportRepo.GetCommentsByAuthorID(ctx, dataExport.UserID)
+	if err != nil {
+		return err
+	}
+	sessions, err := u.sessRepo.GetSessionsByUserID(ctx, dataExport.UserID)
+	if err != nil {
+		return errors.Wrap(err, "exportUC.buildExport.GetSessionsByUserID")
+	}
+	sessionsInfo := make([]*models.SessionInfo, 0, len(sessions))
+	for _, sess := range sessions {
+		sessionsInfo = append(sessionsInfo, &models.SessionInfo{
+			ID:           sess.ID,
+			IPAddress:    sess.IPAddress,
+			UserAgent:    sess.UserAgent,
+			CreatedAt:    sess.CreatedAt,
+			LastSeen:     sess.LastSeen,
+			Impersonated: sess.IsImpersonation(),
+		})
+	}
+
+	buf := &bytes.Buffer{}
+	zipWriter := zip.NewWriter(buf)
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{name: "profile.json", data: user},
+		{name: "news.json", data: news},
+		{name: "comments.json", data: comments},
+		{name: "sessions.json", data: sessionsInfo},
+	}
+	for _, file := range files {
+		if err = writeJSONFile(zipWriter, file.name, file.data); err != nil {
+			return err
+		}
+	}
+	if user.Avatar != nil {
+		if err = u.writeAvatar(ctx, zipWriter, *user.Avatar); err != nil {
+			return err
+		}
+	}
+	if err = zipWriter.Close(); err != nil {
+		return errors.Wrap(err, "exportUC.buildExport.zipWriter.Close")
+	}
+
+	key := fmt.Sprintf("%s/%s.zip", dataExport.UserID.String(), dataExport.ExportID.String())
+	if err = u.awsRepo.PutObject(ctx, u.cfg.Export.Bucket, key, buf, int64(buf.Len()), exportContentType); err != nil {
+		return err
+	}
+
+	linkExpire := time.Duration(u.linkExpire()) * time.Second
+	downloadURL, err := u.awsRepo.PresignedGetObject(ctx, u.cfg.Export.Bucket, key, linkExpire)
+	if err != nil {
+		return err
+	}
+
+	completedAt := time.Now().UTC()
+	expiresAt := completedAt.Add(linkExpire)
+	dataExport.Status = models.DataExportReady
+	dataExport.URL = downloadURL
+	dataExport.CompletedAt = &completedAt
+	dataExport.ExpiresAt = &expiresAt
+
+	return nil
+}
+
+// Copy avatar object into archive, avatars stored outside of our object store are skipped
+func (u *exportUC) writeAvatar(ctx context.Context, zipWriter *zip.Writer, avatarURL string) error {
+	bucket, key, ok := u.parseAWSMinioURL(avatarURL)
+	if !ok {
+		return nil
+	}
+
+	object, err := u.authAWSRepo.GetObject(ctx, bucket, key)
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+
+	fileWriter, err := zipWriter.Create(path.Join("avatar", path.Base(key)))
+	if err != nil {
+		return errors.Wrap(err, "exportUC.writeAvatar.zipWriter.Create")
+	}
+	if _, err = io.Copy(fileWriter, object); err != nil {
+		return errors.Wrap(err, "exportUC.writeAvatar.Copy")
+	}
+	return nil
+}
+
+// Bucket and key of url generated for uploaded avatar
+func (u *exportUC) parseAWSMinioURL(objectURL string) (string, string, bool) {
+	prefix := fmt.Sprintf("%s/minio/", u.cfg.AWS.MinioEndpoint)
+	if !strings.HasPrefix(objectURL, prefix) {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(objectURL, prefix), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func (u *exportUC) exportTimeout() int {
+	if u.cfg.Export.Timeout <= 0 {
+		return defaultExportTimeout
+	}
+	return u.cfg.Export.Timeout
+}
+
+func (u *exportUC) linkExpire() int {
+	if u.cfg.Export.LinkExpire <= 0 {
+		return defaultExportLinkExpire
+	}
+	return u.cfg.Export.LinkExpire
+}
+
+// Record audit event, failures are logged and do not fail the action
+func (u *exportUC) recordAuditEvent(ctx context.Context, event *models.AuditEvent) {
+	if err := u.auditUC.Record(ctx, event); err != nil {
+		u.logger.Errorf("exportUC.recordAuditEvent Action: %s, TargetID: %s, Error: %v", event.Action, event.TargetID, err)
+	}
+}
+
+// Release pending export lock, lock expires with export timeout if release fails
+func (u *exportUC) releaseExportLock(lockKey string) {
+	if err := u.redisRepo.DeleteExportLockCtx(context.Background(), lockKey); err != nil {
+		u.logger.Errorf("exportUC.releaseExportLock.DeleteExportLockCtx: %v", err)
+	}
+}
+
+func (u *exportUC) generateExportKey(exportID string) string {
+	return fmt.Sprintf("%s: %s", exportPrefix, exportID)
+}
+
+func (u *exportUC) generateExportLockKey(userID string) string {
+	return fmt.Sprintf("%s: %s", exportLockPrefix, userID)
+}
+
+func writeJSONFile(zipWriter *zip.Writer, name string, data interface{}) error {
+	fileWriter, err := zipWriter.Create(name)
+	if err != nil {
+		return errors.Wrap(err, "exportUC.writeJSONFile.zipWriter.Create")
+	}
+	encoder := json.NewEncoder(fileWriter)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(data); err != nil {
+		return errors.Wrap(err, "exportUC.writeJSONFile.Encode")
+	}
+	return nil
+}
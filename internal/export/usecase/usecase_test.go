@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/api-mc/config"
+	auditMock "github.com/AleksK1NG/api-mc/internal/audit/mock"
+	authMock "github.com/AleksK1NG/api-mc/internal/auth/mock"
+	"github.com/AleksK1NG/api-mc/internal/export/mock"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	sessionMock "github.com/AleksK1NG/api-mc/internal/session/mock"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+func TestExportUC_CreateExport(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{Export: config.Export{Bucket: "exports", LinkExpire: 3600}}
+	apiLogger := logger.NewApiLogger(&config.Config{Logger: config.Logger{Development: true, Encoding: "json"}})
+	apiLogger.InitLogger()
+	mockAuthRepo := authMock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	exportUC := NewExportUseCase(cfg, nil, mockRedisRepo, nil, mockAuthRepo, nil, nil, mockAuditUC, apiLogger)
+
+	t.Run("User not found", func(t *testing.T) {
+		userID := uuid.New()
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), userID).Return(nil, errors.Wrap(sql.ErrNoRows, "authRepo.GetByID.QueryRowxContext"))
+
+		dataExport, err := exportUC.CreateExport(context.Background(), userID)
+		require.Nil(t, dataExport)
+		require.Equal(t, http.StatusNotFound, httpErrors.ParseErrors(err).Status())
+	})
+
+	t.Run("Create export", func(t *testing.T) {
+		user := &models.User{UserID: uuid.New()}
+		lockKey := fmt.Sprintf("%s: %s", exportLockPrefix, user.UserID.String())
+		released := make(chan struct{})
+
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil)
+		mockRedisRepo.EXPECT().SetExportLockCtx(gomock.Any(), lockKey, defaultExportTimeout, gomock.Any()).Return(true, nil)
+		mockRedisRepo.EXPECT().SetExportCtx(gomock.Any(), gomock.Any(), defaultExportTimeout+cfg.Export.LinkExpire, gomock.Any()).Return(nil)
+		mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event *models.AuditEvent) error {
+			require.Equal(t, models.AuditActionDataExport, event.Action)
+			require.Equal(t, user.UserID, event.ActorID)
+			require.Equal(t, user.UserID.String(), event.TargetID)
+			return nil
+		})
+
+		// Background export fails on user lookup, failed status is stored and lock is released
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), user.UserID).Return(nil, errors.New("connection refused"))
+		mockRedisRepo.EXPECT().SetExportCtx(gomock.Any(), gomock.Any(), cfg.Export.LinkExpire, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, key string, seconds int, dataExport *models.DataExport) error {
+				require.Equal(t, models.DataExportFailed, dataExport.Status)
+				return nil
+			})
+		mockRedisRepo.EXPECT().DeleteExportLockCtx(gomock.Any(), lockKey).DoAndReturn(func(ctx context.Context, key string) error {
+			close(released)
+			return nil
+		})
+
+		dataExport, err := exportUC.CreateExport(context.Background(), user.UserID)
+		require.NoError(t, err)
+		require.Equal(t, models.DataExportPending, dataExport.Status)
+
+		select {
+		case <-released:
+		case <-time.After(5 * time.Second):
+			t.Fatal("export lock was not released")
+		}
+	})
+
+	t.Run("Export already pending", func(t *testing.T) {
+		user := &models.User{UserID: uuid.New()}
+		lockKey := fmt.Sprintf("%s: %s", exportLockPrefix, user.UserID.String())
+
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil)
+		mockRedisRepo.EXPECT().SetExportLockCtx(gomock.Any(), lockKey, defaultExportTimeout, gomock.Any()).Return(false, nil)
+
+		dataExport, err := exportUC.CreateExport(context.Background(), user.UserID)
+		require.Nil(t, dataExport)
+		require.Equal(t, http.StatusConflict, httpErrors.ParseErrors(err).Status())
+	})
+
+	t.Run("Lock is released when export is not stored", func(t *testing.T) {
+		user := &models.User{UserID: uuid.New()}
+		lockKey := fmt.Sprintf("%s: %s", exportLockPrefix, user.UserID.String())
+
+		mockAuthRepo.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil)
+		mockRedisRepo.EXPECT().SetExportLockCtx(gomock.Any(), lockKey, defaultExportTimeout, gomock.Any()).Return(true, nil)
+		mockRedisRepo.EXPECT().SetExportCtx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("connection refused"))
+		mockRedisRepo.EXPECT().DeleteExportLockCtx(gomock.Any(), lockKey).Return(nil)
+
+		dataExport, err := exportUC.CreateExport(context.Background(), user.UserID)
+		require.Nil(t, dataExport)
+		require.Equal(t, http.StatusInternalServerError, httpErrors.ParseErrors(err).Status())
+	})
+}
+
+func TestExportUC_linkExpire(t *testing.T) {
+	t.Parallel()
+
+	uc := NewExportUseCase(&config.Config{}, nil, nil, nil, nil, nil, nil, nil, nil).(*exportUC)
+	require.Equal(t, defaultExportLinkExpire, uc.linkExpire())
+
+	uc = NewExportUseCase(&config.Config{Export: config.Export{LinkExpire: 60}}, nil, nil, nil, nil, nil, nil, nil, nil).(*exportUC)
+	require.Equal(t, 60, uc.linkExpire())
+}
+
+func TestExportUC_GetExport(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{Export: config.Export{Bucket: "exports", LinkExpire: 3600}}
+	apiLogger := logger.NewApiLogger(nil)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	exportUC := NewExportUseCase(cfg, nil, mockRedisRepo, nil, nil, nil, nil, nil, apiLogger)
+
+	stored := &models.DataExport{ExportID: uuid.New(), UserID: uuid.New(), Status: models.DataExportReady, URL: "https://localhost/exports/export.zip"}
+	key := fmt.Sprintf("%s: %s", exportPrefix, stored.ExportID.String())
+
+	t.Run("Get export", func(t *testing.T) {
+		mockRedisRepo.EXPECT().GetExportCtx(gomock.Any(), key).Return(stored, nil)
+
+		dataExport, err := exportUC.GetExport(context.Background(), stored.UserID, stored.ExportID)
+		require.NoError(t, err)
+		require.Equal(t, stored.URL, dataExport.URL)
+	})
+
+	t.Run("Export of other user", func(t *testing.T) {
+		mockRedisRepo.EXPECT().GetExportCtx(gomock.Any(), key).Return(stored, nil)
+
+		dataExport, err := exportUC.GetExport(context.Background(), uuid.New(), stored.ExportID)
+		require.Nil(t, dataExport)
+		require.Equal(t, http.StatusNotFound, httpErrors.ParseErrors(err).Status())
+	})
+
+	t.Run("Export expired", func(t *testing.T) {
+		mockRedisRepo.EXPECT().GetExportCtx(gomock.Any(), key).Return(nil, redis.Nil)
+
+		dataExport, err := exportUC.GetExport(context.Background(), stored.UserID, stored.ExportID)
+		require.Nil(t, dataExport)
+		require.Equal(t, http.StatusNotFound, httpErrors.ParseErrors(err).Status())
+	})
+}
+
+func TestExportUC_buildExport(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{Export: config.Export{Bucket: "exports", LinkExpire: 3600}}
+	apiLogger := logger.NewApiLogger(nil)
+	mockExportRepo := mock.NewMockRepository(ctrl)
+	mockAWSRepo := mock.NewMockAWSRepository(ctrl)
+	mockAuthRepo := authMock.NewMockRepository(ctrl)
+	mockSessRepo := sessionMock.NewMockSessRepository(ctrl)
+	uc := NewExportUseCase(cfg, mockExportRepo, nil, mockAWSRepo, mockAuthRepo, nil, mockSessRepo, nil, apiLogger).(*exportUC)
+
+	user := &models.User{UserID: uuid.New(), FirstName: "Alex", Email: "email@gmail.com"}
+	dataExport := &models.DataExport{ExportID: uuid.New(), UserID: user.UserID, Status: models.DataExportPending}
+	objectKey := fmt.Sprintf("%s/%s.zip", user.UserID.String(), dataExport.ExportID.String())
+
+	mockAuthRepo.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil)
+	mockExportRepo.EXPECT().GetNewsByAuthorID(gomock.Any(), user.UserID).Return([]*models.News{{NewsID: uuid.New(), AuthorID: user.UserID, Title: "title"}}, nil)
+	mockExportRepo.EXPECT().GetCommentsByAuthorID(gomock.Any(), user.UserID).Return([]*models.Comment{}, nil)
+	mockSessRepo.EXPECT().GetSessionsByUserID(gomock.Any(), user.UserID).Return([]*models.Session{{ID: "public-id", SessionID: "secret", UserID: user.UserID}}, nil)
+
+	var archive []byte
+	mockAWSRepo.EXPECT().PutObject(gomock.Any(), "exports", objectKey, gomock.Any(), gomock.Any(), exportContentType).DoAndReturn(
+		func(ctx context.Context, bucket string, key string, reader io.Reader, size int64, contentType string) error {
+			var err error
+			archive, err = ioutil.ReadAll(reader)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(archive)), size)
+			return nil
+		})
+	mockAWSRepo.EXPECT().PresignedGetObject(gomock.Any(), "exports", objectKey, time.Hour).Return("https://localhost/exports/signed", nil)
+
+	err := uc.buildExport(context.Background(), dataExport)
+	require.NoError(t, err)
+	require.Equal(t, models.DataExportReady, dataExport.Status)
+	require.Equal(t, "https://localhost/exports/signed", dataExport.URL)
+	require.NotNil(t, dataExport.ExpiresAt)
+
+	zipReader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	files := make(map[string][]byte)
+	for _, file := range zipReader.File {
+		reader, err := file.Open()
+		require.NoError(t, err)
+		files[file.Name], err = ioutil.ReadAll(reader)
+		require.NoError(t, err)
+		reader.Close()
+	}
+	require.Len(t, files, 4)
+
+	profile := &models.User{}
+	require.NoError(t, json.Unmarshal(files["profile.json"], profile))
+	require.Equal(t, user.Email, profile.Email)
+
+	sessions := make([]*models.SessionInfo, 0)
+	require.NoError(t, json.Unmarshal(files["sessions.json"], &sessions))
+	require.Len(t, sessions, 1)
+	require.Equal(t, "public-id", sessions[0].ID)
+	require.NotContains(t, string(files["sessions.json"]), "secret")
+}
+
+func TestExportUC_parseAWSMinioURL(t *testing.T) {
+	t.Parallel()
+
+	uc := &exportUC{cfg: &config.Config{AWS: config.AWS{MinioEndpoint: "http://127.0.0.1:9000"}}}
+
+	bucket, key, ok := uc.parseAWSMinioURL("http://127.0.0.1:9000/minio/avatars/uuid-avatar.png")
+	require.True(t, ok)
+	require.Equal(t, "avatars", bucket)
+	require.Equal(t, "uuid-avatar.png", key)
+
+	_, _, ok = uc.parseAWSMinioURL("https://gravatar.com/avatar/hash")
+	require.False(t, ok)
+}
@@ -0,0 +1,334 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+// Auth mechanisms
+const (
+	AuthMechanismSession = "session"
+	AuthMechanismJWT     = "jwt"
+	AuthMechanismAPIKey  = "api_key"
+)
+
+const (
+	// Context key of mechanism which authenticated the request
+	authMechanismKey = "auth_mechanism"
+	// Context key of admin impersonating the authenticated user
+	impersonatorIDKey = "impersonator_id"
+	apiKeyHeader      = "X-API-Key"
+	bearerScheme      = "Bearer "
+	// Response header set on every response of impersonation session, value is impersonating admin id
+	ImpersonatedByHeader = "X-Impersonated-By"
+)
+
+// Authenticated request identity
+type authIdentity struct {
+	mechanism   string
+	user        *models.User
+	sid         string
+	uid         string
+	apiKey      *models.APIKey
+	permissions *models.UserPermissions
+	// Set for impersonation sessions
+	impersonatorID *uuid.UUID
+}
+
+// Authenticator returns nil identity and nil error when request has no credentials of its type
+type authenticator func(c echo.Context) (*authIdentity, error)
+
+// Auth middleware, tries configured auth mechanisms in order, API keys are not accepted
+func (mw *MiddlewareManager) AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return mw.authChain(mw.authMechanisms(), "", false)(next)
+}
+
+// Auth middleware of public routes, request without valid credentials of active user continues anonymously
+func (mw *MiddlewareManager) OptionalAuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return mw.authChain(mw.authMechanisms(), "", true)(next)
+}
+
+// Auth middleware accepting also API keys with given scope
+func (mw *MiddlewareManager) ScopedAuthMiddleware(scope string) echo.MiddlewareFunc {
+	return mw.authChain(mw.authMechanisms(), scope, false)
+}
+
+// Auth sessions middleware using redis
+func (mw *MiddlewareManager) AuthSessionMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return mw.authChain([]string{AuthMechanismSession}, "", false)(next)
+}
+
+// JWT way of auth using Authorization Bearer header
+func (mw *MiddlewareManager) AuthJWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return mw.authChain([]string{AuthMechanismJWT}, "", false)(next)
+}
+
+// Get mechanism which authenticated the request
+func GetAuthMechanism(c echo.Context) string {
+	mechanism, _ := c.Get(authMechanismKey).(string)
+	return mechanism
+}
+
+// Get admin impersonating the authenticated user, false if request is not impersonated
+func GetImpersonatorID(c echo.Context) (uuid.UUID, bool) {
+	impersonatorID, ok := c.Get(impersonatorIDKey).(uuid.UUID)
+	return impersonatorID, ok
+}
+
+// Block sensitive actions in impersonation sessions
+func (mw *MiddlewareManager) NotImpersonatingMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if impersonatorID, ok := GetImpersonatorID(c); ok {
+			mw.logger.Errorf("NotImpersonatingMiddleware RequestID: %s, ImpersonatorID: %s, Error: %s",
+				utils.GetRequestID(c),
+				impersonatorID.String(),
+				httpErrors.ImpersonationBlocked.Error(),
+			)
+			return c.JSON(http.StatusForbidden, httpErrors.NewRestError(http.StatusForbidden, httpErrors.ImpersonationBlocked.Error(), nil))
+		}
+		return next(c)
+	}
+}
+
+func (mw *MiddlewareManager) authChain(mechanisms []string, scope string, optional bool) echo.MiddlewareFunc {
+	authenticators := map[string]authenticator{
+		AuthMechanismSession: mw.authenticateSession,
+		AuthMechanismJWT:     mw.authenticateJWT,
+		AuthMechanismAPIKey:  mw.authenticateAPIKey,
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			deny := func(code int, body interface{}) error {
+				if optional {
+					return next(c)
+				}
+				return c.JSON(code, body)
+			}
+
+			for _, mechanism := range mechanisms {
+				if mechanism == AuthMechanismAPIKey && scope == "" {
+					continue
+				}
+				authenticate, ok := authenticators[mechanism]
+				if !ok {
+					continue
+				}
+
+				identity, err := authenticate(c)
+				if err != nil {
+					mw.logger.Errorf("AuthMiddleware RequestID: %s, Mechanism: %s, Error: %s",
+						utils.GetRequestID(c),
+						mechanism,
+						err.Error(),
+					)
+					continue
+				}
+				if identity == nil {
+					continue
+				}
+
+				if identity.apiKey != nil && !identity.apiKey.HasScope(scope) {
+					mw.logger.Errorf("AuthMiddleware RequestID: %s, APIKeyID: %s, Scope: %s, Error: %s",
+						utils.GetRequestID(c),
+						identity.apiKey.APIKeyID.String(),
+						scope,
+						httpErrors.APIKeyScopeRequired.Error(),
+					)
+					return deny(http.StatusForbidden, httpErrors.NewForbiddenError(httpErrors.APIKeyScopeRequired))
+				}
+
+				if !identity.user.IsActive() {
+					statusErr := httpErrors.AccountSuspended
+					if identity.user.Status == models.UserStatusBanned {
+						statusErr = httpErrors.AccountBanned
+					}
+					mw.logger.Errorf("AuthMiddleware RequestID: %s, UserID: %s, Error: %s",
+						utils.GetRequestID(c),
+						identity.user.UserID.String(),
+						statusErr.Error(),
+					)
+					return deny(http.StatusForbidden, httpErrors.NewRestError(http.StatusForbidden, statusErr.Error(), nil))
+				}
+
+				if !mw.isVerifiedAccessAllowed(c, identity.user) {
+					mw.logger.Errorf("AuthMiddleware RequestID: %s, UserID: %s, Error: %s",
+						utils.GetRequestID(c),
+						identity.user.UserID.String(),
+						httpErrors.EmailNotVerified.Error(),
+					)
+					return deny(http.StatusForbidden, httpErrors.NewForbiddenError(httpErrors.EmailNotVerified))
+				}
+
+				permissions, err := mw.authUC.GetPermissions(c.Request().Context(), identity.user)
+				if err != nil {
+					mw.logger.Errorf("AuthMiddleware RequestID: %s, UserID: %s, GetPermissions Error: %s",
+						utils.GetRequestID(c),
+						identity.user.UserID.String(),
+						err.Error(),
+					)
+					permissions = &models.UserPermissions{}
+				}
+				identity.permissions = permissions
+
+				mw.setIdentity(c, identity)
+
+				mw.logger.Info(
+					"AuthMiddleware, RequestID: %s,  IP: %s, UserID: %s, Mechanism: %s",
+					utils.GetRequestID(c),
+					utils.GetIPAddress(c),
+					identity.user.UserID.String(),
+					mechanism,
+				)
+
+				return next(c)
+			}
+
+			return deny(http.StatusUnauthorized, httpErrors.NewUnauthorizedError(httpErrors.Unauthorized))
+		}
+	}
+}
+
+// Set the same context values for all auth mechanisms
+func (mw *MiddlewareManager) setIdentity(c echo.Context, identity *authIdentity) {
+	c.Set(authMechanismKey, identity.mechanism)
+	c.Set("user", identity.user)
+	if identity.sid != "" {
+		c.Set("sid", identity.sid)
+		c.Set("uid", identity.uid)
+	}
+	if identity.apiKey != nil {
+		c.Set("api_key", identity.apiKey)
+	}
+	if identity.impersonatorID != nil {
+		c.Set(impersonatorIDKey, *identity.impersonatorID)
+		c.Response().Header().Set(ImpersonatedByHeader, identity.impersonatorID.String())
+	}
+
+	c.Set("permissions", identity.permissions)
+
+	ctx := context.WithValue(c.Request().Context(), utils.UserCtxKey{}, identity.user)
+	ctx = context.WithValue(ctx, utils.PermissionsCtxKey{}, identity.permissions)
+	if identity.impersonatorID != nil {
+		ctx = context.WithValue(ctx, utils.ImpersonatorCtxKey{}, *identity.impersonatorID)
+	}
+	c.SetRequest(c.Request().WithContext(ctx))
+}
+
+// Configured auth mechanisms, all mechanisms by default
+func (mw *MiddlewareManager) authMechanisms() []string {
+	if len(mw.cfg.Auth.Mechanisms) == 0 {
+		return []string{AuthMechanismSession, AuthMechanismJWT, AuthMechanismAPIKey}
+	}
+	return mw.cfg.Auth.Mechanisms
+}
+
+// Authenticate by session cookie
+func (mw *MiddlewareManager) authenticateSession(c echo.Context) (*authIdentity, error) {
+	cookie, err := c.Cookie(mw.cfg.Session.Name)
+	if err != nil {
+		return nil, nil
+	}
+
+	sess, err := mw.sessUC.GetSessionByID(c.Request().Context(), cookie.Value)
+	if err != nil {
+		return nil, errors.Wrap(err, "authenticateSession.GetSessionByID")
+	}
+
+	if time.Since(sess.LastSeen) > lastSeenInterval {
+		if err = mw.sessUC.UpdateLastSeen(c.Request().Context(), cookie.Value, time.Now()); err != nil {
+			mw.logger.Errorf("UpdateLastSeen RequestID: %s, Error: %s",
+				utils.GetRequestID(c),
+				err.Error(),
+			)
+		}
+	}
+
+	user, err := mw.authUC.GetByID(c.Request().Context(), sess.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "authenticateSession.GetByID")
+	}
+
+	return &authIdentity{
+		mechanism:      AuthMechanismSession,
+		user:           user,
+		sid:            cookie.Value,
+		uid:            sess.SessionID,
+		impersonatorID: sess.ImpersonatorID,
+	}, nil
+}
+
+// Authenticate by Authorization Bearer JWT
+func (mw *MiddlewareManager) authenticateJWT(c echo.Context) (*authIdentity, error) {
+	tokenString := extractBearer(c.Request())
+	if tokenString == "" || strings.HasPrefix(tokenString, models.APIKeyPrefix) {
+		return nil, nil
+	}
+
+	token, err := jwt.Parse(tokenString, mw.jwtKeys.Keyfunc)
+	if err != nil {
+		return nil, errors.Wrap(err, "authenticateJWT.Parse")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, httpErrors.InvalidJWTToken
+	}
+
+	userID, ok := claims["id"].(string)
+	if !ok {
+		return nil, httpErrors.InvalidJWTClaims
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "authenticateJWT.Parse")
+	}
+
+	user, err := mw.authUC.GetByID(c.Request().Context(), userUUID)
+	if err != nil {
+		return nil, errors.Wrap(err, "authenticateJWT.GetByID")
+	}
+
+	return &authIdentity{mechanism: AuthMechanismJWT, user: user}, nil
+}
+
+// Authenticate by X-API-Key header or Authorization Bearer API key
+func (mw *MiddlewareManager) authenticateAPIKey(c echo.Context) (*authIdentity, error) {
+	key := c.Request().Header.Get(apiKeyHeader)
+	if key == "" {
+		if bearer := extractBearer(c.Request()); strings.HasPrefix(bearer, models.APIKeyPrefix) {
+			key = bearer
+		}
+	}
+	if key == "" {
+		return nil, nil
+	}
+
+	user, apiKey, err := mw.authUC.AuthenticateAPIKey(c.Request().Context(), key)
+	if err != nil {
+		return nil, errors.Wrap(err, "authenticateAPIKey.AuthenticateAPIKey")
+	}
+
+	return &authIdentity{mechanism: AuthMechanismAPIKey, user: user, apiKey: apiKey}, nil
+}
+
+// Extract Authorization Bearer credentials
+func extractBearer(r *http.Request) string {
+	authorization := r.Header.Get(echo.HeaderAuthorization)
+	if !strings.HasPrefix(authorization, bearerScheme) {
+		return ""
+	}
+	return strings.TrimPrefix(authorization, bearerScheme)
+}
@@ -0,0 +1,459 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/api-mc/config"
+	authMock "github.com/AleksK1NG/api-mc/internal/auth/mock"
+	"github.com/AleksK1NG/api-mc/internal/models"
+	sessMock "github.com/AleksK1NG/api-mc/internal/session/mock"
+	"github.com/AleksK1NG/api-mc/pkg/csrf"
+	"github.com/AleksK1NG/api-mc/pkg/jwtkeys"
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
+)
+
+const (
+	testSessionCookie = "session_id"
+	testSessionID     = "session"
+	testAPIKey        = models.APIKeyPrefix + "secret"
+)
+
+func newTestConfig() *config.Config {
+	return &config.Config{
+		Server: config.ServerConfig{
+			JwtSecretKey:      "secret",
+			AccessTokenExpire: 60,
+			CSRF:              true,
+		},
+		Session: config.Session{
+			Name: testSessionCookie,
+		},
+		Logger: config.Logger{
+			Development: true,
+			Encoding:    "json",
+		},
+	}
+}
+
+func newTestMiddlewareManager(t *testing.T, ctrl *gomock.Controller, cfg *config.Config) (*MiddlewareManager, *authMock.MockUseCase, *sessMock.MockUCSession) {
+	apiLogger := logger.NewApiLogger(cfg)
+	apiLogger.InitLogger()
+	jwtKeys, err := jwtkeys.NewKeySet(cfg)
+	require.NoError(t, err)
+
+	mockAuthUC := authMock.NewMockUseCase(ctrl)
+	mockSessUC := sessMock.NewMockUCSession(ctrl)
+	return NewMiddlewareManager(mockSessUC, mockAuthUC, cfg, jwtKeys, nil, apiLogger), mockAuthUC, mockSessUC
+}
+
+func TestMiddlewareManager_AuthChain(t *testing.T) {
+	t.Parallel()
+
+	verifiedAt := time.Now()
+	userID := uuid.New()
+	activeUser := func() *models.User {
+		return &models.User{UserID: userID, Email: "email@gmail.com", VerifiedAt: &verifiedAt}
+	}
+	permissions := &models.UserPermissions{Role: "user"}
+
+	jwtToken := func(t *testing.T, cfg *config.Config) string {
+		jwtKeys, err := jwtkeys.NewKeySet(cfg)
+		require.NoError(t, err)
+		token, err := utils.GenerateJWTToken(activeUser(), cfg, jwtKeys)
+		require.NoError(t, err)
+		return token
+	}
+
+	withSession := func(t *testing.T, cfg *config.Config, r *http.Request) {
+		r.AddCookie(&http.Cookie{Name: testSessionCookie, Value: testSessionID})
+	}
+	withJWT := func(t *testing.T, cfg *config.Config, r *http.Request) {
+		r.Header.Set(echo.HeaderAuthorization, bearerScheme+jwtToken(t, cfg))
+	}
+	withAPIKey := func(t *testing.T, cfg *config.Config, r *http.Request) {
+		r.Header.Set(apiKeyHeader, testAPIKey)
+	}
+
+	expectSession := func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession, user *models.User) {
+		sessUC.EXPECT().GetSessionByID(gomock.Any(), testSessionID).
+			Return(&models.Session{SessionID: "uid", UserID: user.UserID, LastSeen: time.Now()}, nil)
+		authUC.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil)
+	}
+	expectJWT := func(authUC *authMock.MockUseCase, user *models.User) {
+		authUC.EXPECT().GetByID(gomock.Any(), user.UserID).Return(user, nil)
+	}
+	expectAPIKey := func(authUC *authMock.MockUseCase, scopes ...string) {
+		authUC.EXPECT().AuthenticateAPIKey(gomock.Any(), testAPIKey).
+			Return(activeUser(), &models.APIKey{APIKeyID: uuid.New(), Scopes: scopes}, nil)
+	}
+	expectPermissions := func(authUC *authMock.MockUseCase) {
+		authUC.EXPECT().GetPermissions(gomock.Any(), gomock.Any()).Return(permissions, nil)
+	}
+
+	authMiddleware := func(mw *MiddlewareManager) echo.MiddlewareFunc { return mw.AuthMiddleware }
+	optionalMiddleware := func(mw *MiddlewareManager) echo.MiddlewareFunc { return mw.OptionalAuthMiddleware }
+	scopedMiddleware := func(scope string) func(mw *MiddlewareManager) echo.MiddlewareFunc {
+		return func(mw *MiddlewareManager) echo.MiddlewareFunc { return mw.ScopedAuthMiddleware(scope) }
+	}
+
+	cases := []struct {
+		name       string
+		config     func(cfg *config.Config)
+		middleware func(mw *MiddlewareManager) echo.MiddlewareFunc
+		method     string
+		request    []func(t *testing.T, cfg *config.Config, r *http.Request)
+		expect     func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession)
+		code       int
+		mechanism  string
+	}{
+		{
+			name:       "Session",
+			middleware: authMiddleware,
+			request:    []func(t *testing.T, cfg *config.Config, r *http.Request){withSession},
+			expect: func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession) {
+				expectSession(authUC, sessUC, activeUser())
+				expectPermissions(authUC)
+			},
+			code:      http.StatusOK,
+			mechanism: AuthMechanismSession,
+		},
+		{
+			name:       "Session updates last seen",
+			middleware: authMiddleware,
+			request:    []func(t *testing.T, cfg *config.Config, r *http.Request){withSession},
+			expect: func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession) {
+				sessUC.EXPECT().GetSessionByID(gomock.Any(), testSessionID).
+					Return(&models.Session{SessionID: "uid", UserID: userID, LastSeen: time.Now().Add(-time.Hour)}, nil)
+				sessUC.EXPECT().UpdateLastSeen(gomock.Any(), testSessionID, gomock.Any()).Return(nil)
+				authUC.EXPECT().GetByID(gomock.Any(), userID).Return(activeUser(), nil)
+				expectPermissions(authUC)
+			},
+			code:      http.StatusOK,
+			mechanism: AuthMechanismSession,
+		},
+		{
+			name:       "JWT",
+			middleware: authMiddleware,
+			request:    []func(t *testing.T, cfg *config.Config, r *http.Request){withJWT},
+			expect: func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession) {
+				expectJWT(authUC, activeUser())
+				expectPermissions(authUC)
+			},
+			code:      http.StatusOK,
+			mechanism: AuthMechanismJWT,
+		},
+		{
+			name:       "Session is tried before JWT",
+			middleware: authMiddleware,
+			request:    []func(t *testing.T, cfg *config.Config, r *http.Request){withSession, withJWT},
+			expect: func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession) {
+				expectSession(authUC, sessUC, activeUser())
+				expectPermissions(authUC)
+			},
+			code:      http.StatusOK,
+			mechanism: AuthMechanismSession,
+		},
+		{
+			name:       "Invalid session falls back to JWT",
+			middleware: authMiddleware,
+			request:    []func(t *testing.T, cfg *config.Config, r *http.Request){withSession, withJWT},
+			expect: func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession) {
+				sessUC.EXPECT().GetSessionByID(gomock.Any(), testSessionID).Return(nil, errors.New("not found"))
+				expectJWT(authUC, activeUser())
+				expectPermissions(authUC)
+			},
+			code:      http.StatusOK,
+			mechanism: AuthMechanismJWT,
+		},
+		{
+			name:       "Configured mechanisms only",
+			config:     func(cfg *config.Config) { cfg.Auth.Mechanisms = []string{AuthMechanismJWT} },
+			middleware: authMiddleware,
+			request:    []func(t *testing.T, cfg *config.Config, r *http.Request){withSession},
+			code:       http.StatusUnauthorized,
+		},
+		{
+			name:       "API key with scope",
+			middleware: scopedMiddleware(models.APIKeyScopeNewsWrite),
+			request:    []func(t *testing.T, cfg *config.Config, r *http.Request){withAPIKey},
+			expect: func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession) {
+				expectAPIKey(authUC, models.APIKeyScopeNewsWrite)
+				expectPermissions(authUC)
+			},
+			code:      http.StatusOK,
+			mechanism: AuthMechanismAPIKey,
+		},
+		{
+			name:       "API key as bearer",
+			middleware: scopedMiddleware(models.APIKeyScopeNewsWrite),
+			request: []func(t *testing.T, cfg *config.Config, r *http.Request){func(t *testing.T, cfg *config.Config, r *http.Request) {
+				r.Header.Set(echo.HeaderAuthorization, bearerScheme+testAPIKey)
+			}},
+			expect: func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession) {
+				expectAPIKey(authUC, models.APIKeyScopeNewsWrite)
+				expectPermissions(authUC)
+			},
+			code:      http.StatusOK,
+			mechanism: AuthMechanismAPIKey,
+		},
+		{
+			name:       "API key is not accepted without scope",
+			middleware: authMiddleware,
+			request:    []func(t *testing.T, cfg *config.Config, r *http.Request){withAPIKey},
+			code:       http.StatusUnauthorized,
+		},
+		{
+			name:       "API key without required scope",
+			middleware: scopedMiddleware(models.APIKeyScopeCommentsWrite),
+			request:    []func(t *testing.T, cfg *config.Config, r *http.Request){withAPIKey},
+			expect: func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession) {
+				expectAPIKey(authUC, models.APIKeyScopeNewsWrite)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:       "Invalid API key",
+			middleware: scopedMiddleware(models.APIKeyScopeNewsWrite),
+			request:    []func(t *testing.T, cfg *config.Config, r *http.Request){withAPIKey},
+			expect: func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession) {
+				authUC.EXPECT().AuthenticateAPIKey(gomock.Any(), testAPIKey).Return(nil, nil, errors.New("invalid api key"))
+			},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name:       "No credentials",
+			middleware: authMiddleware,
+			code:       http.StatusUnauthorized,
+		},
+		{
+			name:       "Invalid JWT",
+			middleware: authMiddleware,
+			request: []func(t *testing.T, cfg *config.Config, r *http.Request){func(t *testing.T, cfg *config.Config, r *http.Request) {
+				r.Header.Set(echo.HeaderAuthorization, bearerScheme+"invalid")
+			}},
+			code: http.StatusUnauthorized,
+		},
+		{
+			name:       "Suspended user",
+			middleware: authMiddleware,
+			request:    []func(t *testing.T, cfg *config.Config, r *http.Request){withJWT},
+			expect: func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession) {
+				user := activeUser()
+				user.Status = models.UserStatusSuspended
+				expectJWT(authUC, user)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:       "Banned user",
+			middleware: authMiddleware,
+			request:    []func(t *testing.T, cfg *config.Config, r *http.Request){withSession},
+			expect: func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession) {
+				user := activeUser()
+				user.Status = models.UserStatusBanned
+				expectSession(authUC, sessUC, user)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:       "Unverified user denied",
+			config:     func(cfg *config.Config) { cfg.Server.UnverifiedAccess = unverifiedAccessDeny },
+			middleware: authMiddleware,
+			request:    []func(t *testing.T, cfg *config.Config, r *http.Request){withJWT},
+			expect: func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession) {
+				user := activeUser()
+				user.VerifiedAt = nil
+				expectJWT(authUC, user)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:       "Unverified user read only safe method",
+			config:     func(cfg *config.Config) { cfg.Server.UnverifiedAccess = unverifiedAccessReadOnly },
+			middleware: authMiddleware,
+			method:     http.MethodGet,
+			request:    []func(t *testing.T, cfg *config.Config, r *http.Request){withJWT},
+			expect: func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession) {
+				user := activeUser()
+				user.VerifiedAt = nil
+				expectJWT(authUC, user)
+				expectPermissions(authUC)
+			},
+			code:      http.StatusOK,
+			mechanism: AuthMechanismJWT,
+		},
+		{
+			name:       "Unverified user read only unsafe method",
+			config:     func(cfg *config.Config) { cfg.Server.UnverifiedAccess = unverifiedAccessReadOnly },
+			middleware: authMiddleware,
+			method:     http.MethodPost,
+			request:    []func(t *testing.T, cfg *config.Config, r *http.Request){withJWT},
+			expect: func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession) {
+				user := activeUser()
+				user.VerifiedAt = nil
+				expectJWT(authUC, user)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:       "Optional without credentials",
+			middleware: optionalMiddleware,
+			code:       http.StatusOK,
+		},
+		{
+			name:       "Optional with suspended user continues anonymously",
+			middleware: optionalMiddleware,
+			request:    []func(t *testing.T, cfg *config.Config, r *http.Request){withJWT},
+			expect: func(authUC *authMock.MockUseCase, sessUC *sessMock.MockUCSession) {
+				user := activeUser()
+				user.Status = models.UserStatusSuspended
+				expectJWT(authUC, user)
+			},
+			code: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cfg := newTestConfig()
+			if tc.config != nil {
+				tc.config(cfg)
+			}
+			mw, mockAuthUC, mockSessUC := newTestMiddlewareManager(t, ctrl, cfg)
+			if tc.expect != nil {
+				tc.expect(mockAuthUC, mockSessUC)
+			}
+
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, "/api/v1/auth/me", nil)
+			for _, prepare := range tc.request {
+				prepare(t, cfg, req)
+			}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			var user *models.User
+			handler := func(c echo.Context) error {
+				user, _ = c.Get("user").(*models.User)
+				return c.String(http.StatusOK, GetAuthMechanism(c))
+			}
+
+			err := tc.middleware(mw)(handler)(c)
+			require.NoError(t, err)
+			require.Equal(t, tc.code, rec.Code)
+			if tc.code != http.StatusOK {
+				return
+			}
+			require.Equal(t, tc.mechanism, rec.Body.String())
+			if tc.mechanism == "" {
+				require.Nil(t, user)
+				return
+			}
+			require.NotNil(t, user)
+			ctxUser, err := utils.GetUserFromCtx(c.Request().Context())
+			require.NoError(t, err)
+			require.Equal(t, user.UserID, ctxUser.UserID)
+		})
+	}
+}
+
+func TestMiddlewareManager_CSRF(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name      string
+		disabled  bool
+		mechanism string
+		sid       string
+		token     func(mw *MiddlewareManager) string
+		code      int
+	}{
+		{
+			name:     "Disabled",
+			disabled: true,
+			code:     http.StatusOK,
+		},
+		{
+			name:      "Skipped for JWT",
+			mechanism: AuthMechanismJWT,
+			code:      http.StatusOK,
+		},
+		{
+			name:      "Skipped for API key",
+			mechanism: AuthMechanismAPIKey,
+			code:      http.StatusOK,
+		},
+		{
+			name:      "Session without token",
+			mechanism: AuthMechanismSession,
+			sid:       testSessionID,
+			code:      http.StatusForbidden,
+		},
+		{
+			name:      "Session with token of other session",
+			mechanism: AuthMechanismSession,
+			sid:       testSessionID,
+			token:     func(mw *MiddlewareManager) string { return csrf.MakeToken("other", mw.logger) },
+			code:      http.StatusForbidden,
+		},
+		{
+			name:      "Session with valid token",
+			mechanism: AuthMechanismSession,
+			sid:       testSessionID,
+			token:     func(mw *MiddlewareManager) string { return csrf.MakeToken(testSessionID, mw.logger) },
+			code:      http.StatusOK,
+		},
+		{
+			name: "Unauthenticated without token",
+			code: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cfg := newTestConfig()
+			cfg.Server.CSRF = !tc.disabled
+			mw, _, _ := newTestMiddlewareManager(t, ctrl, cfg)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/news/create", nil)
+			if tc.token != nil {
+				req.Header.Set(csrf.CSRFHeader, tc.token(mw))
+			}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+			if tc.mechanism != "" {
+				c.Set(authMechanismKey, tc.mechanism)
+			}
+			if tc.sid != "" {
+				c.Set("sid", tc.sid)
+			}
+
+			err := mw.CSRF(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})(c)
+			require.NoError(t, err)
+			require.Equal(t, tc.code, rec.Code)
+		})
+	}
+}
@@ -17,6 +17,11 @@ func (mw *MiddlewareManager) CSRF(next echo.HandlerFunc) echo.HandlerFunc {
 			return next(ctx)
 		}
 
+		// CSRF is enforced only for cookie based session auth
+		if mechanism := GetAuthMechanism(ctx); mechanism != "" && mechanism != AuthMechanismSession {
+			return next(ctx)
+		}
+
 		token := ctx.Request().Header.Get(csrf.CSRFHeader)
 		if token == "" {
 			mw.logger.Errorf("CSRF Middleware get CSRF header, Token: %s, Error: %s, RequestId: %s",
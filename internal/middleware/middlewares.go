@@ -4,6 +4,7 @@ import (
 	"github.com/AleksK1NG/api-mc/config"
 	"github.com/AleksK1NG/api-mc/internal/auth"
 	"github.com/AleksK1NG/api-mc/internal/session"
+	"github.com/AleksK1NG/api-mc/pkg/jwtkeys"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 )
 
@@ -12,11 +13,12 @@ type MiddlewareManager struct {
 	sessUC  session.UCSession
 	authUC  auth.UseCase
 	cfg     *config.Config
+	jwtKeys *jwtkeys.KeySet
 	origins []string
 	logger  logger.Logger
 }
 
 // Middleware manager constructor
-func NewMiddlewareManager(sessUC session.UCSession, authUC auth.UseCase, cfg *config.Config, origins []string, logger logger.Logger) *MiddlewareManager {
-	return &MiddlewareManager{sessUC: sessUC, authUC: authUC, cfg: cfg, origins: origins, logger: logger}
+func NewMiddlewareManager(sessUC session.UCSession, authUC auth.UseCase, cfg *config.Config, jwtKeys *jwtkeys.KeySet, origins []string, logger logger.Logger) *MiddlewareManager {
+	return &MiddlewareManager{sessUC: sessUC, authUC: authUC, cfg: cfg, jwtKeys: jwtKeys, origins: origins, logger: logger}
 }
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Plain API key prefix, used to distinguish API keys from jwt bearer tokens
+const APIKeyPrefix = "amc_"
+
+// API key scopes
+const (
+	APIKeyScopeNewsWrite     = "news:write"
+	APIKeyScopeCommentsWrite = "comments:write"
+)
+
+// API key scopes stored as comma separated string
+type APIKeyScopes []string
+
+// Value implements driver.Valuer
+func (s APIKeyScopes) Value() (driver.Value, error) {
+	return strings.Join(s, ","), nil
+}
+
+// Scan implements sql.Scanner
+func (s *APIKeyScopes) Scan(src interface{}) error {
+	var value string
+	switch v := src.(type) {
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	case nil:
+		*s = APIKeyScopes{}
+		return nil
+	default:
+		return fmt.Errorf("APIKeyScopes.Scan: unsupported type %T", src)
+	}
+
+	if value == "" {
+		*s = APIKeyScopes{}
+		return nil
+	}
+	*s = strings.Split(value, ",")
+	return nil
+}
+
+// API key model, key itself is stored only as hash
+type APIKey struct {
+	APIKeyID   uuid.UUID    `json:"api_key_id" db:"api_key_id" validate:"omitempty"`
+	UserID     uuid.UUID    `json:"user_id" db:"user_id" validate:"omitempty"`
+	Name       string       `json:"name" db:"name" validate:"required,lte=64"`
+	Prefix     string       `json:"prefix" db:"prefix"`
+	KeyHash    string       `json:"-" db:"key_hash"`
+	Scopes     APIKeyScopes `json:"scopes" db:"scopes" validate:"required,dive,oneof=news:write comments:write"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty" db:"expires_at"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+}
+
+// Check API key has given scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Check API key is expired
+func (k *APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && k.ExpiresAt.Before(time.Now())
+}
+
+// Created API key, plain key is returned only once
+type APIKeyWithSecret struct {
+	APIKey *APIKey `json:"api_key"`
+	Key    string  `json:"key"`
+}
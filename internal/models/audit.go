@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit actions
+const (
+	AuditActionLogin              = "user.login"
+	AuditActionLoginFailed        = "user.login_failed"
+	AuditActionLogout             = "user.logout"
+	AuditActionUpdate             = "user.update"
+	AuditActionRoleChange         = "user.role_change"
+	AuditActionPasswordChange     = "user.password_change"
+	AuditActionPasswordReset      = "user.password_reset"
+	AuditActionEmailChange        = "user.email_change"
+	AuditActionEmailRevert        = "user.email_revert"
+	AuditActionStatusChange       = "user.status_change"
+	AuditActionImpersonationStart = "user.impersonation_start"
+	AuditActionImpersonationStop  = "user.impersonation_stop"
+	AuditActionDelete             = "user.delete"
+	AuditActionRestore            = "user.restore"
+	AuditActionDataExport         = "user.data_export"
+	AuditActionNewsUpdate         = "news.update"
+	AuditActionNewsDelete         = "news.delete"
+	AuditActionNewsStatusChange   = "news.status_change"
+	AuditActionCommentUpdate      = "comment.update"
+	AuditActionCommentDelete      = "comment.delete"
+)
+
+// Audit event target types
+const (
+	AuditTargetUser    = "user"
+	AuditTargetNews    = "news"
+	AuditTargetComment = "comment"
+)
+
+// Audit event of security relevant action, before and after are stored as changed fields only.
+// Nil actor is system.
+type AuditEvent struct {
+	EventID    uuid.UUID              `json:"event_id" db:"event_id"`
+	Action     string                 `json:"action" db:"action"`
+	ActorID    uuid.UUID              `json:"actor_id" db:"actor_id"`
+	TargetType string                 `json:"target_type" db:"target_type"`
+	TargetID   string                 `json:"target_id" db:"target_id"`
+	IPAddress  string                 `json:"ip_address" db:"ip_address"`
+	UserAgent  string                 `json:"user_agent" db:"user_agent"`
+	RequestID  string                 `json:"request_id" db:"request_id"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty" db:"-"`
+	Before     interface{}            `json:"before,omitempty" db:"-"`
+	After      interface{}            `json:"after,omitempty" db:"-"`
+	CreatedAt  time.Time              `json:"created_at" db:"created_at"`
+}
+
+// Audit events filter, empty fields are not filtered, time range is [from, to)
+type AuditEventsFilter struct {
+	ActorID    *uuid.UUID
+	TargetType string
+	TargetID   string
+	Action     string
+	From       *time.Time
+	To         *time.Time
+}
+
+// Audit events list
+type AuditEventsList struct {
+	TotalCount int           `json:"total_count"`
+	TotalPages int           `json:"total_pages"`
+	Page       int           `json:"page"`
+	Size       int           `json:"size"`
+	HasMore    bool          `json:"has_more"`
+	Events     []*AuditEvent `json:"events"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// External identity provider account linked to user
+type UserIdentity struct {
+	IdentityID uuid.UUID `json:"identity_id" db:"identity_id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	Provider   string    `json:"provider" db:"provider"`
+	Subject    string    `json:"subject" db:"subject"`
+	Email      *string   `json:"email,omitempty" db:"email"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// Pending OpenID Connect authorization request, stored in redis by state hash
+type OIDCAuthRequest struct {
+	Provider     string `json:"provider" redis:"provider"`
+	Nonce        string `json:"nonce" redis:"nonce"`
+	CodeVerifier string `json:"code_verifier" redis:"code_verifier"`
+}
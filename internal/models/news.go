@@ -6,6 +6,15 @@ import (
 	"github.com/google/uuid"
 )
 
+// News editorial statuses, only published news are public
+const (
+	NewsStatusDraft     = "draft"
+	NewsStatusInReview  = "in_review"
+	NewsStatusScheduled = "scheduled"
+	NewsStatusPublished = "published"
+	NewsStatusArchived  = "archived"
+)
+
 // News base model
 type News struct {
 	NewsID    uuid.UUID `json:"news_id" db:"news_id" validate:"omitempty,uuid"`
@@ -16,6 +25,25 @@ type News struct {
 	Category  *string   `json:"category,omitempty" db:"category" validate:"omitempty,lte=10"`
 	CreatedAt time.Time `json:"created_at,omitempty" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at"`
+	// Status is changed only by status transitions, news are created as draft
+	Status      string     `json:"status,omitempty" db:"status"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty" db:"scheduled_at"`
+	PublishedAt *time.Time `json:"published_at,omitempty" db:"published_at"`
+	// Text search configuration the search vector is built with
+	SearchLanguage string `json:"-" db:"search_language"`
+}
+
+// News status transition, scheduled time is required for scheduled status only
+type NewsStatusChange struct {
+	Status      string     `json:"status" validate:"required,oneof=draft in_review scheduled published archived"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+}
+
+// News list filter, viewer sees published news and own news of any status, with Unpublished all news
+type NewsFilter struct {
+	Status      string
+	ViewerID    *uuid.UUID
+	Unpublished bool
 }
 
 // All News response
@@ -30,12 +58,33 @@ type NewsList struct {
 
 // News base
 type NewsBase struct {
-	NewsID    uuid.UUID `json:"news_id" db:"news_id" validate:"omitempty,uuid"`
-	AuthorID  uuid.UUID `json:"author_id" db:"author_id" validate:"omitempty,uuid"`
-	Title     string    `json:"title" db:"title" validate:"required,gte=10"`
-	Content   string    `json:"content" db:"content" validate:"required,gte=20"`
-	ImageURL  *string   `json:"image_url,omitempty" db:"image_url" validate:"omitempty,lte=512,url"`
-	Category  *string   `json:"category,omitempty" db:"category" validate:"omitempty,lte=10"`
-	Author    string    `json:"author" db:"author"`
-	UpdatedAt time.Time `json:"updated_at,omitempty" db:"updated_at"`
+	NewsID      uuid.UUID  `json:"news_id" db:"news_id" validate:"omitempty,uuid"`
+	AuthorID    uuid.UUID  `json:"author_id" db:"author_id" validate:"omitempty,uuid"`
+	Title       string     `json:"title" db:"title" validate:"required,gte=10"`
+	Content     string     `json:"content" db:"content" validate:"required,gte=20"`
+	ImageURL    *string    `json:"image_url,omitempty" db:"image_url" validate:"omitempty,lte=512,url"`
+	Category    *string    `json:"category,omitempty" db:"category" validate:"omitempty,lte=10"`
+	Author      string     `json:"author" db:"author"`
+	UpdatedAt   time.Time  `json:"updated_at,omitempty" db:"updated_at"`
+	Status      string     `json:"status" db:"status"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty" db:"scheduled_at"`
+	PublishedAt *time.Time `json:"published_at,omitempty" db:"published_at"`
+}
+
+// News full-text search result, headlines are fragments with matched words wrapped in <b></b>
+type NewsSearchResult struct {
+	News
+	Rank          float64 `json:"rank" db:"rank"`
+	TitleHeadline string  `json:"title_headline" db:"title_headline"`
+	Headline      string  `json:"headline" db:"headline"`
+}
+
+// News full-text search response
+type NewsSearchList struct {
+	TotalCount int                 `json:"total_count"`
+	TotalPages int                 `json:"total_pages"`
+	Page       int                 `json:"page"`
+	Size       int                 `json:"size"`
+	HasMore    bool                `json:"has_more"`
+	News       []*NewsSearchResult `json:"news"`
 }
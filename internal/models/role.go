@@ -0,0 +1,60 @@
+package models
+
+import "strings"
+
+// Permissions
+const (
+	PermissionNewsPublish      = "news:publish"
+	PermissionNewsModerate     = "news:moderate"
+	PermissionCommentsModerate = "comments:moderate"
+	PermissionUsersUpdate      = "users:update"
+	PermissionUsersDelete      = "users:delete"
+	PermissionUsersUnlock      = "users:unlock"
+	PermissionUsersSessions    = "users:sessions"
+	PermissionUsersRoles       = "users:roles"
+	PermissionUsersStatus      = "users:status"
+	PermissionUsersImpersonate = "users:impersonate"
+	PermissionUsersExport      = "users:export"
+	PermissionAuditRead        = "audit:read"
+	// Permissions managing other users, holders are forced to use two factor when it is enabled by config
+	adminPermissionPrefix = "users:"
+)
+
+// Role model
+type Role struct {
+	Name        string   `json:"name" db:"name"`
+	Description string   `json:"description" db:"description"`
+	Permissions []string `json:"permissions" db:"-"`
+}
+
+// Role permission
+type RolePermission struct {
+	Role       string `json:"role" db:"role"`
+	Permission string `json:"permission" db:"permission"`
+}
+
+// User permissions set, cached together with the role it was resolved for
+type UserPermissions struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+// Check permission is in set
+func (p *UserPermissions) Has(permission string) bool {
+	for _, perm := range p.Permissions {
+		if perm == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Check set contains any permission managing other users
+func (p *UserPermissions) HasAdmin() bool {
+	for _, perm := range p.Permissions {
+		if strings.HasPrefix(perm, adminPermissionPrefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,38 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // Session model
 type Session struct {
 	SessionID string    `json:"session_id" redis:"session_id"`
+	ID        string    `json:"id" redis:"id"`
 	UserID    uuid.UUID `json:"user_id" redis:"user_id"`
+	IPAddress string    `json:"ip_address" redis:"ip_address"`
+	UserAgent string    `json:"user_agent" redis:"user_agent"`
+	CreatedAt time.Time `json:"created_at" redis:"created_at"`
+	LastSeen  time.Time `json:"last_seen" redis:"last_seen"`
+	// Admin signed in as session user, and session cookie of the admin restored when impersonation stops
+	ImpersonatorID        *uuid.UUID `json:"impersonator_id,omitempty" redis:"impersonator_id"`
+	ImpersonatorSessionID string     `json:"impersonator_session_id,omitempty" redis:"impersonator_session_id"`
+}
+
+// Check session is impersonation session
+func (s *Session) IsImpersonation() bool {
+	return s.ImpersonatorID != nil
+}
+
+// Active session info, public id is used instead of secret session id
+type SessionInfo struct {
+	ID        string    `json:"id"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+	LastSeen  time.Time `json:"last_seen"`
+	Current   bool      `json:"current"`
+	// Session was started by admin impersonating the user
+	Impersonated bool `json:"impersonated"`
 }
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Refresh token model, stored in redis by token hash
+type RefreshToken struct {
+	FamilyID  uuid.UUID `json:"family_id" redis:"family_id"`
+	UserID    uuid.UUID `json:"user_id" redis:"user_id"`
+	Used      bool      `json:"used" redis:"used"`
+	ExpiresAt time.Time `json:"expires_at" redis:"expires_at"`
+}
+
+// Single use user token, stored in redis by token hash
+type UserToken struct {
+	UserID uuid.UUID `json:"user_id" redis:"user_id"`
+	Email  string    `json:"email" redis:"email"`
+}
+
+// User TOTP two factor secret
+type UserTOTP struct {
+	UserID       uuid.UUID  `json:"user_id" db:"user_id"`
+	Secret       string     `json:"-" db:"secret"`
+	LastUsedStep int64      `json:"-" db:"last_used_step"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// TOTP enrolment response, secret is shown to the user only once
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// Recovery codes response, codes are shown to the user only once
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
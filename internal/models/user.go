@@ -5,44 +5,68 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
+
+	"github.com/AleksK1NG/api-mc/pkg/passwordhash"
 )
 
 // User full model
 type User struct {
-	UserID      uuid.UUID  `json:"user_id" db:"user_id" redis:"user_id" validate:"omitempty"`
-	FirstName   string     `json:"first_name" db:"first_name" redis:"first_name" validate:"required,lte=30"`
-	LastName    string     `json:"last_name" db:"last_name" redis:"last_name" validate:"required,lte=30"`
-	Email       string     `json:"email,omitempty" db:"email" redis:"email" validate:"omitempty,lte=60,email"`
-	Password    string     `json:"password,omitempty" db:"password" redis:"password" validate:"omitempty,required,gte=6"`
-	Role        *string    `json:"role,omitempty" db:"role" redis:"role" validate:"omitempty,lte=10"`
-	About       *string    `json:"about,omitempty" db:"about" redis:"about" validate:"omitempty,lte=1024"`
-	Avatar      *string    `json:"avatar,omitempty" db:"avatar" redis:"avatar" validate:"omitempty,lte=512,url"`
-	PhoneNumber *string    `json:"phone_number,omitempty" db:"phone_number" redis:"phone_number" validate:"omitempty,lte=20"`
-	Address     *string    `json:"address,omitempty" db:"address" redis:"address" validate:"omitempty,lte=250"`
-	City        *string    `json:"city,omitempty" db:"city" redis:"city" validate:"omitempty,lte=24"`
-	Country     *string    `json:"country,omitempty" db:"country" redis:"country" validate:"omitempty,lte=24"`
-	Gender      *string    `json:"gender,omitempty" db:"gender" redis:"gender" validate:"omitempty,lte=10"`
-	Postcode    *int       `json:"postcode,omitempty" db:"postcode" redis:"postcode" validate:"omitempty"`
-	Birthday    *time.Time `json:"birthday,omitempty" db:"birthday" redis:"birthday" validate:"omitempty,lte=10"`
-	CreatedAt   time.Time  `json:"created_at,omitempty" db:"created_at" redis:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at,omitempty" db:"updated_at" redis:"updated_at"`
-	LoginDate   time.Time  `json:"login_date" db:"login_date" redis:"login_date"`
-}
-
-// Hash user password with bcrypt
-func (u *User) HashPassword() error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+	UserID           uuid.UUID  `json:"user_id" db:"user_id" redis:"user_id" validate:"omitempty"`
+	FirstName        string     `json:"first_name" db:"first_name" redis:"first_name" validate:"required,lte=30"`
+	LastName         string     `json:"last_name" db:"last_name" redis:"last_name" validate:"required,lte=30"`
+	Email            string     `json:"email,omitempty" db:"email" redis:"email" validate:"omitempty,lte=60,email"`
+	Password         string     `json:"password,omitempty" db:"password" redis:"password" validate:"omitempty,required,gte=6"`
+	Role             *string    `json:"role,omitempty" db:"role" redis:"role" validate:"omitempty,lte=10"`
+	About            *string    `json:"about,omitempty" db:"about" redis:"about" validate:"omitempty,lte=1024"`
+	Avatar           *string    `json:"avatar,omitempty" db:"avatar" redis:"avatar" validate:"omitempty,lte=512,url"`
+	PhoneNumber      *string    `json:"phone_number,omitempty" db:"phone_number" redis:"phone_number" validate:"omitempty,lte=20"`
+	Address          *string    `json:"address,omitempty" db:"address" redis:"address" validate:"omitempty,lte=250"`
+	City             *string    `json:"city,omitempty" db:"city" redis:"city" validate:"omitempty,lte=24"`
+	Country          *string    `json:"country,omitempty" db:"country" redis:"country" validate:"omitempty,lte=24"`
+	Gender           *string    `json:"gender,omitempty" db:"gender" redis:"gender" validate:"omitempty,lte=10"`
+	Postcode         *int       `json:"postcode,omitempty" db:"postcode" redis:"postcode" validate:"omitempty"`
+	Birthday         *time.Time `json:"birthday,omitempty" db:"birthday" redis:"birthday" validate:"omitempty,lte=10"`
+	CreatedAt        time.Time  `json:"created_at,omitempty" db:"created_at" redis:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at,omitempty" db:"updated_at" redis:"updated_at"`
+	LoginDate        time.Time  `json:"login_date" db:"login_date" redis:"login_date"`
+	VerifiedAt       *time.Time `json:"verified_at,omitempty" db:"verified_at" redis:"verified_at"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled" db:"two_factor_enabled" redis:"two_factor_enabled"`
+	LockedUntil      *time.Time `json:"locked_until,omitempty" db:"locked_until" redis:"locked_until"`
+	PendingEmail     *string    `json:"pending_email,omitempty" db:"pending_email" redis:"pending_email"`
+	Status           string     `json:"status,omitempty" db:"status" redis:"status"`
+	StatusReason     *string    `json:"status_reason,omitempty" db:"status_reason" redis:"status_reason"`
+	StatusUntil      *time.Time `json:"status_until,omitempty" db:"status_until" redis:"status_until"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty" db:"deleted_at" redis:"deleted_at"`
+	PurgedAt         *time.Time `json:"purged_at,omitempty" db:"purged_at" redis:"purged_at"`
+}
+
+// Account statuses
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusBanned    = "banned"
+)
+
+// Account status change, status without expiry lasts until changed
+type UserStatus struct {
+	Status string     `json:"status" validate:"required,oneof=active suspended banned"`
+	Reason *string    `json:"reason,omitempty" validate:"omitempty,lte=250"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+// Hash user password with given hasher
+func (u *User) HashPassword(hasher passwordhash.Hasher) error {
+	hashedPassword, err := hasher.Hash(u.Password)
 	if err != nil {
 		return err
 	}
-	u.Password = string(hashedPassword)
+	u.Password = hashedPassword
 	return nil
 }
 
-// Compare user password and payload
-func (u *User) ComparePasswords(password string) error {
-	if err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)); err != nil {
+// Compare user password hash and payload
+func (u *User) ComparePasswords(hasher passwordhash.Hasher, password string) error {
+	if err := hasher.Compare(u.Password, password); err != nil {
 		return err
 	}
 	return nil
@@ -53,21 +77,47 @@ func (u *User) SanitizePassword() {
 	u.Password = ""
 }
 
+// Sanitize private account state, it is visible only to the user and admins
+func (u *User) SanitizeAccountState() {
+	u.LockedUntil = nil
+	u.PendingEmail = nil
+	u.Status = ""
+	u.StatusReason = nil
+	u.StatusUntil = nil
+}
+
+// Check user email is verified
+func (u *User) IsVerified() bool {
+	return u.VerifiedAt != nil
+}
+
+// Check user account is temporarily locked
+func (u *User) IsLocked() bool {
+	return u.LockedUntil != nil && u.LockedUntil.After(time.Now())
+}
+
+// Check account is not suspended or banned, expired status is treated as active
+func (u *User) IsActive() bool {
+	if u.Status == "" || u.Status == UserStatusActive {
+		return true
+	}
+	return u.StatusUntil != nil && !u.StatusUntil.After(time.Now())
+}
+
 // Prepare user for register
-func (u *User) PrepareCreate() error {
+func (u *User) PrepareCreate(hasher passwordhash.Hasher) error {
 	u.Email = strings.ToLower(strings.TrimSpace(u.Email))
 	u.Password = strings.TrimSpace(u.Password)
 
-	if err := u.HashPassword(); err != nil {
+	if err := u.HashPassword(hasher); err != nil {
 		return err
 	}
 
 	if u.PhoneNumber != nil {
 		*u.PhoneNumber = strings.TrimSpace(*u.PhoneNumber)
 	}
-	if u.Role != nil {
-		*u.Role = strings.ToLower(strings.TrimSpace(*u.Role))
-	}
+	// Role is assigned only by admins, registered users always start with default role
+	u.Role = nil
 	return nil
 }
 
@@ -84,6 +134,58 @@ func (u *User) PrepareUpdate() error {
 	return nil
 }
 
+// Self-service user update, profile fields users can change on their own account
+type UserUpdate struct {
+	FirstName   string     `json:"first_name,omitempty" validate:"omitempty,lte=30"`
+	LastName    string     `json:"last_name,omitempty" validate:"omitempty,lte=30"`
+	About       *string    `json:"about,omitempty" validate:"omitempty,lte=1024"`
+	PhoneNumber *string    `json:"phone_number,omitempty" validate:"omitempty,lte=20"`
+	Address     *string    `json:"address,omitempty" validate:"omitempty,lte=250"`
+	City        *string    `json:"city,omitempty" validate:"omitempty,lte=24"`
+	Gender      *string    `json:"gender,omitempty" validate:"omitempty,lte=10"`
+	Postcode    *int       `json:"postcode,omitempty" validate:"omitempty"`
+	Birthday    *time.Time `json:"birthday,omitempty" validate:"omitempty"`
+}
+
+// Admin user update, profile fields and fields writable only with permission
+type AdminUserUpdate struct {
+	UserUpdate
+	Avatar *string `json:"avatar,omitempty" validate:"omitempty,lte=512,url"`
+	Role   *string `json:"role,omitempty" validate:"omitempty,lte=10"`
+}
+
+// User with profile fields of update
+func (u *UserUpdate) ToUser(userID uuid.UUID) *User {
+	return &User{
+		UserID:      userID,
+		FirstName:   u.FirstName,
+		LastName:    u.LastName,
+		About:       u.About,
+		PhoneNumber: u.PhoneNumber,
+		Address:     u.Address,
+		City:        u.City,
+		Gender:      u.Gender,
+		Postcode:    u.Postcode,
+		Birthday:    u.Birthday,
+	}
+}
+
+// User with all fields of admin update
+func (u *AdminUserUpdate) ToUser(userID uuid.UUID) *User {
+	user := u.UserUpdate.ToUser(userID)
+	user.Avatar = u.Avatar
+	user.Role = u.Role
+	return user
+}
+
+// User typeahead suggestion, only public identity
+type UserSuggestion struct {
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	FirstName string    `json:"first_name" db:"first_name"`
+	LastName  string    `json:"last_name" db:"last_name"`
+	Avatar    *string   `json:"avatar,omitempty" db:"avatar"`
+}
+
 // All Users response
 type UsersList struct {
 	TotalCount int     `json:"total_count"`
@@ -96,6 +198,9 @@ type UsersList struct {
 
 // Find user query
 type UserWithToken struct {
-	User  *User  `json:"user"`
-	Token string `json:"token"`
+	User              *User  `json:"user,omitempty"`
+	Token             string `json:"token,omitempty"`
+	RefreshToken      string `json:"refresh_token,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	Challenge         string `json:"challenge,omitempty"`
 }
@@ -9,5 +9,6 @@ type Handlers interface {
 	GetByID() echo.HandlerFunc
 	Delete() echo.HandlerFunc
 	GetNews() echo.HandlerFunc
-	SearchByTitle() echo.HandlerFunc
+	Search() echo.HandlerFunc
+	UpdateStatus() echo.HandlerFunc
 }
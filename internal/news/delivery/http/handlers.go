@@ -93,9 +93,50 @@ func (h newsHandlers) Update() echo.HandlerFunc {
 	}
 }
 
+// UpdateStatus godoc
+// @Summary Change news status
+// @Description Move news through editorial workflow: draft, in_review, scheduled, published, archived.
+// @Description Authors may submit own news for review, withdraw it and archive it, other transitions require news:publish permission
+// @Tags News
+// @Accept json
+// @Produce json
+// @Param id path int true "news_id"
+// @Param status body models.NewsStatusChange true "news status"
+// @Success 200 {object} models.News
+// @Failure 400 {object} httpErrors.RestError
+// @Failure 403 {object} httpErrors.RestError
+// @Failure 409 {object} httpErrors.RestError
+// @Router /news/{id}/status [put]
+func (h newsHandlers) UpdateStatus() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "newsHandlers.UpdateStatus")
+		defer span.Finish()
+
+		newsUUID, err := uuid.Parse(c.Param("news_id"))
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		change := &models.NewsStatusChange{}
+		if err = c.Bind(change); err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		updatedNews, err := h.newsUC.UpdateStatus(ctx, newsUUID, change)
+		if err != nil {
+			utils.LogResponseError(c, h.logger, err)
+			return c.JSON(httpErrors.ErrorResponse(err))
+		}
+
+		return c.JSON(http.StatusOK, updatedNews)
+	}
+}
+
 // GetByID godoc
 // @Summary Get by id news
-// @Description Get by id news handler
+// @Description Get by id news handler, unpublished news are found only by their authors and editors
 // @Tags News
 // @Accept json
 // @Produce json
@@ -154,10 +195,11 @@ func (h newsHandlers) Delete() echo.HandlerFunc {
 
 // GetNews godoc
 // @Summary Get all news
-// @Description Get all news with pagination
+// @Description Get all news with pagination, anonymous users get only published news
 // @Tags News
 // @Accept json
 // @Produce json
+// @Param status query string false "news status"
 // @Param page query int false "page number" Format(page)
 // @Param size query int false "number of elements per page" Format(size)
 // @Param orderBy query int false "filter name" Format(orderBy)
@@ -174,7 +216,7 @@ func (h newsHandlers) GetNews() echo.HandlerFunc {
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		newsList, err := h.newsUC.GetNews(ctx, pq)
+		newsList, err := h.newsUC.GetNews(ctx, c.QueryParam("status"), pq)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
@@ -184,20 +226,22 @@ func (h newsHandlers) GetNews() echo.HandlerFunc {
 	}
 }
 
-// SearchByTitle godoc
-// @Summary Search by title
-// @Description Search news by title
+// Search godoc
+// @Summary Search news
+// @Description Full-text search news by title and content, best matches first. Quoted "words" match a phrase, word* matches a prefix.
+// @Description Anonymous users find only published news
 // @Tags News
 // @Accept json
 // @Produce json
+// @Param q query string true "search query"
 // @Param page query int false "page number" Format(page)
 // @Param size query int false "number of elements per page" Format(size)
-// @Param orderBy query int false "filter name" Format(orderBy)
-// @Success 200 {object} models.NewsList
+// @Success 200 {object} models.NewsSearchList
+// @Failure 400 {object} httpErrors.RestError
 // @Router /news/search [get]
-func (h newsHandlers) SearchByTitle() echo.HandlerFunc {
+func (h newsHandlers) Search() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "newsHandlers.SearchByTitle")
+		span, ctx := opentracing.StartSpanFromContext(utils.GetRequestCtx(c), "newsHandlers.Search")
 		defer span.Finish()
 
 		pq, err := utils.GetPaginationFromCtx(c)
@@ -206,8 +250,12 @@ func (h newsHandlers) SearchByTitle() echo.HandlerFunc {
 			return c.JSON(httpErrors.ErrorResponse(err))
 		}
 
-		newsList, err := h.newsUC.SearchByTitle(ctx, c.QueryParam("title"), pq)
+		query := c.QueryParam("q")
+		if query == "" {
+			query = c.QueryParam("title")
+		}
 
+		newsList, err := h.newsUC.Search(ctx, query, pq)
 		if err != nil {
 			utils.LogResponseError(c, h.logger, err)
 			return c.JSON(httpErrors.ErrorResponse(err))
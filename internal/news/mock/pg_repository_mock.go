@@ -96,31 +96,61 @@ func (mr *MockRepositoryMockRecorder) Delete(ctx, newsID interface{}) *gomock.Ca
 }
 
 // GetNews mocks base method
-func (m *MockRepository) GetNews(ctx context.Context, pq *utils.PaginationQuery) (*models.NewsList, error) {
+func (m *MockRepository) GetNews(ctx context.Context, filter *models.NewsFilter, pq *utils.PaginationQuery) (*models.NewsList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetNews", ctx, pq)
+	ret := m.ctrl.Call(m, "GetNews", ctx, filter, pq)
 	ret0, _ := ret[0].(*models.NewsList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetNews indicates an expected call of GetNews
-func (mr *MockRepositoryMockRecorder) GetNews(ctx, pq interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetNews(ctx, filter, pq interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNews", reflect.TypeOf((*MockRepository)(nil).GetNews), ctx, pq)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNews", reflect.TypeOf((*MockRepository)(nil).GetNews), ctx, filter, pq)
 }
 
-// SearchByTitle mocks base method
-func (m *MockRepository) SearchByTitle(ctx context.Context, title string, query *utils.PaginationQuery) (*models.NewsList, error) {
+// Search mocks base method
+func (m *MockRepository) Search(ctx context.Context, language, tsQuery string, filter *models.NewsFilter, query *utils.PaginationQuery) (*models.NewsSearchList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchByTitle", ctx, title, query)
-	ret0, _ := ret[0].(*models.NewsList)
+	ret := m.ctrl.Call(m, "Search", ctx, language, tsQuery, filter, query)
+	ret0, _ := ret[0].(*models.NewsSearchList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search
+func (mr *MockRepositoryMockRecorder) Search(ctx, language, tsQuery, filter, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockRepository)(nil).Search), ctx, language, tsQuery, filter, query)
+}
+
+// UpdateStatus mocks base method
+func (m *MockRepository) UpdateStatus(ctx context.Context, newsID uuid.UUID, fromStatus string, change *models.NewsStatusChange) (*models.News, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, newsID, fromStatus, change)
+	ret0, _ := ret[0].(*models.News)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus
+func (mr *MockRepositoryMockRecorder) UpdateStatus(ctx, newsID, fromStatus, change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockRepository)(nil).UpdateStatus), ctx, newsID, fromStatus, change)
+}
+
+// PublishScheduled mocks base method
+func (m *MockRepository) PublishScheduled(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishScheduled", ctx, limit)
+	ret0, _ := ret[0].([]uuid.UUID)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SearchByTitle indicates an expected call of SearchByTitle
-func (mr *MockRepositoryMockRecorder) SearchByTitle(ctx, title, query interface{}) *gomock.Call {
+// PublishScheduled indicates an expected call of PublishScheduled
+func (mr *MockRepositoryMockRecorder) PublishScheduled(ctx, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchByTitle", reflect.TypeOf((*MockRepository)(nil).SearchByTitle), ctx, title, query)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishScheduled", reflect.TypeOf((*MockRepository)(nil).PublishScheduled), ctx, limit)
 }
@@ -96,31 +96,61 @@ func (mr *MockUseCaseMockRecorder) Delete(ctx, newsID interface{}) *gomock.Call
 }
 
 // GetNews mocks base method
-func (m *MockUseCase) GetNews(ctx context.Context, pq *utils.PaginationQuery) (*models.NewsList, error) {
+func (m *MockUseCase) GetNews(ctx context.Context, status string, pq *utils.PaginationQuery) (*models.NewsList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetNews", ctx, pq)
+	ret := m.ctrl.Call(m, "GetNews", ctx, status, pq)
 	ret0, _ := ret[0].(*models.NewsList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetNews indicates an expected call of GetNews
-func (mr *MockUseCaseMockRecorder) GetNews(ctx, pq interface{}) *gomock.Call {
+func (mr *MockUseCaseMockRecorder) GetNews(ctx, status, pq interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNews", reflect.TypeOf((*MockUseCase)(nil).GetNews), ctx, pq)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNews", reflect.TypeOf((*MockUseCase)(nil).GetNews), ctx, status, pq)
 }
 
-// SearchByTitle mocks base method
-func (m *MockUseCase) SearchByTitle(ctx context.Context, title string, query *utils.PaginationQuery) (*models.NewsList, error) {
+// Search mocks base method
+func (m *MockUseCase) Search(ctx context.Context, query string, pq *utils.PaginationQuery) (*models.NewsSearchList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchByTitle", ctx, title, query)
-	ret0, _ := ret[0].(*models.NewsList)
+	ret := m.ctrl.Call(m, "Search", ctx, query, pq)
+	ret0, _ := ret[0].(*models.NewsSearchList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search
+func (mr *MockUseCaseMockRecorder) Search(ctx, query, pq interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockUseCase)(nil).Search), ctx, query, pq)
+}
+
+// UpdateStatus mocks base method
+func (m *MockUseCase) UpdateStatus(ctx context.Context, newsID uuid.UUID, change *models.NewsStatusChange) (*models.News, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, newsID, change)
+	ret0, _ := ret[0].(*models.News)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus
+func (mr *MockUseCaseMockRecorder) UpdateStatus(ctx, newsID, change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockUseCase)(nil).UpdateStatus), ctx, newsID, change)
+}
+
+// PublishScheduled mocks base method
+func (m *MockUseCase) PublishScheduled(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishScheduled", ctx)
+	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SearchByTitle indicates an expected call of SearchByTitle
-func (mr *MockUseCaseMockRecorder) SearchByTitle(ctx, title, query interface{}) *gomock.Call {
+// PublishScheduled indicates an expected call of PublishScheduled
+func (mr *MockUseCaseMockRecorder) PublishScheduled(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchByTitle", reflect.TypeOf((*MockUseCase)(nil).SearchByTitle), ctx, title, query)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishScheduled", reflect.TypeOf((*MockUseCase)(nil).PublishScheduled), ctx)
 }
@@ -37,6 +37,7 @@ func (r *newsRepo) Create(ctx context.Context, news *models.News) (*models.News,
 		&news.Title,
 		&news.Content,
 		&news.Category,
+		&news.SearchLanguage,
 	).StructScan(&n); err != nil {
 		return nil, errors.Wrap(err, "newsRepo.Create.QueryRowxContext")
 	}
@@ -57,6 +58,7 @@ func (r *newsRepo) Update(ctx context.Context, news *models.News) (*models.News,
 		&news.Content,
 		&news.ImageURL,
 		&news.Category,
+		&news.SearchLanguage,
 		&news.NewsID,
 	).StructScan(&n); err != nil {
 		return nil, errors.Wrap(err, "newsRepo.Update.QueryRowxContext")
@@ -99,13 +101,46 @@ func (r *newsRepo) Delete(ctx context.Context, newsID uuid.UUID) error {
 	return nil
 }
 
+// Change news status if it is still in expected status, sql.ErrNoRows otherwise
+func (r *newsRepo) UpdateStatus(ctx context.Context, newsID uuid.UUID, fromStatus string, change *models.NewsStatusChange) (*models.News, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "newsRepo.UpdateStatus")
+	defer span.Finish()
+
+	var n models.News
+	if err := r.db.QueryRowxContext(
+		ctx,
+		updateNewsStatus,
+		newsID,
+		fromStatus,
+		change.Status,
+		change.ScheduledAt,
+	).StructScan(&n); err != nil {
+		return nil, errors.Wrap(err, "newsRepo.UpdateStatus.QueryRowxContext")
+	}
+
+	return &n, nil
+}
+
+// Publish batch of scheduled news whose time has come, returns ids of published news
+func (r *newsRepo) PublishScheduled(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "newsRepo.PublishScheduled")
+	defer span.Finish()
+
+	newsIDs := make([]uuid.UUID, 0, limit)
+	if err := r.db.SelectContext(ctx, &newsIDs, publishScheduledNews, limit); err != nil {
+		return nil, errors.Wrap(err, "newsRepo.PublishScheduled.SelectContext")
+	}
+
+	return newsIDs, nil
+}
+
 // Get news
-func (r *newsRepo) GetNews(ctx context.Context, pq *utils.PaginationQuery) (*models.NewsList, error) {
+func (r *newsRepo) GetNews(ctx context.Context, filter *models.NewsFilter, pq *utils.PaginationQuery) (*models.NewsList, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "newsRepo.GetNews")
 	defer span.Finish()
 
 	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, getTotalCount); err != nil {
+	if err := r.db.GetContext(ctx, &totalCount, getTotalCount, filter.Unpublished, filter.ViewerID, filter.Status); err != nil {
 		return nil, errors.Wrap(err, "newsRepo.GetNews.GetContext.totalCount")
 	}
 
@@ -121,7 +156,7 @@ func (r *newsRepo) GetNews(ctx context.Context, pq *utils.PaginationQuery) (*mod
 	}
 
 	var newsList = make([]*models.News, 0, pq.GetSize())
-	rows, err := r.db.QueryxContext(ctx, getNews, pq.GetOffset(), pq.GetLimit())
+	rows, err := r.db.QueryxContext(ctx, getNews, filter.Unpublished, filter.ViewerID, filter.Status, pq.GetOffset(), pq.GetLimit())
 	if err != nil {
 		return nil, errors.Wrap(err, "newsRepo.GetNews.QueryxContext")
 	}
@@ -149,46 +184,46 @@ func (r *newsRepo) GetNews(ctx context.Context, pq *utils.PaginationQuery) (*mod
 	}, nil
 }
 
-// Find news by title
-func (r *newsRepo) SearchByTitle(ctx context.Context, title string, query *utils.PaginationQuery) (*models.NewsList, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "newsRepo.SearchByTitle")
+// Full-text search news, best ranked first
+func (r *newsRepo) Search(ctx context.Context, language string, tsQuery string, filter *models.NewsFilter, query *utils.PaginationQuery) (*models.NewsSearchList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "newsRepo.Search")
 	defer span.Finish()
 
 	var totalCount int
-	if err := r.db.GetContext(ctx, &totalCount, findByTitleCount, title); err != nil {
-		return nil, errors.Wrap(err, "newsRepo.SearchByTitle.GetContext")
+	if err := r.db.GetContext(ctx, &totalCount, searchNewsCount, language, tsQuery, filter.Unpublished, filter.ViewerID, filter.Status); err != nil {
+		return nil, errors.Wrap(err, "newsRepo.Search.GetContext")
 	}
 	if totalCount == 0 {
-		return &models.NewsList{
+		return &models.NewsSearchList{
 			TotalCount: totalCount,
 			TotalPages: utils.GetTotalPages(totalCount, query.GetSize()),
 			Page:       query.GetPage(),
 			Size:       query.GetSize(),
 			HasMore:    utils.GetHasMore(query.GetPage(), totalCount, query.GetSize()),
-			News:       make([]*models.News, 0),
+			News:       make([]*models.NewsSearchResult, 0),
 		}, nil
 	}
 
-	var newsList = make([]*models.News, 0, query.GetSize())
-	rows, err := r.db.QueryxContext(ctx, findByTitle, title, query.GetOffset(), query.GetLimit())
+	var newsList = make([]*models.NewsSearchResult, 0, query.GetSize())
+	rows, err := r.db.QueryxContext(ctx, searchNews, language, tsQuery, filter.Unpublished, filter.ViewerID, filter.Status, query.GetOffset(), query.GetLimit())
 	if err != nil {
-		return nil, errors.Wrap(err, "newsRepo.SearchByTitle.QueryxContext")
+		return nil, errors.Wrap(err, "newsRepo.Search.QueryxContext")
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		n := &models.News{}
+		n := &models.NewsSearchResult{}
 		if err = rows.StructScan(n); err != nil {
-			return nil, errors.Wrap(err, "newsRepo.SearchByTitle.StructScan")
+			return nil, errors.Wrap(err, "newsRepo.Search.StructScan")
 		}
 		newsList = append(newsList, n)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, errors.Wrap(err, "newsRepo.SearchByTitle.rows.Err")
+		return nil, errors.Wrap(err, "newsRepo.Search.rows.Err")
 	}
 
-	return &models.NewsList{
+	return &models.NewsSearchList{
 		TotalCount: totalCount,
 		TotalPages: utils.GetTotalPages(totalCount, query.GetSize()),
 		Page:       query.GetPage(),
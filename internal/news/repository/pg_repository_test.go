@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
@@ -10,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/utils"
 )
 
 func TestNewsRepo_Create(t *testing.T) {
@@ -37,7 +39,7 @@ func TestNewsRepo_Create(t *testing.T) {
 			Content:  content,
 		}
 
-		mock.ExpectQuery(createNews).WithArgs(news.AuthorID, news.Title, news.Content, news.Category).WillReturnRows(rows)
+		mock.ExpectQuery(createNews).WithArgs(news.AuthorID, news.Title, news.Content, news.Category, news.SearchLanguage).WillReturnRows(rows)
 
 		createdNews, err := newsRepo.Create(context.Background(), news)
 
@@ -76,6 +78,7 @@ func TestNewsRepo_Update(t *testing.T) {
 			news.Content,
 			news.ImageURL,
 			news.Category,
+			news.SearchLanguage,
 			news.NewsID,
 		).WillReturnRows(rows)
 
@@ -87,6 +90,131 @@ func TestNewsRepo_Update(t *testing.T) {
 	})
 }
 
+func TestNewsRepo_Search(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	newsRepo := NewNewsRepository(sqlxDB)
+
+	t.Run("Search", func(t *testing.T) {
+		newsUID := uuid.New()
+		language := "english"
+		tsQuery := "(golang <-> release) & gener:*"
+		query := &utils.PaginationQuery{Size: 10, Page: 1}
+
+		countRows := sqlmock.NewRows([]string{"count"}).AddRow(1)
+		rows := sqlmock.NewRows([]string{"news_id", "title", "rank", "title_headline", "headline"}).
+			AddRow(newsUID, "Golang release", 0.6, "<b>Golang</b> <b>release</b>", "new <b>generics</b>")
+
+		mock.ExpectQuery(searchNewsCount).WithArgs(language, tsQuery, false, nil, "").WillReturnRows(countRows)
+		mock.ExpectQuery(searchNews).WithArgs(language, tsQuery, false, nil, "", query.GetOffset(), query.GetLimit()).WillReturnRows(rows)
+
+		newsList, err := newsRepo.Search(context.Background(), language, tsQuery, &models.NewsFilter{}, query)
+		require.NoError(t, err)
+		require.Equal(t, 1, newsList.TotalCount)
+		require.Len(t, newsList.News, 1)
+		require.Equal(t, newsUID, newsList.News[0].NewsID)
+		require.Equal(t, "new <b>generics</b>", newsList.News[0].Headline)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestNewsRepo_GetNews(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	newsRepo := NewNewsRepository(sqlxDB)
+
+	t.Run("GetNews", func(t *testing.T) {
+		viewerUID := uuid.New()
+		filter := &models.NewsFilter{Status: models.NewsStatusDraft, ViewerID: &viewerUID}
+		query := &utils.PaginationQuery{Size: 10, Page: 1}
+
+		countRows := sqlmock.NewRows([]string{"count"}).AddRow(1)
+		rows := sqlmock.NewRows([]string{"news_id", "author_id", "title", "status"}).
+			AddRow(uuid.New(), viewerUID, "title", models.NewsStatusDraft)
+
+		mock.ExpectQuery(getTotalCount).WithArgs(false, viewerUID, models.NewsStatusDraft).WillReturnRows(countRows)
+		mock.ExpectQuery(getNews).WithArgs(false, viewerUID, models.NewsStatusDraft, query.GetOffset(), query.GetLimit()).WillReturnRows(rows)
+
+		newsList, err := newsRepo.GetNews(context.Background(), filter, query)
+		require.NoError(t, err)
+		require.Len(t, newsList.News, 1)
+		require.Equal(t, models.NewsStatusDraft, newsList.News[0].Status)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestNewsRepo_UpdateStatus(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	newsRepo := NewNewsRepository(sqlxDB)
+
+	t.Run("UpdateStatus", func(t *testing.T) {
+		newsUID := uuid.New()
+		change := &models.NewsStatusChange{Status: models.NewsStatusPublished}
+		publishedAt := time.Now()
+
+		rows := sqlmock.NewRows([]string{"news_id", "status", "published_at"}).
+			AddRow(newsUID, models.NewsStatusPublished, publishedAt)
+
+		mock.ExpectQuery(updateNewsStatus).
+			WithArgs(newsUID, models.NewsStatusInReview, models.NewsStatusPublished, nil).
+			WillReturnRows(rows)
+
+		updatedNews, err := newsRepo.UpdateStatus(context.Background(), newsUID, models.NewsStatusInReview, change)
+		require.NoError(t, err)
+		require.Equal(t, models.NewsStatusPublished, updatedNews.Status)
+		require.NotNil(t, updatedNews.PublishedAt)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestNewsRepo_PublishScheduled(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	defer sqlxDB.Close()
+
+	newsRepo := NewNewsRepository(sqlxDB)
+
+	t.Run("PublishScheduled", func(t *testing.T) {
+		newsUID := uuid.New()
+
+		rows := sqlmock.NewRows([]string{"news_id"}).AddRow(newsUID)
+
+		mock.ExpectQuery(publishScheduledNews).WithArgs(100).WillReturnRows(rows)
+
+		newsIDs, err := newsRepo.PublishScheduled(context.Background(), 100)
+		require.NoError(t, err)
+		require.Equal(t, []uuid.UUID{newsUID}, newsIDs)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestNewsRepo_Delete(t *testing.T) {
 	t.Parallel()
 
@@ -1,18 +1,19 @@
 package repository
 
 const (
-	createNews = `INSERT INTO news (author_id, title, content, image_url, category, created_at) 
-					VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($4, ''), now()) 
-					RETURNING *`
+	createNews = `INSERT INTO news (author_id, title, content, image_url, category, search_language, created_at) 
+					VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($4, ''), $5::regconfig, now()) 
+					RETURNING news_id, author_id, title, content, image_url, category, status, scheduled_at, published_at, created_at, updated_at`
 
 	updateNews = `UPDATE news 
 					SET title = COALESCE(NULLIF($1, ''), title),
 						content = COALESCE(NULLIF($2, ''), content), 
 					    image_url = COALESCE(NULLIF($3, ''), image_url), 
 					    category = COALESCE(NULLIF($4, ''), category), 
+					    search_language = $5::regconfig,
 					    updated_at = now() 
-					WHERE news_id = $5
-					RETURNING *`
+					WHERE news_id = $6
+					RETURNING news_id, author_id, title, content, image_url, category, status, scheduled_at, published_at, created_at, updated_at`
 
 	getNewsByID = `SELECT n.news_id,
        n.title,
@@ -20,6 +21,9 @@ const (
        n.updated_at,
        n.image_url,
        n.category,
+       n.status,
+       n.scheduled_at,
+       n.published_at,
        CONCAT(u.first_name, ' ', u.last_name) as author,
        u.user_id as author_id
 FROM news n
@@ -28,19 +32,44 @@ WHERE news_id = $1`
 
 	deleteNews = `DELETE FROM news WHERE news_id = $1`
 
-	getTotalCount = `SELECT COUNT(news_id) FROM news`
+	updateNewsStatus = `UPDATE news 
+					SET status = $3,
+					    scheduled_at = $4,
+					    published_at = CASE WHEN $3 = 'published' THEN COALESCE(published_at, now()) ELSE published_at END,
+					    updated_at = now() 
+					WHERE news_id = $1 AND status = $2
+					RETURNING news_id, author_id, title, content, image_url, category, status, scheduled_at, published_at, created_at, updated_at`
+
+	publishScheduledNews = `UPDATE news 
+					SET status = 'published',
+					    published_at = COALESCE(published_at, scheduled_at),
+					    scheduled_at = NULL,
+					    updated_at = now() 
+					WHERE news_id IN (SELECT news_id FROM news 
+					                  WHERE status = 'scheduled' AND scheduled_at <= now() 
+					                  ORDER BY scheduled_at LIMIT $1 FOR UPDATE SKIP LOCKED)
+					RETURNING news_id`
 
-	getNews = `SELECT news_id, author_id, title, content, image_url, category, updated_at, created_at 
+	getTotalCount = `SELECT COUNT(news_id) FROM news 
+					WHERE (status = 'published' OR $1::boolean OR author_id = $2) AND ($3::varchar = '' OR status = $3)`
+
+	getNews = `SELECT news_id, author_id, title, content, image_url, category, status, scheduled_at, published_at, updated_at, created_at 
 				FROM news 
-				ORDER BY created_at, updated_at OFFSET $1 LIMIT $2`
+				WHERE (status = 'published' OR $1::boolean OR author_id = $2) AND ($3::varchar = '' OR status = $3)
+				ORDER BY created_at, updated_at OFFSET $4 LIMIT $5`
 
-	findByTitleCount = `SELECT COUNT(*)
+	searchNewsCount = `SELECT COUNT(*)
 					FROM news
-					WHERE title ILIKE '%' || $1 || '%'`
+					WHERE search_vector @@ to_tsquery($1::regconfig, $2)
+					  AND (status = 'published' OR $3::boolean OR author_id = $4) AND ($5::varchar = '' OR status = $5)`
 
-	findByTitle = `SELECT news_id, author_id, title, content, image_url, category, updated_at, created_at
-					FROM news
-					WHERE title ILIKE '%' || $1 || '%'
-					ORDER BY title, created_at, updated_at
-					OFFSET $2 LIMIT $3`
+	searchNews = `SELECT news_id, author_id, title, content, image_url, category, status, scheduled_at, published_at, updated_at, created_at,
+       ts_rank(search_vector, query) AS rank,
+       ts_headline($1::regconfig, title, query, 'HighlightAll=true') AS title_headline,
+       ts_headline($1::regconfig, content, query, 'MaxWords=35, MinWords=15, MaxFragments=2') AS headline
+FROM news, to_tsquery($1::regconfig, $2) query
+WHERE search_vector @@ query
+  AND (status = 'published' OR $3::boolean OR author_id = $4) AND ($5::varchar = '' OR status = $5)
+ORDER BY rank DESC, created_at DESC
+OFFSET $6 LIMIT $7`
 )
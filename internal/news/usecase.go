@@ -16,6 +16,8 @@ type UseCase interface {
 	Update(ctx context.Context, news *models.News) (*models.News, error)
 	GetNewsByID(ctx context.Context, newsID uuid.UUID) (*models.NewsBase, error)
 	Delete(ctx context.Context, newsID uuid.UUID) error
-	GetNews(ctx context.Context, pq *utils.PaginationQuery) (*models.NewsList, error)
-	SearchByTitle(ctx context.Context, title string, query *utils.PaginationQuery) (*models.NewsList, error)
+	GetNews(ctx context.Context, status string, pq *utils.PaginationQuery) (*models.NewsList, error)
+	Search(ctx context.Context, query string, pq *utils.PaginationQuery) (*models.NewsSearchList, error)
+	UpdateStatus(ctx context.Context, newsID uuid.UUID, change *models.NewsStatusChange) (*models.News, error)
+	PublishScheduled(ctx context.Context) (int, error)
 }
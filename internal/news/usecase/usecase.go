@@ -2,14 +2,19 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 
 	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/internal/audit"
 	"github.com/AleksK1NG/api-mc/internal/models"
 	"github.com/AleksK1NG/api-mc/internal/news"
 	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
@@ -18,8 +23,45 @@ import (
 )
 
 const (
-	basePrefix    = "api-news:"
-	cacheDuration = 3600
+	basePrefix              = "api-news:"
+	cacheDuration           = 3600
+	defaultSearchLanguage   = "english"
+	defaultPublishBatchSize = 100
+)
+
+// Allowed news status transitions. Users with publish permission may make any of them,
+// authors only those allowed to owner: submit for review, withdraw from review and archive.
+var newsStatusTransitions = map[string]map[string]struct{ owner bool }{
+	models.NewsStatusDraft: {
+		models.NewsStatusInReview:  {owner: true},
+		models.NewsStatusScheduled: {},
+		models.NewsStatusPublished: {},
+	},
+	models.NewsStatusInReview: {
+		models.NewsStatusDraft:     {owner: true},
+		models.NewsStatusScheduled: {},
+		models.NewsStatusPublished: {},
+	},
+	models.NewsStatusScheduled: {
+		models.NewsStatusDraft:     {},
+		models.NewsStatusInReview:  {},
+		models.NewsStatusPublished: {},
+	},
+	models.NewsStatusPublished: {
+		models.NewsStatusDraft:    {},
+		models.NewsStatusArchived: {owner: true},
+	},
+	models.NewsStatusArchived: {
+		models.NewsStatusDraft:     {},
+		models.NewsStatusPublished: {},
+	},
+}
+
+var (
+	// Quoted phrase or single word of search query
+	searchTermRegexp = regexp.MustCompile(`"[^"]*"|\S+`)
+	// Letters and digits only, so to_tsquery operators can not be injected
+	searchLexemeRegexp = regexp.MustCompile(`[\p{L}\p{N}]+`)
 )
 
 // News UseCase
@@ -27,12 +69,13 @@ type newsUC struct {
 	cfg       *config.Config
 	newsRepo  news.Repository
 	redisRepo news.RedisRepository
+	auditUC   audit.UseCase
 	logger    logger.Logger
 }
 
 // News UseCase constructor
-func NewNewsUseCase(cfg *config.Config, newsRepo news.Repository, redisRepo news.RedisRepository, logger logger.Logger) news.UseCase {
-	return &newsUC{cfg: cfg, newsRepo: newsRepo, redisRepo: redisRepo, logger: logger}
+func NewNewsUseCase(cfg *config.Config, newsRepo news.Repository, redisRepo news.RedisRepository, auditUC audit.UseCase, logger logger.Logger) news.UseCase {
+	return &newsUC{cfg: cfg, newsRepo: newsRepo, redisRepo: redisRepo, auditUC: auditUC, logger: logger}
 }
 
 // Create news
@@ -46,6 +89,7 @@ func (u *newsUC) Create(ctx context.Context, news *models.News) (*models.News, e
 	}
 
 	news.AuthorID = user.UserID
+	news.SearchLanguage = u.searchLanguage()
 
 	if err = utils.ValidateStruct(ctx, news); err != nil {
 		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "newsUC.Create.ValidateStruct"))
@@ -69,9 +113,10 @@ func (u *newsUC) Update(ctx context.Context, news *models.News) (*models.News, e
 		return nil, err
 	}
 
-	if err = utils.ValidateIsOwner(ctx, newsByID.AuthorID.String(), u.logger); err != nil {
+	if err = utils.ValidateIsOwnerOrPermission(ctx, newsByID.AuthorID.String(), models.PermissionNewsModerate, u.logger); err != nil {
 		return nil, httpErrors.NewRestError(http.StatusForbidden, "Forbidden", errors.Wrap(err, "newsUC.Update.ValidateIsOwner"))
 	}
+	news.SearchLanguage = u.searchLanguage()
 
 	updatedUser, err := u.newsRepo.Update(ctx, news)
 	if err != nil {
@@ -82,6 +127,14 @@ func (u *newsUC) Update(ctx context.Context, news *models.News) (*models.News, e
 		u.logger.Errorf("newsUC.Update.DeleteNewsCtx: %v", err)
 	}
 
+	u.recordAuditEvent(ctx, &models.AuditEvent{
+		Action:     models.AuditActionNewsUpdate,
+		TargetType: models.AuditTargetNews,
+		TargetID:   news.NewsID.String(),
+		Before:     newsByID,
+		After:      updatedUser,
+	})
+
 	return updatedUser, nil
 }
 
@@ -94,20 +147,25 @@ func (u *newsUC) GetNewsByID(ctx context.Context, newsID uuid.UUID) (*models.New
 	if err != nil {
 		u.logger.Errorf("newsUC.GetNewsByID.GetNewsByIDCtx: %v", err)
 	}
-	if newsBase != nil {
-		return newsBase, nil
+	if newsBase == nil {
+		newsBase, err = u.newsRepo.GetNewsByID(ctx, newsID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = u.redisRepo.SetNewsCtx(ctx, u.getKeyWithPrefix(newsID.String()), cacheDuration, newsBase); err != nil {
+			u.logger.Errorf("newsUC.GetNewsByID.SetNewsCtx: %s", err)
+		}
 	}
 
-	n, err := u.newsRepo.GetNewsByID(ctx, newsID)
-	if err != nil {
-		return nil, err
+	// Unpublished news are hidden as not existing
+	filter := newsFilter(ctx, "")
+	if newsBase.Status != models.NewsStatusPublished && !filter.Unpublished &&
+		(filter.ViewerID == nil || *filter.ViewerID != newsBase.AuthorID) {
+		return nil, httpErrors.NewNotFoundError(errors.New("newsUC.GetNewsByID: news is not published"))
 	}
 
-	if err = u.redisRepo.SetNewsCtx(ctx, u.getKeyWithPrefix(newsID.String()), cacheDuration, n); err != nil {
-		u.logger.Errorf("newsUC.GetNewsByID.SetNewsCtx: %s", err)
-	}
-
-	return n, nil
+	return newsBase, nil
 }
 
 // Delete news
@@ -120,7 +178,7 @@ func (u *newsUC) Delete(ctx context.Context, newsID uuid.UUID) error {
 		return err
 	}
 
-	if err = utils.ValidateIsOwner(ctx, newsByID.AuthorID.String(), u.logger); err != nil {
+	if err = utils.ValidateIsOwnerOrPermission(ctx, newsByID.AuthorID.String(), models.PermissionNewsModerate, u.logger); err != nil {
 		return httpErrors.NewRestError(http.StatusForbidden, "Forbidden", errors.Wrap(err, "newsUC.Delete.ValidateIsOwner"))
 	}
 
@@ -132,25 +190,188 @@ func (u *newsUC) Delete(ctx context.Context, newsID uuid.UUID) error {
 		u.logger.Errorf("newsUC.Delete.DeleteNewsCtx: %v", err)
 	}
 
+	u.recordAuditEvent(ctx, &models.AuditEvent{
+		Action:     models.AuditActionNewsDelete,
+		TargetType: models.AuditTargetNews,
+		TargetID:   newsID.String(),
+		Before:     newsByID,
+	})
+
 	return nil
 }
 
 // Get news
-func (u *newsUC) GetNews(ctx context.Context, pq *utils.PaginationQuery) (*models.NewsList, error) {
+func (u *newsUC) GetNews(ctx context.Context, status string, pq *utils.PaginationQuery) (*models.NewsList, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "newsUC.GetNews")
 	defer span.Finish()
 
-	return u.newsRepo.GetNews(ctx, pq)
+	if status != "" {
+		if _, ok := newsStatusTransitions[status]; !ok {
+			return nil, httpErrors.NewRestError(http.StatusBadRequest, httpErrors.BadQueryParams.Error(), errors.Errorf("newsUC.GetNews: unknown status %s", status))
+		}
+	}
+
+	return u.newsRepo.GetNews(ctx, newsFilter(ctx, status), pq)
 }
 
-// Find nes by title
-func (u *newsUC) SearchByTitle(ctx context.Context, title string, query *utils.PaginationQuery) (*models.NewsList, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "newsUC.SearchByTitle")
+// Full-text search news by title and content
+func (u *newsUC) Search(ctx context.Context, query string, pq *utils.PaginationQuery) (*models.NewsSearchList, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "newsUC.Search")
 	defer span.Finish()
 
-	return u.newsRepo.SearchByTitle(ctx, title, query)
+	tsQuery := buildTSQuery(query)
+	if tsQuery == "" {
+		return nil, httpErrors.NewRestError(http.StatusBadRequest, httpErrors.BadQueryParams.Error(), errors.New("newsUC.Search: empty search query"))
+	}
+
+	return u.newsRepo.Search(ctx, u.searchLanguage(), tsQuery, newsFilter(ctx, ""), pq)
+}
+
+// Change news status following editorial workflow
+func (u *newsUC) UpdateStatus(ctx context.Context, newsID uuid.UUID, change *models.NewsStatusChange) (*models.News, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "newsUC.UpdateStatus")
+	defer span.Finish()
+
+	if err := utils.ValidateStruct(ctx, change); err != nil {
+		return nil, httpErrors.NewBadRequestError(errors.WithMessage(err, "newsUC.UpdateStatus.ValidateStruct"))
+	}
+
+	newsByID, err := u.newsRepo.GetNewsByID(ctx, newsID)
+	if err != nil {
+		return nil, err
+	}
+
+	transition, ok := newsStatusTransitions[newsByID.Status][change.Status]
+	if !ok {
+		return nil, httpErrors.NewBadRequestError(errors.Wrapf(httpErrors.InvalidNewsTransition, "newsUC.UpdateStatus: %s to %s", newsByID.Status, change.Status))
+	}
+
+	if !utils.HasPermission(ctx, models.PermissionNewsPublish) {
+		if !transition.owner {
+			return nil, httpErrors.NewRestError(http.StatusForbidden, "Forbidden", errors.Errorf("newsUC.UpdateStatus: %s to %s requires %s", newsByID.Status, change.Status, models.PermissionNewsPublish))
+		}
+		if err = utils.ValidateIsOwner(ctx, newsByID.AuthorID.String(), u.logger); err != nil {
+			return nil, httpErrors.NewRestError(http.StatusForbidden, "Forbidden", errors.Wrap(err, "newsUC.UpdateStatus.ValidateIsOwner"))
+		}
+	}
+
+	if change.Status != models.NewsStatusScheduled {
+		change.ScheduledAt = nil
+	} else if change.ScheduledAt == nil || !change.ScheduledAt.After(time.Now()) {
+		return nil, httpErrors.NewBadRequestError(httpErrors.InvalidScheduledAt)
+	}
+
+	updatedNews, err := u.newsRepo.UpdateStatus(ctx, newsID, newsByID.Status, change)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Status was changed concurrently
+			return nil, httpErrors.NewRestError(http.StatusConflict, httpErrors.InvalidNewsTransition.Error(), err)
+		}
+		return nil, err
+	}
+
+	if err = u.redisRepo.DeleteNewsCtx(ctx, u.getKeyWithPrefix(newsID.String())); err != nil {
+		u.logger.Errorf("newsUC.UpdateStatus.DeleteNewsCtx: %v", err)
+	}
+
+	u.recordAuditEvent(ctx, &models.AuditEvent{
+		Action:     models.AuditActionNewsStatusChange,
+		TargetType: models.AuditTargetNews,
+		TargetID:   newsID.String(),
+		Metadata:   map[string]interface{}{"from": newsByID.Status, "to": change.Status, "scheduled_at": change.ScheduledAt},
+	})
+
+	return updatedNews, nil
+}
+
+// Publish scheduled news whose time has come, returns number of published news
+func (u *newsUC) PublishScheduled(ctx context.Context) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "newsUC.PublishScheduled")
+	defer span.Finish()
+
+	batchSize := u.cfg.News.PublishBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultPublishBatchSize
+	}
+
+	var published int
+	for {
+		newsIDs, err := u.newsRepo.PublishScheduled(ctx, batchSize)
+		if err != nil {
+			return published, err
+		}
+		published += len(newsIDs)
+
+		for _, newsID := range newsIDs {
+			if err = u.redisRepo.DeleteNewsCtx(ctx, u.getKeyWithPrefix(newsID.String())); err != nil {
+				u.logger.Errorf("newsUC.PublishScheduled.DeleteNewsCtx: %v", err)
+			}
+
+			u.recordAuditEvent(ctx, &models.AuditEvent{
+				Action:     models.AuditActionNewsStatusChange,
+				TargetType: models.AuditTargetNews,
+				TargetID:   newsID.String(),
+				Metadata:   map[string]interface{}{"from": models.NewsStatusScheduled, "to": models.NewsStatusPublished},
+			})
+		}
+
+		if len(newsIDs) < batchSize || ctx.Err() != nil {
+			return published, nil
+		}
+	}
+}
+
+// Configured text search language, news are indexed and searched with the same one
+func (u *newsUC) searchLanguage() string {
+	if u.cfg == nil || u.cfg.News.SearchLanguage == "" {
+		return defaultSearchLanguage
+	}
+	return u.cfg.News.SearchLanguage
+}
+
+// News visible to context user: published and own news, all news with publish or moderate permission
+func newsFilter(ctx context.Context, status string) *models.NewsFilter {
+	filter := &models.NewsFilter{
+		Status:      status,
+		Unpublished: utils.HasPermission(ctx, models.PermissionNewsPublish) || utils.HasPermission(ctx, models.PermissionNewsModerate),
+	}
+	if user, err := utils.GetUserFromCtx(ctx); err == nil {
+		filter.ViewerID = &user.UserID
+	}
+	return filter
+}
+
+// Build to_tsquery expression from user query, all terms must match.
+// "quoted words" match as phrase, trailing * matches word prefix, other characters are ignored
+func buildTSQuery(query string) string {
+	terms := make([]string, 0)
+	for _, term := range searchTermRegexp.FindAllString(query, -1) {
+		phrase := strings.HasPrefix(term, `"`)
+		prefix := !phrase && strings.HasSuffix(term, "*")
+
+		lexemes := searchLexemeRegexp.FindAllString(term, -1)
+		if len(lexemes) == 0 {
+			continue
+		}
+		if prefix {
+			lexemes[len(lexemes)-1] += ":*"
+		}
+		if len(lexemes) == 1 {
+			terms = append(terms, lexemes[0])
+			continue
+		}
+		terms = append(terms, "("+strings.Join(lexemes, " <-> ")+")")
+	}
+	return strings.Join(terms, " & ")
 }
 
 func (u *newsUC) getKeyWithPrefix(newsID string) string {
 	return fmt.Sprintf("%s: %s", basePrefix, newsID)
 }
+
+// Record audit event, failures are logged and do not fail the action
+func (u *newsUC) recordAuditEvent(ctx context.Context, event *models.AuditEvent) {
+	if err := u.auditUC.Record(ctx, event); err != nil {
+		u.logger.Errorf("newsUC.recordAuditEvent Action: %s, TargetID: %s, Error: %v", event.Action, event.TargetID, err)
+	}
+}
@@ -2,16 +2,23 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
+	"github.com/AleksK1NG/api-mc/config"
+	auditMock "github.com/AleksK1NG/api-mc/internal/audit/mock"
 	"github.com/AleksK1NG/api-mc/internal/models"
 	"github.com/AleksK1NG/api-mc/internal/news/mock"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 	"github.com/AleksK1NG/api-mc/pkg/utils"
 )
@@ -24,7 +31,7 @@ func TestNewsUC_Create(t *testing.T) {
 
 	apiLogger := logger.NewApiLogger(nil)
 	mockNewsRepo := mock.NewMockRepository(ctrl)
-	newsUC := NewNewsUseCase(nil, mockNewsRepo, nil, apiLogger)
+	newsUC := NewNewsUseCase(nil, mockNewsRepo, nil, nil, apiLogger)
 
 	userUID := uuid.New()
 
@@ -59,7 +66,9 @@ func TestNewsUC_Update(t *testing.T) {
 	apiLogger := logger.NewApiLogger(nil)
 	mockNewsRepo := mock.NewMockRepository(ctrl)
 	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
-	newsUC := NewNewsUseCase(nil, mockNewsRepo, mockRedisRepo, apiLogger)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	newsUC := NewNewsUseCase(nil, mockNewsRepo, mockRedisRepo, mockAuditUC, apiLogger)
 
 	userUID := uuid.New()
 	newsUID := uuid.New()
@@ -106,11 +115,12 @@ func TestNewsUC_GetNewsByID(t *testing.T) {
 	apiLogger := logger.NewApiLogger(nil)
 	mockNewsRepo := mock.NewMockRepository(ctrl)
 	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
-	newsUC := NewNewsUseCase(nil, mockNewsRepo, mockRedisRepo, apiLogger)
+	newsUC := NewNewsUseCase(nil, mockNewsRepo, mockRedisRepo, nil, apiLogger)
 
 	newsUID := uuid.New()
 	newsBase := &models.NewsBase{
 		NewsID: newsUID,
+		Status: models.NewsStatusPublished,
 	}
 	ctx := context.Background()
 	span, ctxWithTrace := opentracing.StartSpanFromContext(ctx, "newsUC.GetNewsByID")
@@ -127,6 +137,58 @@ func TestNewsUC_GetNewsByID(t *testing.T) {
 	require.NotNil(t, newsByID)
 }
 
+func TestNewsUC_GetNewsByIDUnpublished(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiLogger := logger.NewApiLogger(nil)
+	mockNewsRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	newsUC := NewNewsUseCase(nil, mockNewsRepo, mockRedisRepo, nil, apiLogger)
+
+	authorUID := uuid.New()
+	newsBase := &models.NewsBase{
+		NewsID:   uuid.New(),
+		AuthorID: authorUID,
+		Status:   models.NewsStatusDraft,
+	}
+	mockRedisRepo.EXPECT().GetNewsByIDCtx(gomock.Any(), gomock.Any()).Return(newsBase, nil).AnyTimes()
+
+	t.Run("Anonymous", func(t *testing.T) {
+		newsByID, err := newsUC.GetNewsByID(context.Background(), newsBase.NewsID)
+		require.Error(t, err)
+		require.Nil(t, newsByID)
+		require.Equal(t, http.StatusNotFound, httpErrors.ParseErrors(err).Status())
+	})
+
+	t.Run("Other user", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), utils.UserCtxKey{}, &models.User{UserID: uuid.New()})
+
+		newsByID, err := newsUC.GetNewsByID(ctx, newsBase.NewsID)
+		require.Error(t, err)
+		require.Nil(t, newsByID)
+	})
+
+	t.Run("Author", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), utils.UserCtxKey{}, &models.User{UserID: authorUID})
+
+		newsByID, err := newsUC.GetNewsByID(ctx, newsBase.NewsID)
+		require.NoError(t, err)
+		require.Equal(t, newsBase, newsByID)
+	})
+
+	t.Run("Editor", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), utils.UserCtxKey{}, &models.User{UserID: uuid.New()})
+		ctx = context.WithValue(ctx, utils.PermissionsCtxKey{}, &models.UserPermissions{Permissions: []string{models.PermissionNewsPublish}})
+
+		newsByID, err := newsUC.GetNewsByID(ctx, newsBase.NewsID)
+		require.NoError(t, err)
+		require.Equal(t, newsBase, newsByID)
+	})
+}
+
 func TestNewsUC_Delete(t *testing.T) {
 	t.Parallel()
 
@@ -136,7 +198,8 @@ func TestNewsUC_Delete(t *testing.T) {
 	apiLogger := logger.NewApiLogger(nil)
 	mockNewsRepo := mock.NewMockRepository(ctrl)
 	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
-	newsUC := NewNewsUseCase(nil, mockNewsRepo, mockRedisRepo, apiLogger)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	newsUC := NewNewsUseCase(nil, mockNewsRepo, mockRedisRepo, mockAuditUC, apiLogger)
 
 	newsUID := uuid.New()
 	userUID := uuid.New()
@@ -157,6 +220,13 @@ func TestNewsUC_Delete(t *testing.T) {
 	mockNewsRepo.EXPECT().GetNewsByID(ctxWithTrace, gomock.Eq(newsBase.NewsID)).Return(newsBase, nil)
 	mockNewsRepo.EXPECT().Delete(ctxWithTrace, gomock.Eq(newsUID)).Return(nil)
 	mockRedisRepo.EXPECT().DeleteNewsCtx(ctxWithTrace, gomock.Eq(cacheKey)).Return(nil)
+	mockAuditUC.EXPECT().Record(ctxWithTrace, gomock.Any()).DoAndReturn(func(ctx context.Context, event *models.AuditEvent) error {
+		require.Equal(t, models.AuditActionNewsDelete, event.Action)
+		require.Equal(t, models.AuditTargetNews, event.TargetType)
+		require.Equal(t, newsUID.String(), event.TargetID)
+		require.Equal(t, newsBase, event.Before)
+		return nil
+	})
 
 	err := newsUC.Delete(ctx, newsBase.NewsID)
 	require.NoError(t, err)
@@ -172,7 +242,7 @@ func TestNewsUC_GetNews(t *testing.T) {
 	apiLogger := logger.NewApiLogger(nil)
 	mockNewsRepo := mock.NewMockRepository(ctrl)
 	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
-	newsUC := NewNewsUseCase(nil, mockNewsRepo, mockRedisRepo, apiLogger)
+	newsUC := NewNewsUseCase(nil, mockNewsRepo, mockRedisRepo, nil, apiLogger)
 
 	ctx := context.Background()
 	span, ctxWithTrace := opentracing.StartSpanFromContext(ctx, "newsUC.GetNews")
@@ -186,27 +256,32 @@ func TestNewsUC_GetNews(t *testing.T) {
 
 	newsList := &models.NewsList{}
 
-	mockNewsRepo.EXPECT().GetNews(ctxWithTrace, query).Return(newsList, nil)
+	mockNewsRepo.EXPECT().GetNews(ctxWithTrace, &models.NewsFilter{}, query).Return(newsList, nil)
 
-	news, err := newsUC.GetNews(ctx, query)
+	news, err := newsUC.GetNews(ctx, "", query)
 	require.NoError(t, err)
 	require.Nil(t, err)
 	require.NotNil(t, news)
+
+	news, err = newsUC.GetNews(ctx, "unknown", query)
+	require.Error(t, err)
+	require.Nil(t, news)
 }
 
-func TestNewsUC_SearchByTitle(t *testing.T) {
+func TestNewsUC_Search(t *testing.T) {
 	t.Parallel()
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	cfg := &config.Config{News: config.News{SearchLanguage: "simple"}}
 	apiLogger := logger.NewApiLogger(nil)
 	mockNewsRepo := mock.NewMockRepository(ctrl)
 	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
-	newsUC := NewNewsUseCase(nil, mockNewsRepo, mockRedisRepo, apiLogger)
+	newsUC := NewNewsUseCase(cfg, mockNewsRepo, mockRedisRepo, nil, apiLogger)
 
 	ctx := context.Background()
-	span, ctxWithTrace := opentracing.StartSpanFromContext(ctx, "newsUC.SearchByTitle")
+	span, ctxWithTrace := opentracing.StartSpanFromContext(ctx, "newsUC.Search")
 	defer span.Finish()
 	query := &utils.PaginationQuery{
 		Size:    10,
@@ -214,13 +289,193 @@ func TestNewsUC_SearchByTitle(t *testing.T) {
 		OrderBy: "",
 	}
 
-	newsList := &models.NewsList{}
-	title := "title"
+	t.Run("Search", func(t *testing.T) {
+		newsList := &models.NewsSearchList{}
+
+		mockNewsRepo.EXPECT().Search(ctxWithTrace, "simple", "(Golang <-> release) & gener:*", &models.NewsFilter{}, query).Return(newsList, nil)
 
-	mockNewsRepo.EXPECT().SearchByTitle(ctxWithTrace, title, query).Return(newsList, nil)
+		news, err := newsUC.Search(ctx, `"Golang release" gener*`, query)
+		require.NoError(t, err)
+		require.NotNil(t, news)
+	})
+
+	t.Run("Empty query", func(t *testing.T) {
+		news, err := newsUC.Search(ctx, ` "" * !`, query)
+		require.Error(t, err)
+		require.Nil(t, news)
+	})
+}
 
-	news, err := newsUC.SearchByTitle(ctx, title, query)
+func TestNewsUC_UpdateStatus(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		Logger: config.Logger{
+			Development: true,
+			Encoding:    "json",
+		},
+	}
+
+	apiLogger := logger.NewApiLogger(cfg)
+	apiLogger.InitLogger()
+	mockNewsRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	newsUC := NewNewsUseCase(cfg, mockNewsRepo, mockRedisRepo, mockAuditUC, apiLogger)
+
+	authorUID := uuid.New()
+	newsUID := uuid.New()
+	cacheKey := fmt.Sprintf("%s: %s", basePrefix, newsUID)
+
+	authorCtx := context.WithValue(context.Background(), utils.UserCtxKey{}, &models.User{UserID: authorUID})
+	editorCtx := context.WithValue(context.Background(), utils.UserCtxKey{}, &models.User{UserID: uuid.New()})
+	editorCtx = context.WithValue(editorCtx, utils.PermissionsCtxKey{}, &models.UserPermissions{Permissions: []string{models.PermissionNewsPublish}})
+
+	t.Run("Author submits for review", func(t *testing.T) {
+		newsBase := &models.NewsBase{NewsID: newsUID, AuthorID: authorUID, Status: models.NewsStatusDraft}
+		change := &models.NewsStatusChange{Status: models.NewsStatusInReview}
+		updatedNews := &models.News{NewsID: newsUID, Status: models.NewsStatusInReview}
+
+		mockNewsRepo.EXPECT().GetNewsByID(gomock.Any(), newsUID).Return(newsBase, nil)
+		mockNewsRepo.EXPECT().UpdateStatus(gomock.Any(), newsUID, models.NewsStatusDraft, change).Return(updatedNews, nil)
+		mockRedisRepo.EXPECT().DeleteNewsCtx(gomock.Any(), cacheKey).Return(nil)
+		mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event *models.AuditEvent) error {
+			require.Equal(t, models.AuditActionNewsStatusChange, event.Action)
+			require.Equal(t, models.NewsStatusDraft, event.Metadata["from"])
+			require.Equal(t, models.NewsStatusInReview, event.Metadata["to"])
+			return nil
+		})
+
+		n, err := newsUC.UpdateStatus(authorCtx, newsUID, change)
+		require.NoError(t, err)
+		require.Equal(t, updatedNews, n)
+	})
+
+	t.Run("Author can not publish", func(t *testing.T) {
+		newsBase := &models.NewsBase{NewsID: newsUID, AuthorID: authorUID, Status: models.NewsStatusInReview}
+
+		mockNewsRepo.EXPECT().GetNewsByID(gomock.Any(), newsUID).Return(newsBase, nil)
+
+		n, err := newsUC.UpdateStatus(authorCtx, newsUID, &models.NewsStatusChange{Status: models.NewsStatusPublished})
+		require.Error(t, err)
+		require.Nil(t, n)
+		require.Equal(t, http.StatusForbidden, httpErrors.ParseErrors(err).Status())
+	})
+
+	t.Run("Other user can not submit", func(t *testing.T) {
+		newsBase := &models.NewsBase{NewsID: newsUID, AuthorID: uuid.New(), Status: models.NewsStatusDraft}
+
+		mockNewsRepo.EXPECT().GetNewsByID(gomock.Any(), newsUID).Return(newsBase, nil)
+
+		n, err := newsUC.UpdateStatus(authorCtx, newsUID, &models.NewsStatusChange{Status: models.NewsStatusInReview})
+		require.Error(t, err)
+		require.Nil(t, n)
+		require.Equal(t, http.StatusForbidden, httpErrors.ParseErrors(err).Status())
+	})
+
+	t.Run("Invalid transition", func(t *testing.T) {
+		newsBase := &models.NewsBase{NewsID: newsUID, AuthorID: authorUID, Status: models.NewsStatusDraft}
+
+		mockNewsRepo.EXPECT().GetNewsByID(gomock.Any(), newsUID).Return(newsBase, nil)
+
+		n, err := newsUC.UpdateStatus(editorCtx, newsUID, &models.NewsStatusChange{Status: models.NewsStatusArchived})
+		require.Error(t, err)
+		require.Nil(t, n)
+		require.Equal(t, http.StatusBadRequest, httpErrors.ParseErrors(err).Status())
+	})
+
+	t.Run("Schedule in the past", func(t *testing.T) {
+		newsBase := &models.NewsBase{NewsID: newsUID, AuthorID: authorUID, Status: models.NewsStatusInReview}
+		scheduledAt := time.Now().Add(-time.Hour)
+
+		mockNewsRepo.EXPECT().GetNewsByID(gomock.Any(), newsUID).Return(newsBase, nil)
+
+		n, err := newsUC.UpdateStatus(editorCtx, newsUID, &models.NewsStatusChange{Status: models.NewsStatusScheduled, ScheduledAt: &scheduledAt})
+		require.Error(t, err)
+		require.Nil(t, n)
+		require.Equal(t, http.StatusBadRequest, httpErrors.ParseErrors(err).Status())
+	})
+
+	t.Run("Editor schedules", func(t *testing.T) {
+		newsBase := &models.NewsBase{NewsID: newsUID, AuthorID: authorUID, Status: models.NewsStatusInReview}
+		scheduledAt := time.Now().Add(time.Hour)
+		change := &models.NewsStatusChange{Status: models.NewsStatusScheduled, ScheduledAt: &scheduledAt}
+		updatedNews := &models.News{NewsID: newsUID, Status: models.NewsStatusScheduled, ScheduledAt: &scheduledAt}
+
+		mockNewsRepo.EXPECT().GetNewsByID(gomock.Any(), newsUID).Return(newsBase, nil)
+		mockNewsRepo.EXPECT().UpdateStatus(gomock.Any(), newsUID, models.NewsStatusInReview, change).Return(updatedNews, nil)
+		mockRedisRepo.EXPECT().DeleteNewsCtx(gomock.Any(), cacheKey).Return(nil)
+		mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+
+		n, err := newsUC.UpdateStatus(editorCtx, newsUID, change)
+		require.NoError(t, err)
+		require.Equal(t, updatedNews, n)
+	})
+
+	t.Run("Concurrent change", func(t *testing.T) {
+		newsBase := &models.NewsBase{NewsID: newsUID, AuthorID: authorUID, Status: models.NewsStatusInReview}
+		change := &models.NewsStatusChange{Status: models.NewsStatusPublished}
+
+		mockNewsRepo.EXPECT().GetNewsByID(gomock.Any(), newsUID).Return(newsBase, nil)
+		mockNewsRepo.EXPECT().UpdateStatus(gomock.Any(), newsUID, models.NewsStatusInReview, change).Return(nil, errors.Wrap(sql.ErrNoRows, "newsRepo.UpdateStatus"))
+
+		n, err := newsUC.UpdateStatus(editorCtx, newsUID, change)
+		require.Error(t, err)
+		require.Nil(t, n)
+		require.Equal(t, http.StatusConflict, httpErrors.ParseErrors(err).Status())
+	})
+}
+
+func TestNewsUC_PublishScheduled(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{News: config.News{PublishBatchSize: 2}}
+	apiLogger := logger.NewApiLogger(nil)
+	mockNewsRepo := mock.NewMockRepository(ctrl)
+	mockRedisRepo := mock.NewMockRedisRepository(ctrl)
+	mockAuditUC := auditMock.NewMockUseCase(ctrl)
+	newsUC := NewNewsUseCase(cfg, mockNewsRepo, mockRedisRepo, mockAuditUC, apiLogger)
+
+	firstBatch := []uuid.UUID{uuid.New(), uuid.New()}
+	secondBatch := []uuid.UUID{uuid.New()}
+
+	gomock.InOrder(
+		mockNewsRepo.EXPECT().PublishScheduled(gomock.Any(), 2).Return(firstBatch, nil),
+		mockNewsRepo.EXPECT().PublishScheduled(gomock.Any(), 2).Return(secondBatch, nil),
+	)
+	mockRedisRepo.EXPECT().DeleteNewsCtx(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+	mockAuditUC.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+
+	published, err := newsUC.PublishScheduled(context.Background())
 	require.NoError(t, err)
-	require.Nil(t, err)
-	require.NotNil(t, news)
+	require.Equal(t, 3, published)
+}
+
+func TestBuildTSQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: "golang", expected: "golang"},
+		{query: "golang  news", expected: "golang & news"},
+		{query: "gol*", expected: "gol:*"},
+		{query: `"go release" notes`, expected: "(go <-> release) & notes"},
+		{query: `"go release`, expected: "go & release"},
+		{query: "e-mail*", expected: "(e <-> mail:*)"},
+		{query: "a&b | !c:*", expected: "(a <-> b) & c:*"},
+		{query: "новости", expected: "новости"},
+		{query: `"" * !`, expected: ""},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.expected, buildTSQuery(test.query), test.query)
+	}
 }
@@ -12,19 +12,27 @@ import (
 	echoSwagger "github.com/swaggo/echo-swagger"
 
 	// _ "github.com/AleksK1NG/api-mc/docs"
+	auditHttp "github.com/AleksK1NG/api-mc/internal/audit/delivery/http"
+	auditRepository "github.com/AleksK1NG/api-mc/internal/audit/repository"
+	auditUseCase "github.com/AleksK1NG/api-mc/internal/audit/usecase"
 	authHttp "github.com/AleksK1NG/api-mc/internal/auth/delivery/http"
 	authRepository "github.com/AleksK1NG/api-mc/internal/auth/repository"
 	authUseCase "github.com/AleksK1NG/api-mc/internal/auth/usecase"
 	commentsHttp "github.com/AleksK1NG/api-mc/internal/comments/delivery/http"
 	commentsRepository "github.com/AleksK1NG/api-mc/internal/comments/repository"
 	commentsUseCase "github.com/AleksK1NG/api-mc/internal/comments/usecase"
+	exportHttp "github.com/AleksK1NG/api-mc/internal/export/delivery/http"
+	exportRepository "github.com/AleksK1NG/api-mc/internal/export/repository"
+	exportUseCase "github.com/AleksK1NG/api-mc/internal/export/usecase"
 	apiMiddlewares "github.com/AleksK1NG/api-mc/internal/middleware"
 	newsHttp "github.com/AleksK1NG/api-mc/internal/news/delivery/http"
 	newsRepository "github.com/AleksK1NG/api-mc/internal/news/repository"
 	newsUseCase "github.com/AleksK1NG/api-mc/internal/news/usecase"
 	sessionRepository "github.com/AleksK1NG/api-mc/internal/session/repository"
 	"github.com/AleksK1NG/api-mc/internal/session/usecase"
+	"github.com/AleksK1NG/api-mc/pkg/jwtkeys"
 	"github.com/AleksK1NG/api-mc/pkg/metric"
+	"github.com/AleksK1NG/api-mc/pkg/passwordhash"
 	"github.com/AleksK1NG/api-mc/pkg/utils"
 )
 
@@ -48,24 +56,44 @@ func (s *Server) MapHandlers(e *echo.Echo) error {
 	aAWSRepo := authRepository.NewAuthAWSRepository(s.awsClient)
 	authRedisRepo := authRepository.NewAuthRedisRepo(s.redisClient)
 	newsRedisRepo := newsRepository.NewNewsRedisRepo(s.redisClient)
+	eRepo := exportRepository.NewExportRepository(s.db)
+	exportRedisRepo := exportRepository.NewExportRedisRepo(s.redisClient)
+	exportAWSRepo := exportRepository.NewExportAWSRepository(s.awsClient)
+	auditRepo := auditRepository.NewAuditRepository(s.db)
+
+	jwtKeys, err := jwtkeys.NewKeySet(s.cfg)
+	if err != nil {
+		return err
+	}
+	passwordHasher, err := passwordhash.New(s.cfg.PasswordHash)
+	if err != nil {
+		return err
+	}
 
 	// Init useCases
-	authUC := authUseCase.NewAuthUseCase(s.cfg, aRepo, authRedisRepo, aAWSRepo, s.logger)
-	newsUC := newsUseCase.NewNewsUseCase(s.cfg, nRepo, newsRedisRepo, s.logger)
-	commUC := commentsUseCase.NewCommentsUseCase(s.cfg, cRepo, s.logger)
+	auditUC := auditUseCase.NewAuditUseCase(s.cfg, auditRepo, s.logger)
+	authUC := authUseCase.NewAuthUseCase(s.cfg, aRepo, authRedisRepo, aAWSRepo, sRepo, s.mailer, jwtKeys, passwordHasher, auditUC, s.logger)
+	newsUC := newsUseCase.NewNewsUseCase(s.cfg, nRepo, newsRedisRepo, auditUC, s.logger)
+	commUC := commentsUseCase.NewCommentsUseCase(s.cfg, cRepo, newsUC, auditUC, s.logger)
 	sessUC := usecase.NewSessionUseCase(sRepo, s.cfg)
+	exportUC := exportUseCase.NewExportUseCase(s.cfg, eRepo, exportRedisRepo, exportAWSRepo, aRepo, aAWSRepo, sRepo, auditUC, s.logger)
+
+	s.runJobs(authUC, newsUC)
 
 	// Init handlers
-	authHandlers := authHttp.NewAuthHandlers(s.cfg, authUC, sessUC, s.logger)
+	authHandlers := authHttp.NewAuthHandlers(s.cfg, authUC, sessUC, auditUC, s.logger)
 	newsHandlers := newsHttp.NewNewsHandlers(s.cfg, newsUC, s.logger)
 	commHandlers := commentsHttp.NewCommentsHandlers(s.cfg, commUC, s.logger)
+	exportHandlers := exportHttp.NewExportHandlers(s.cfg, exportUC, s.logger)
+	auditHandlers := auditHttp.NewAuditHandlers(s.cfg, auditUC, s.logger)
 
-	mw := apiMiddlewares.NewMiddlewareManager(sessUC, authUC, s.cfg, []string{"*"}, s.logger)
+	mw := apiMiddlewares.NewMiddlewareManager(sessUC, authUC, s.cfg, jwtKeys, []string{"*"}, s.logger)
 
 	e.Use(mw.RequestLoggerMiddleware)
 
 	docs.SwaggerInfo.Title = "Go example REST API"
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
+	e.GET("/.well-known/jwks.json", authHandlers.GetJWKS())
 
 	if s.cfg.Server.SSL {
 		e.Pre(middleware.HTTPSRedirect())
@@ -101,10 +129,14 @@ func (s *Server) MapHandlers(e *echo.Echo) error {
 	authGroup := v1.Group("/auth")
 	newsGroup := v1.Group("/news")
 	commGroup := v1.Group("/comments")
+	exportGroup := v1.Group("/exports")
+	auditGroup := v1.Group("/audit")
 
 	authHttp.MapAuthRoutes(authGroup, authHandlers, mw)
 	newsHttp.MapNewsRoutes(newsGroup, newsHandlers, mw)
 	commentsHttp.MapCommentsRoutes(commGroup, commHandlers, mw)
+	exportHttp.MapExportRoutes(exportGroup, exportHandlers, mw)
+	auditHttp.MapAuditRoutes(auditGroup, auditHandlers, mw)
 
 	health.GET("", func(c echo.Context) error {
 		s.logger.Infof("Health check RequestID: %s", utils.GetRequestID(c))
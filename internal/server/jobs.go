@@ -0,0 +1,33 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/AleksK1NG/api-mc/internal/auth"
+	"github.com/AleksK1NG/api-mc/internal/news"
+	"github.com/AleksK1NG/api-mc/pkg/scheduler"
+)
+
+// Start background jobs, jobs are stopped on server shutdown
+func (s *Server) runJobs(authUC auth.UseCase, newsUC news.UseCase) {
+	if s.cfg.Deletion.PurgeInterval > 0 {
+		go scheduler.Run(s.jobsCtx, "purgeDeletedUsers", time.Duration(s.cfg.Deletion.PurgeInterval)*time.Second, func(ctx context.Context) error {
+			purged, err := authUC.PurgeDeletedUsers(ctx)
+			if purged > 0 {
+				s.logger.Infof("Purged deleted users: %d", purged)
+			}
+			return err
+		}, s.logger)
+	}
+
+	if s.cfg.News.PublishInterval > 0 {
+		go scheduler.Run(s.jobsCtx, "publishScheduledNews", time.Duration(s.cfg.News.PublishInterval)*time.Second, func(ctx context.Context) error {
+			published, err := newsUC.PublishScheduled(ctx)
+			if published > 0 {
+				s.logger.Infof("Published scheduled news: %d", published)
+			}
+			return err
+		}, s.logger)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/AleksK1NG/api-mc/config"
 	_ "github.com/AleksK1NG/api-mc/docs"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
+	"github.com/AleksK1NG/api-mc/pkg/mailer"
 )
 
 const (
@@ -33,12 +34,27 @@ type Server struct {
 	db          *sqlx.DB
 	redisClient *redis.Client
 	awsClient   *minio.Client
+	mailer      mailer.Mailer
 	logger      logger.Logger
+	// Background jobs context, canceled on shutdown
+	jobsCtx  context.Context
+	stopJobs context.CancelFunc
 }
 
 // NewServer New Server constructor
-func NewServer(cfg *config.Config, db *sqlx.DB, redisClient *redis.Client, awsS3Client *minio.Client, logger logger.Logger) *Server {
-	return &Server{echo: echo.New(), cfg: cfg, db: db, redisClient: redisClient, awsClient: awsS3Client, logger: logger}
+func NewServer(cfg *config.Config, db *sqlx.DB, redisClient *redis.Client, awsS3Client *minio.Client, mailSender mailer.Mailer, logger logger.Logger) *Server {
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	return &Server{
+		echo:        echo.New(),
+		cfg:         cfg,
+		db:          db,
+		redisClient: redisClient,
+		awsClient:   awsS3Client,
+		mailer:      mailSender,
+		logger:      logger,
+		jobsCtx:     jobsCtx,
+		stopJobs:    stopJobs,
+	}
 }
 
 func (s *Server) Run() error {
@@ -71,6 +87,7 @@ func (s *Server) Run() error {
 		signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
 		<-quit
+		s.stopJobs()
 
 		ctx, shutdown := context.WithTimeout(context.Background(), ctxTimeout*time.Second)
 		defer shutdown()
@@ -108,6 +125,7 @@ func (s *Server) Run() error {
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
 	<-quit
+	s.stopJobs()
 
 	ctx, shutdown := context.WithTimeout(context.Background(), ctxTimeout*time.Second)
 	defer shutdown()
@@ -8,7 +8,9 @@ import (
 	context "context"
 	models "github.com/AleksK1NG/api-mc/internal/models"
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 	reflect "reflect"
+	time "time"
 )
 
 // MockSessRepository is a mock of SessRepository interface
@@ -77,3 +79,60 @@ func (mr *MockSessRepositoryMockRecorder) DeleteByID(ctx, sessionID interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockSessRepository)(nil).DeleteByID), ctx, sessionID)
 }
+
+// DeleteAllByUserID mocks base method
+func (m *MockSessRepository) DeleteAllByUserID(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAllByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAllByUserID indicates an expected call of DeleteAllByUserID
+func (mr *MockSessRepositoryMockRecorder) DeleteAllByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllByUserID", reflect.TypeOf((*MockSessRepository)(nil).DeleteAllByUserID), ctx, userID)
+}
+
+// GetSessionsByUserID mocks base method
+func (m *MockSessRepository) GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionsByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionsByUserID indicates an expected call of GetSessionsByUserID
+func (mr *MockSessRepositoryMockRecorder) GetSessionsByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionsByUserID", reflect.TypeOf((*MockSessRepository)(nil).GetSessionsByUserID), ctx, userID)
+}
+
+// UpdateLastSeen mocks base method
+func (m *MockSessRepository) UpdateLastSeen(ctx context.Context, sessionID string, lastSeen time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLastSeen", ctx, sessionID, lastSeen)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLastSeen indicates an expected call of UpdateLastSeen
+func (mr *MockSessRepositoryMockRecorder) UpdateLastSeen(ctx, sessionID, lastSeen interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastSeen", reflect.TypeOf((*MockSessRepository)(nil).UpdateLastSeen), ctx, sessionID, lastSeen)
+}
+
+// DeleteSession mocks base method
+func (m *MockSessRepository) DeleteSession(ctx context.Context, sess *models.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", ctx, sess)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession
+func (mr *MockSessRepositoryMockRecorder) DeleteSession(ctx, sess interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockSessRepository)(nil).DeleteSession), ctx, sess)
+}
@@ -8,7 +8,9 @@ import (
 	context "context"
 	models "github.com/AleksK1NG/api-mc/internal/models"
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 	reflect "reflect"
+	time "time"
 )
 
 // MockUCSession is a mock of UCSession interface
@@ -77,3 +79,60 @@ func (mr *MockUCSessionMockRecorder) DeleteByID(ctx, sessionID interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockUCSession)(nil).DeleteByID), ctx, sessionID)
 }
+
+// DeleteAllByUserID mocks base method
+func (m *MockUCSession) DeleteAllByUserID(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAllByUserID", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAllByUserID indicates an expected call of DeleteAllByUserID
+func (mr *MockUCSessionMockRecorder) DeleteAllByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllByUserID", reflect.TypeOf((*MockUCSession)(nil).DeleteAllByUserID), ctx, userID)
+}
+
+// GetSessionsByUserID mocks base method
+func (m *MockUCSession) GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionsByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionsByUserID indicates an expected call of GetSessionsByUserID
+func (mr *MockUCSessionMockRecorder) GetSessionsByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionsByUserID", reflect.TypeOf((*MockUCSession)(nil).GetSessionsByUserID), ctx, userID)
+}
+
+// UpdateLastSeen mocks base method
+func (m *MockUCSession) UpdateLastSeen(ctx context.Context, sessionID string, lastSeen time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLastSeen", ctx, sessionID, lastSeen)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLastSeen indicates an expected call of UpdateLastSeen
+func (mr *MockUCSessionMockRecorder) UpdateLastSeen(ctx, sessionID, lastSeen interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastSeen", reflect.TypeOf((*MockUCSession)(nil).UpdateLastSeen), ctx, sessionID, lastSeen)
+}
+
+// DeleteUserSession mocks base method
+func (m *MockUCSession) DeleteUserSession(ctx context.Context, userID uuid.UUID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserSession", ctx, userID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserSession indicates an expected call of DeleteUserSession
+func (mr *MockUCSessionMockRecorder) DeleteUserSession(ctx, userID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserSession", reflect.TypeOf((*MockUCSession)(nil).DeleteUserSession), ctx, userID, id)
+}
@@ -17,9 +17,32 @@ import (
 )
 
 const (
-	basePrefix = "api-session:"
+	basePrefix    = "api-session:"
+	userSetPrefix = "api-session-user:"
 )
 
+// Add session to the user index, the index lifetime is only ever extended so that
+// a short session can't make the index expire before longer sessions of the user
+const addToUserSetScript = `
+redis.call('SADD', KEYS[1], ARGV[1])
+local ttl = redis.call('TTL', KEYS[1])
+if ttl < tonumber(ARGV[2]) then
+	redis.call('EXPIRE', KEYS[1], ARGV[2])
+end
+return ttl
+`
+
+// Replace session value keeping its expiration in one step, so a session revoked
+// concurrently is not written back
+const replaceSessionScript = `
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl <= 0 then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ttl)
+return 1
+`
+
 // Session repository
 type sessionRepo struct {
 	redisClient *redis.Client
@@ -38,14 +61,22 @@ func (s *sessionRepo) CreateSession(ctx context.Context, sess *models.Session, e
 	defer span.Finish()
 
 	sess.SessionID = uuid.New().String()
+	sess.ID = uuid.New().String()
+	sess.CreatedAt = time.Now().UTC()
+	sess.LastSeen = sess.CreatedAt
 	sessionKey := s.createKey(sess.SessionID)
 
 	sessBytes, err := json.Marshal(&sess)
 	if err != nil {
 		return "", errors.WithMessage(err, "sessionRepo.CreateSession.json.Marshal")
 	}
-	if err = s.redisClient.Set(ctx, sessionKey, sessBytes, time.Second*time.Duration(expire)).Err(); err != nil {
-		return "", errors.Wrap(err, "sessionRepo.CreateSession.redisClient.Set")
+
+	userSetKey := s.createUserSetKey(sess.UserID.String())
+	pipe := s.redisClient.TxPipeline()
+	pipe.Set(ctx, sessionKey, sessBytes, time.Second*time.Duration(expire))
+	pipe.Eval(ctx, addToUserSetScript, []string{userSetKey}, sessionKey, expire)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return "", errors.Wrap(err, "sessionRepo.CreateSession.pipe.Exec")
 	}
 	return sessionKey, nil
 }
@@ -78,6 +109,116 @@ func (s *sessionRepo) DeleteByID(ctx context.Context, sessionID string) error {
 	return nil
 }
 
+// Delete all sessions of the user
+func (s *sessionRepo) DeleteAllByUserID(ctx context.Context, userID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.DeleteAllByUserID")
+	defer span.Finish()
+
+	userSetKey := s.createUserSetKey(userID.String())
+	sessionKeys, err := s.redisClient.SMembers(ctx, userSetKey).Result()
+	if err != nil {
+		return errors.Wrap(err, "sessionRepo.DeleteAllByUserID.redisClient.SMembers")
+	}
+
+	if err = s.redisClient.Del(ctx, append(sessionKeys, userSetKey)...).Err(); err != nil {
+		return errors.Wrap(err, "sessionRepo.DeleteAllByUserID.redisClient.Del")
+	}
+	return nil
+}
+
+// Get all active sessions of the user, expired sessions are removed from the user index
+func (s *sessionRepo) GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.GetSessionsByUserID")
+	defer span.Finish()
+
+	userSetKey := s.createUserSetKey(userID.String())
+	sessionKeys, err := s.redisClient.SMembers(ctx, userSetKey).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "sessionRepo.GetSessionsByUserID.redisClient.SMembers")
+	}
+
+	sessions := make([]*models.Session, 0, len(sessionKeys))
+	if len(sessionKeys) == 0 {
+		return sessions, nil
+	}
+
+	values, err := s.redisClient.MGet(ctx, sessionKeys...).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "sessionRepo.GetSessionsByUserID.redisClient.MGet")
+	}
+
+	expiredKeys := make([]interface{}, 0)
+	for i, value := range values {
+		sessJSON, ok := value.(string)
+		if !ok {
+			expiredKeys = append(expiredKeys, sessionKeys[i])
+			continue
+		}
+		sess := &models.Session{}
+		if err = json.Unmarshal([]byte(sessJSON), sess); err != nil {
+			return nil, errors.Wrap(err, "sessionRepo.GetSessionsByUserID.json.Unmarshal")
+		}
+		sessions = append(sessions, sess)
+	}
+
+	if len(expiredKeys) > 0 {
+		if err = s.redisClient.SRem(ctx, userSetKey, expiredKeys...).Err(); err != nil {
+			return nil, errors.Wrap(err, "sessionRepo.GetSessionsByUserID.redisClient.SRem")
+		}
+	}
+
+	return sessions, nil
+}
+
+// Update session last seen time keeping session expiration
+func (s *sessionRepo) UpdateLastSeen(ctx context.Context, sessionID string, lastSeen time.Time) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.UpdateLastSeen")
+	defer span.Finish()
+
+	sess, err := s.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	sess.LastSeen = lastSeen.UTC()
+	sessBytes, err := json.Marshal(sess)
+	if err != nil {
+		return errors.Wrap(err, "sessionRepo.UpdateLastSeen.json.Marshal")
+	}
+	return s.replaceSession(ctx, sessionID, sessBytes)
+}
+
+// Overwrite existing session keeping its expiration, fails with redis.Nil if session was deleted meanwhile
+func (s *sessionRepo) replaceSession(ctx context.Context, sessionKey string, sessBytes []byte) error {
+	replaced, err := s.redisClient.Eval(ctx, replaceSessionScript, []string{sessionKey}, sessBytes).Int()
+	if err != nil {
+		return errors.Wrap(err, "sessionRepo.replaceSession.redisClient.Eval")
+	}
+	if replaced == 0 {
+		return errors.Wrap(redis.Nil, "sessionRepo.replaceSession")
+	}
+	return nil
+}
+
+// Delete session and remove it from the user index
+func (s *sessionRepo) DeleteSession(ctx context.Context, sess *models.Session) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionRepo.DeleteSession")
+	defer span.Finish()
+
+	sessionKey := s.createKey(sess.SessionID)
+	pipe := s.redisClient.TxPipeline()
+	pipe.Del(ctx, sessionKey)
+	pipe.SRem(ctx, s.createUserSetKey(sess.UserID.String()), sessionKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrap(err, "sessionRepo.DeleteSession.pipe.Exec")
+	}
+	return nil
+}
+
 func (s *sessionRepo) createKey(sessionID string) string {
 	return fmt.Sprintf("%s: %s", s.basePrefix, sessionID)
 }
+
+func (s *sessionRepo) createUserSetKey(userID string) string {
+	return fmt.Sprintf("%s: %s", userSetPrefix, userID)
+}
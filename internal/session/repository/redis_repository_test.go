@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis"
 	"github.com/go-redis/redis/v8"
@@ -76,3 +79,143 @@ func TestSessionRepo_DeleteByID(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestSessionRepo_DeleteAllByUserID(t *testing.T) {
+	t.Parallel()
+
+	sessRepository := SetupRedis()
+
+	t.Run("DeleteAllByUserID", func(t *testing.T) {
+		userID := uuid.New()
+		first, err := sessRepository.CreateSession(context.Background(), &models.Session{UserID: userID}, 10)
+		require.NoError(t, err)
+		second, err := sessRepository.CreateSession(context.Background(), &models.Session{UserID: userID}, 10)
+		require.NoError(t, err)
+		other, err := sessRepository.CreateSession(context.Background(), &models.Session{UserID: uuid.New()}, 10)
+		require.NoError(t, err)
+
+		err = sessRepository.DeleteAllByUserID(context.Background(), userID)
+		require.NoError(t, err)
+
+		_, err = sessRepository.GetSessionByID(context.Background(), first)
+		require.Error(t, err)
+		_, err = sessRepository.GetSessionByID(context.Background(), second)
+		require.Error(t, err)
+		s, err := sessRepository.GetSessionByID(context.Background(), other)
+		require.NoError(t, err)
+		require.NotNil(t, s)
+	})
+}
+
+func TestSessionRepo_DeleteAllByUserIDAfterShortSessionExpired(t *testing.T) {
+	t.Parallel()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	sessRepository := NewSessionRepository(redis.NewClient(&redis.Options{Addr: mr.Addr()}), nil)
+
+	t.Run("DeleteAllByUserID", func(t *testing.T) {
+		userID := uuid.New()
+		long, err := sessRepository.CreateSession(context.Background(), &models.Session{UserID: userID}, 3600)
+		require.NoError(t, err)
+		_, err = sessRepository.CreateSession(context.Background(), &models.Session{UserID: userID}, 900)
+		require.NoError(t, err)
+
+		mr.FastForward(901 * time.Second)
+
+		sessions, err := sessRepository.GetSessionsByUserID(context.Background(), userID)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+
+		err = sessRepository.DeleteAllByUserID(context.Background(), userID)
+		require.NoError(t, err)
+
+		_, err = sessRepository.GetSessionByID(context.Background(), long)
+		require.Error(t, err)
+	})
+}
+
+func TestSessionRepo_GetSessionsByUserID(t *testing.T) {
+	t.Parallel()
+
+	sessRepository := SetupRedis()
+
+	t.Run("GetSessionsByUserID", func(t *testing.T) {
+		userID := uuid.New()
+		_, err := sessRepository.CreateSession(context.Background(), &models.Session{UserID: userID, IPAddress: "127.0.0.1"}, 10)
+		require.NoError(t, err)
+		second, err := sessRepository.CreateSession(context.Background(), &models.Session{UserID: userID, UserAgent: "curl"}, 10)
+		require.NoError(t, err)
+
+		err = sessRepository.DeleteByID(context.Background(), second)
+		require.NoError(t, err)
+
+		sessions, err := sessRepository.GetSessionsByUserID(context.Background(), userID)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		require.Equal(t, "127.0.0.1", sessions[0].IPAddress)
+		require.NotEmpty(t, sessions[0].ID)
+		require.False(t, sessions[0].CreatedAt.IsZero())
+	})
+}
+
+func TestSessionRepo_UpdateLastSeen(t *testing.T) {
+	t.Parallel()
+
+	sessRepository := SetupRedis()
+
+	t.Run("UpdateLastSeen", func(t *testing.T) {
+		sessionKey, err := sessRepository.CreateSession(context.Background(), &models.Session{UserID: uuid.New()}, 10)
+		require.NoError(t, err)
+
+		lastSeen := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		err = sessRepository.UpdateLastSeen(context.Background(), sessionKey, lastSeen)
+		require.NoError(t, err)
+
+		sess, err := sessRepository.GetSessionByID(context.Background(), sessionKey)
+		require.NoError(t, err)
+		require.True(t, lastSeen.Equal(sess.LastSeen))
+	})
+
+	t.Run("Session deleted between read and write", func(t *testing.T) {
+		sessionKey, err := sessRepository.CreateSession(context.Background(), &models.Session{UserID: uuid.New()}, 10)
+		require.NoError(t, err)
+
+		sess, err := sessRepository.GetSessionByID(context.Background(), sessionKey)
+		require.NoError(t, err)
+		sessBytes, err := json.Marshal(sess)
+		require.NoError(t, err)
+
+		err = sessRepository.DeleteSession(context.Background(), sess)
+		require.NoError(t, err)
+
+		err = sessRepository.(*sessionRepo).replaceSession(context.Background(), sessionKey, sessBytes)
+		require.True(t, errors.Is(err, redis.Nil))
+
+		_, err = sessRepository.GetSessionByID(context.Background(), sessionKey)
+		require.Error(t, err)
+	})
+}
+
+func TestSessionRepo_DeleteSession(t *testing.T) {
+	t.Parallel()
+
+	sessRepository := SetupRedis()
+
+	t.Run("DeleteSession", func(t *testing.T) {
+		userID := uuid.New()
+		sessionKey, err := sessRepository.CreateSession(context.Background(), &models.Session{UserID: userID}, 10)
+		require.NoError(t, err)
+
+		sess, err := sessRepository.GetSessionByID(context.Background(), sessionKey)
+		require.NoError(t, err)
+
+		err = sessRepository.DeleteSession(context.Background(), sess)
+		require.NoError(t, err)
+
+		sessions, err := sessRepository.GetSessionsByUserID(context.Background(), userID)
+		require.NoError(t, err)
+		require.Len(t, sessions, 0)
+	})
+}
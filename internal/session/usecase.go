@@ -3,6 +3,9 @@ package session
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/AleksK1NG/api-mc/internal/models"
 )
@@ -12,4 +15,8 @@ type UCSession interface {
 	CreateSession(ctx context.Context, session *models.Session, expire int) (string, error)
 	GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error)
 	DeleteByID(ctx context.Context, sessionID string) error
+	DeleteAllByUserID(ctx context.Context, userID uuid.UUID) error
+	GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error)
+	UpdateLastSeen(ctx context.Context, sessionID string, lastSeen time.Time) error
+	DeleteUserSession(ctx context.Context, userID uuid.UUID, id string) error
 }
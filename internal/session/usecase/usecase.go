@@ -2,12 +2,17 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
 
 	"github.com/AleksK1NG/api-mc/config"
 	"github.com/AleksK1NG/api-mc/internal/models"
 	"github.com/AleksK1NG/api-mc/internal/session"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
 )
 
 // Session use case
@@ -37,6 +42,49 @@ func (u *sessionUC) DeleteByID(ctx context.Context, sessionID string) error {
 	return u.sessionRepo.DeleteByID(ctx, sessionID)
 }
 
+// Delete all sessions of the user
+func (u *sessionUC) DeleteAllByUserID(ctx context.Context, userID uuid.UUID) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.DeleteAllByUserID")
+	defer span.Finish()
+
+	return u.sessionRepo.DeleteAllByUserID(ctx, userID)
+}
+
+// Get all active sessions of the user
+func (u *sessionUC) GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.GetSessionsByUserID")
+	defer span.Finish()
+
+	return u.sessionRepo.GetSessionsByUserID(ctx, userID)
+}
+
+// Update session last seen time
+func (u *sessionUC) UpdateLastSeen(ctx context.Context, sessionID string, lastSeen time.Time) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.UpdateLastSeen")
+	defer span.Finish()
+
+	return u.sessionRepo.UpdateLastSeen(ctx, sessionID, lastSeen)
+}
+
+// Delete user session by public session id
+func (u *sessionUC) DeleteUserSession(ctx context.Context, userID uuid.UUID, id string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.DeleteUserSession")
+	defer span.Finish()
+
+	sessions, err := u.sessionRepo.GetSessionsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, sess := range sessions {
+		if sess.ID == id {
+			return u.sessionRepo.DeleteSession(ctx, sess)
+		}
+	}
+
+	return httpErrors.NewNotFoundError(errors.Wrap(sql.ErrNoRows, "sessionUC.DeleteUserSession"))
+}
+
 // get session by id
 func (u *sessionUC) GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "sessionUC.GetSessionByID")
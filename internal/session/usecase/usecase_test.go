@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
 	"github.com/AleksK1NG/api-mc/internal/models"
@@ -71,3 +72,51 @@ func TestSessionUC_DeleteByID(t *testing.T) {
 	require.NoError(t, err)
 	require.Nil(t, err)
 }
+
+func TestSessionUC_DeleteAllByUserID(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSessRepo := mock.NewMockSessRepository(ctrl)
+	sessUC := NewSessionUseCase(mockSessRepo, nil)
+
+	ctx := context.Background()
+	userID := uuid.New()
+
+	mockSessRepo.EXPECT().DeleteAllByUserID(gomock.Any(), gomock.Eq(userID)).Return(nil)
+
+	err := sessUC.DeleteAllByUserID(ctx, userID)
+	require.NoError(t, err)
+	require.Nil(t, err)
+}
+
+func TestSessionUC_DeleteUserSession(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSessRepo := mock.NewMockSessRepository(ctrl)
+	sessUC := NewSessionUseCase(mockSessRepo, nil)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	sess := &models.Session{SessionID: "session id", ID: "public id", UserID: userID}
+
+	t.Run("DeleteUserSession", func(t *testing.T) {
+		mockSessRepo.EXPECT().GetSessionsByUserID(gomock.Any(), gomock.Eq(userID)).Return([]*models.Session{sess}, nil)
+		mockSessRepo.EXPECT().DeleteSession(gomock.Any(), gomock.Eq(sess)).Return(nil)
+
+		err := sessUC.DeleteUserSession(ctx, userID, sess.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("DeleteUserSession Not found", func(t *testing.T) {
+		mockSessRepo.EXPECT().GetSessionsByUserID(gomock.Any(), gomock.Eq(userID)).Return([]*models.Session{sess}, nil)
+
+		err := sessUC.DeleteUserSession(ctx, userID, "other id")
+		require.Error(t, err)
+	})
+}
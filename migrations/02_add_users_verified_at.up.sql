@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS verified_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+-- accounts created before email verification was introduced are treated as verified
+UPDATE users SET verified_at = created_at WHERE verified_at IS NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_recovery_codes CASCADE;
+DROP TABLE IF EXISTS user_totp CASCADE;
+
+ALTER TABLE users DROP COLUMN IF EXISTS two_factor_enabled;
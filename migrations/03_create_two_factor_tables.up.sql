@@ -0,0 +1,21 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS two_factor_enabled BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS user_totp
+(
+    user_id        UUID PRIMARY KEY         NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    secret         VARCHAR(64)              NOT NULL CHECK ( secret <> '' ),
+    last_used_step BIGINT                   NOT NULL DEFAULT 0,
+    confirmed_at   TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS user_recovery_codes
+(
+    code_hash  VARCHAR(64) PRIMARY KEY  NOT NULL,
+    user_id    UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    used_at    TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS user_recovery_codes_user_id_idx ON user_recovery_codes (user_id);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    api_key_id   UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    user_id      UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    name         VARCHAR(64)              NOT NULL CHECK ( name <> '' ),
+    prefix       VARCHAR(32)              NOT NULL,
+    key_hash     VARCHAR(64) UNIQUE       NOT NULL,
+    scopes       VARCHAR(250)             NOT NULL DEFAULT '',
+    expires_at   TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_user_id_idx ON api_keys (user_id);
@@ -0,0 +1,57 @@
+CREATE TABLE IF NOT EXISTS roles
+(
+    name        VARCHAR(10) PRIMARY KEY  NOT NULL CHECK ( name <> '' ),
+    description VARCHAR(250)             NOT NULL DEFAULT '',
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS permissions
+(
+    name        VARCHAR(64) PRIMARY KEY NOT NULL CHECK ( name <> '' ),
+    description VARCHAR(250)            NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions
+(
+    role       VARCHAR(10) NOT NULL REFERENCES roles (name) ON DELETE CASCADE ON UPDATE CASCADE,
+    permission VARCHAR(64) NOT NULL REFERENCES permissions (name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name, description)
+VALUES ('user', 'Regular user'),
+       ('editor', 'Publishes and edits news'),
+       ('moderator', 'Moderates news and comments'),
+       ('admin', 'Full access')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO roles (name)
+SELECT DISTINCT role
+FROM users
+ON CONFLICT DO NOTHING;
+
+INSERT INTO permissions (name, description)
+VALUES ('news:publish', 'Publish news'),
+       ('news:moderate', 'Update and delete any news'),
+       ('comments:moderate', 'Update and delete any comment'),
+       ('users:update', 'Update any user'),
+       ('users:delete', 'Delete users'),
+       ('users:unlock', 'Unlock locked accounts'),
+       ('users:sessions', 'List and revoke sessions of any user'),
+       ('users:roles', 'Assign user roles')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('editor', 'news:publish'),
+       ('editor', 'news:moderate'),
+       ('moderator', 'news:moderate'),
+       ('moderator', 'comments:moderate')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', name
+FROM permissions
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE users
+    ADD CONSTRAINT users_role_fkey FOREIGN KEY (role) REFERENCES roles (name) ON UPDATE CASCADE;
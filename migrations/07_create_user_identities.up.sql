@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS user_identities
+(
+    identity_id UUID PRIMARY KEY         NOT NULL DEFAULT uuid_generate_v4(),
+    user_id     UUID                     NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    provider    VARCHAR(32)              NOT NULL CHECK ( provider <> '' ),
+    subject     VARCHAR(250)             NOT NULL CHECK ( subject <> '' ),
+    email       VARCHAR(64)                       DEFAULT NULL,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS user_identities_user_id_idx ON user_identities (user_id);
@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS pending_email VARCHAR(64) DEFAULT NULL CHECK ( pending_email <> '' );
@@ -0,0 +1,6 @@
+DELETE FROM permissions WHERE name = 'users:status';
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS status_until,
+    DROP COLUMN IF EXISTS status_reason,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,12 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS status        VARCHAR(10) NOT NULL DEFAULT 'active' CHECK ( status IN ('active', 'suspended', 'banned') ),
+    ADD COLUMN IF NOT EXISTS status_reason VARCHAR(250)             DEFAULT NULL,
+    ADD COLUMN IF NOT EXISTS status_until  TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+INSERT INTO permissions (name, description)
+VALUES ('users:status', 'Suspend, ban and reinstate users')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('admin', 'users:status')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,7 @@
+INSERT INTO permissions (name, description)
+VALUES ('users:impersonate', 'Sign in as another user for support')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('admin', 'users:impersonate')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,7 @@
+INSERT INTO permissions (name, description)
+VALUES ('users:export', 'Export personal data of any user')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('admin', 'users:export')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE name = 'audit:read';
+
+DROP TABLE IF EXISTS audit_events CASCADE;
+DROP FUNCTION IF EXISTS audit_events_append_only();
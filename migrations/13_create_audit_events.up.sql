@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS audit_events
+(
+    event_id    UUID PRIMARY KEY                  DEFAULT uuid_generate_v4(),
+    action      VARCHAR(50)              NOT NULL CHECK ( action <> '' ),
+    actor_id    UUID                              DEFAULT NULL,
+    target_type VARCHAR(20)              NOT NULL DEFAULT '',
+    target_id   VARCHAR(64)              NOT NULL DEFAULT '',
+    ip_address  VARCHAR(64)              NOT NULL DEFAULT '',
+    user_agent  VARCHAR(512)             NOT NULL DEFAULT '',
+    request_id  VARCHAR(64)              NOT NULL DEFAULT '',
+    metadata    JSONB                             DEFAULT NULL,
+    before_data JSONB                             DEFAULT NULL,
+    after_data  JSONB                             DEFAULT NULL,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_created_at_idx ON audit_events (created_at);
+CREATE INDEX IF NOT EXISTS audit_events_actor_id_idx ON audit_events (actor_id, created_at);
+CREATE INDEX IF NOT EXISTS audit_events_target_idx ON audit_events (target_id, created_at);
+CREATE INDEX IF NOT EXISTS audit_events_action_idx ON audit_events (action, created_at);
+
+-- Audit log is append-only
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS TRIGGER AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update_delete
+    BEFORE UPDATE OR DELETE
+    ON audit_events
+    FOR EACH ROW
+EXECUTE PROCEDURE audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE
+    ON audit_events
+    FOR EACH STATEMENT
+EXECUTE PROCEDURE audit_events_append_only();
+
+INSERT INTO permissions (name, description)
+VALUES ('audit:read', 'Query audit log')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('admin', 'audit:read')
+ON CONFLICT DO NOTHING;
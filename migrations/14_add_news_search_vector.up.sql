@@ -0,0 +1,10 @@
+ALTER TABLE news
+    ADD COLUMN IF NOT EXISTS search_language REGCONFIG NOT NULL DEFAULT 'english';
+
+ALTER TABLE news
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector(search_language, COALESCE(title, '')), 'A') ||
+        setweight(to_tsvector(search_language, COALESCE(content, '')), 'B')
+        ) STORED;
+
+CREATE INDEX IF NOT EXISTS news_search_vector_idx ON news USING GIN (search_vector);
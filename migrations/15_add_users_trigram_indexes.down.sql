@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS users_email_trgm_idx;
+DROP INDEX IF EXISTS users_full_name_trgm_idx;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS news_scheduled_at_idx;
+DROP INDEX IF EXISTS news_status_idx;
+
+ALTER TABLE news
+    DROP COLUMN IF EXISTS published_at,
+    DROP COLUMN IF EXISTS scheduled_at,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,13 @@
+ALTER TABLE news
+    ADD COLUMN IF NOT EXISTS status       VARCHAR(16) NOT NULL DEFAULT 'draft'
+        CHECK ( status IN ('draft', 'in_review', 'scheduled', 'published', 'archived') ),
+    ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    ADD COLUMN IF NOT EXISTS published_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+-- News created before editorial workflow were public
+UPDATE news
+SET status       = 'published',
+    published_at = created_at;
+
+CREATE INDEX IF NOT EXISTS news_status_idx ON news (status);
+CREATE INDEX IF NOT EXISTS news_scheduled_at_idx ON news (scheduled_at) WHERE status = 'scheduled';
@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strings"
+	"time"
 )
 
 const (
@@ -38,6 +40,38 @@ var (
 	ExistsEmailError      = errors.New("User with given email already exists")
 	InvalidJWTToken       = errors.New("Invalid JWT token")
 	InvalidJWTClaims      = errors.New("Invalid JWT claims")
+	InvalidRefreshToken   = errors.New("Invalid refresh token")
+	RefreshTokenReused    = errors.New("Refresh token reuse detected")
+	InvalidResetToken     = errors.New("Invalid or expired password reset token")
+	InvalidVerifyToken    = errors.New("Invalid or expired email verification token")
+	EmailNotVerified      = errors.New("Email is not verified")
+	TwoFactorEnabled      = errors.New("Two factor authentication already enabled")
+	TwoFactorNotEnrolled  = errors.New("Two factor authentication is not enrolled")
+	TwoFactorRequired     = errors.New("Two factor authentication required")
+	InvalidTwoFactorCode  = errors.New("Invalid two factor code")
+	InvalidChallenge      = errors.New("Invalid or expired login challenge")
+	AccountLocked         = errors.New("Account temporarily locked, too many failed login attempts")
+	AccountSuspended      = errors.New("Account is suspended")
+	AccountBanned         = errors.New("Account is banned")
+	InvalidStatusUntil    = errors.New("Status expiry must be in the future")
+	NotImpersonating      = errors.New("Session is not impersonating")
+	ImpersonationDenied   = errors.New("User can not be impersonated")
+	ImpersonationBlocked  = errors.New("Action is not allowed while impersonating")
+	InvalidAPIKey         = errors.New("Invalid or expired API key")
+	APIKeyExpirationError = errors.New("API key expiration must be in the future")
+	APIKeyScopeRequired   = errors.New("API key has no required scope")
+	TooManyRequests       = errors.New("Too many requests, try again later")
+	EmailChangeNotAllowed = errors.New("Email can be changed only with confirmation")
+	FieldUpdateDenied     = errors.New("Not allowed to update field")
+	InvalidNewsTransition = errors.New("News status can not be changed to given status")
+	InvalidScheduledAt    = errors.New("Scheduled time must be in the future")
+	InvalidEmailChange    = errors.New("Invalid or expired email change token")
+	InvalidMagicLink      = errors.New("Invalid or expired magic link")
+	WeakPassword          = errors.New("Password does not satisfy password policy")
+	ExportInProgress      = errors.New("Personal data export is already in progress")
+	OIDCProviderNotFound  = errors.New("OIDC provider not found")
+	InvalidOIDCState      = errors.New("Invalid or expired OIDC state")
+	OIDCEmailNotVerified  = errors.New("OIDC provider returned no verified email")
 	NotAllowedImageHeader = errors.New("Not allowed image header")
 	NoCookie              = errors.New("not found cookie header")
 )
@@ -71,6 +105,24 @@ func (e RestError) Causes() interface{} {
 	return e.ErrCauses
 }
 
+// Account lockout error, carries seconds to wait before next attempt
+type LockoutError struct {
+	RestError
+	RetryAfter int `json:"retry_after"`
+}
+
+// Violated password policy rule
+type PasswordViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Password policy error, lists all violated rules
+type PasswordPolicyError struct {
+	RestError
+	Violations []PasswordViolation `json:"violations"`
+}
+
 // New Rest Error
 func NewRestError(status int, err string, causes interface{}) RestErr {
 	return RestError{
@@ -144,6 +196,41 @@ func NewInternalServerError(causes interface{}) RestErr {
 	return result
 }
 
+// New Lockout Error, retry after is rounded up to whole seconds
+func NewLockoutError(retryAfter time.Duration) RestErr {
+	return newRetryAfterError(AccountLocked, retryAfter)
+}
+
+// New Too Many Requests Error, retry after is rounded up to whole seconds
+func NewTooManyRequestsError(retryAfter time.Duration) RestErr {
+	return newRetryAfterError(TooManyRequests, retryAfter)
+}
+
+// New Password Policy Error
+func NewPasswordPolicyError(violations []PasswordViolation) RestErr {
+	return PasswordPolicyError{
+		RestError: RestError{
+			ErrStatus: http.StatusBadRequest,
+			ErrError:  WeakPassword.Error(),
+		},
+		Violations: violations,
+	}
+}
+
+func newRetryAfterError(err error, retryAfter time.Duration) RestErr {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return LockoutError{
+		RestError: RestError{
+			ErrStatus: http.StatusTooManyRequests,
+			ErrError:  err.Error(),
+		},
+		RetryAfter: seconds,
+	}
+}
+
 // Parser of error string messages returns RestError
 func ParseErrors(err error) RestErr {
 	switch {
@@ -173,8 +260,13 @@ func ParseErrors(err error) RestErr {
 	}
 }
 
+// Check postgres unique constraint violation
+func IsUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "23505")
+}
+
 func parseSqlErrors(err error) RestErr {
-	if strings.Contains(err.Error(), "23505") {
+	if IsUniqueViolation(err) {
 		return NewRestError(http.StatusBadRequest, ExistsEmailError.Error(), err)
 	}
 
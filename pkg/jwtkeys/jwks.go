@@ -0,0 +1,105 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JSON Web Key, only public parameters
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+func newJWK(key *Key) JWK {
+	jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Algorithm}
+
+	switch publicKey := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encodeBigInt(publicKey.N, 0)
+		jwk.E = encodeBigInt(big.NewInt(int64(publicKey.E)), 0)
+	case *ecdsa.PublicKey:
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = publicKey.Curve.Params().Name
+		jwk.X = encodeBigInt(publicKey.X, size)
+		jwk.Y = encodeBigInt(publicKey.Y, size)
+	}
+
+	return jwk
+}
+
+// Base64 url encoded big-endian integer, left padded with zeros to given size
+func encodeBigInt(n *big.Int, size int) string {
+	b := n.Bytes()
+	if len(b) < size {
+		padded := make([]byte, size)
+		copy(padded[size-len(b):], b)
+		b = padded
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Parse public key from JWK, supports RSA and P-256 EC keys
+func (jwk JWK) PublicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, errors.Wrap(err, "jwtkeys.JWK.PublicKey.n")
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, errors.Wrap(err, "jwtkeys.JWK.PublicKey.e")
+		}
+		if !e.IsInt64() {
+			return nil, errors.Errorf("jwtkeys.JWK.PublicKey: invalid RSA exponent, key %s", jwk.Kid)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if jwk.Crv != elliptic.P256().Params().Name {
+			return nil, errors.Errorf("jwtkeys.JWK.PublicKey: unsupported curve %s, key %s", jwk.Crv, jwk.Kid)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, errors.Wrap(err, "jwtkeys.JWK.PublicKey.x")
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, errors.Wrap(err, "jwtkeys.JWK.PublicKey.y")
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.Errorf("jwtkeys.JWK.PublicKey: point is not on curve, key %s", jwk.Kid)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, errors.Errorf("jwtkeys.JWK.PublicKey: unsupported key type %s, key %s", jwk.Kty, jwk.Kid)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,187 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"io/ioutil"
+	"sort"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+// JWT key, private key is nil for verification only keys
+type Key struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+}
+
+// JWT signing and verification keys
+type KeySet struct {
+	secret     []byte
+	signingKey *Key
+	keys       map[string]*Key
+}
+
+// Load configured JWT keys, HS256 uses server secret key, RS256 and ES256 load PEM key files
+func NewKeySet(cfg *config.Config) (*KeySet, error) {
+	algorithm := cfg.JWT.Algorithm
+	if algorithm == "" {
+		algorithm = HS256
+	}
+
+	switch algorithm {
+	case HS256:
+		if cfg.Server.JwtSecretKey == "" {
+			return nil, errors.New("jwtkeys.NewKeySet: empty JwtSecretKey")
+		}
+		return &KeySet{secret: []byte(cfg.Server.JwtSecretKey), keys: map[string]*Key{}}, nil
+	case RS256, ES256:
+	default:
+		return nil, errors.Errorf("jwtkeys.NewKeySet: unsupported algorithm %s", algorithm)
+	}
+
+	ks := &KeySet{keys: make(map[string]*Key, len(cfg.JWT.Keys))}
+	for _, keyCfg := range cfg.JWT.Keys {
+		key, err := loadKey(keyCfg)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := ks.keys[key.ID]; ok {
+			return nil, errors.Errorf("jwtkeys.NewKeySet: duplicate key id %s", key.ID)
+		}
+		ks.keys[key.ID] = key
+	}
+
+	signingKey, ok := ks.keys[cfg.JWT.SigningKeyID]
+	if !ok {
+		return nil, errors.Errorf("jwtkeys.NewKeySet: signing key %s not found", cfg.JWT.SigningKeyID)
+	}
+	if signingKey.PrivateKey == nil {
+		return nil, errors.Errorf("jwtkeys.NewKeySet: signing key %s has no private key", signingKey.ID)
+	}
+	if signingKey.Algorithm != algorithm {
+		return nil, errors.Errorf("jwtkeys.NewKeySet: signing key %s algorithm %s, expected %s", signingKey.ID, signingKey.Algorithm, algorithm)
+	}
+	ks.signingKey = signingKey
+
+	return ks, nil
+}
+
+// Sign claims with current signing key, kid header is set for asymmetric keys
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if ks.signingKey == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ks.secret)
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(ks.signingKey.Algorithm), claims)
+	token.Header["kid"] = ks.signingKey.ID
+	return token.SignedString(ks.signingKey.PrivateKey)
+}
+
+// Keyfunc for jwt.Parse, selects verification key by kid header
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if ks.signingKey == nil {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return ks.secret, nil
+	}
+
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, errors.New("missing kid header")
+	}
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, errors.Errorf("unknown kid %s", kid)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, errors.Errorf("unexpected signing method %v", token.Header["alg"])
+	}
+	return key.PublicKey, nil
+}
+
+// Public verification keys in JWK set format, empty for HS256
+func (ks *KeySet) JWKS() *JWKS {
+	jwks := &JWKS{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, key := range ks.keys {
+		jwks.Keys = append(jwks.Keys, newJWK(key))
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].Kid < jwks.Keys[j].Kid })
+	return jwks
+}
+
+func loadKey(keyCfg config.JWTKey) (*Key, error) {
+	if keyCfg.ID == "" {
+		return nil, errors.New("jwtkeys.loadKey: empty key id")
+	}
+	key := &Key{ID: keyCfg.ID, Algorithm: keyCfg.Algorithm}
+
+	if keyCfg.PrivateKeyFile != "" {
+		pemBytes, err := ioutil.ReadFile(keyCfg.PrivateKeyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "jwtkeys.loadKey.ReadFile %s", keyCfg.ID)
+		}
+		switch keyCfg.Algorithm {
+		case RS256:
+			privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, errors.Wrapf(err, "jwtkeys.loadKey.ParseRSAPrivateKeyFromPEM %s", keyCfg.ID)
+			}
+			key.PrivateKey, key.PublicKey = privateKey, &privateKey.PublicKey
+		case ES256:
+			privateKey, err := jwt.ParseECPrivateKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, errors.Wrapf(err, "jwtkeys.loadKey.ParseECPrivateKeyFromPEM %s", keyCfg.ID)
+			}
+			key.PrivateKey, key.PublicKey = privateKey, &privateKey.PublicKey
+		default:
+			return nil, errors.Errorf("jwtkeys.loadKey: unsupported algorithm %s for key %s", keyCfg.Algorithm, keyCfg.ID)
+		}
+	} else if keyCfg.PublicKeyFile != "" {
+		pemBytes, err := ioutil.ReadFile(keyCfg.PublicKeyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "jwtkeys.loadKey.ReadFile %s", keyCfg.ID)
+		}
+		switch keyCfg.Algorithm {
+		case RS256:
+			publicKey, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, errors.Wrapf(err, "jwtkeys.loadKey.ParseRSAPublicKeyFromPEM %s", keyCfg.ID)
+			}
+			key.PublicKey = publicKey
+		case ES256:
+			publicKey, err := jwt.ParseECPublicKeyFromPEM(pemBytes)
+			if err != nil {
+				return nil, errors.Wrapf(err, "jwtkeys.loadKey.ParseECPublicKeyFromPEM %s", keyCfg.ID)
+			}
+			key.PublicKey = publicKey
+		default:
+			return nil, errors.Errorf("jwtkeys.loadKey: unsupported algorithm %s for key %s", keyCfg.Algorithm, keyCfg.ID)
+		}
+	} else {
+		return nil, errors.Errorf("jwtkeys.loadKey: no key file for key %s", keyCfg.ID)
+	}
+
+	if ecKey, ok := key.PublicKey.(*ecdsa.PublicKey); ok && ecKey.Curve != elliptic.P256() {
+		return nil, errors.Errorf("jwtkeys.loadKey: ES256 requires P-256 curve, key %s", keyCfg.ID)
+	}
+	if rsaKey, ok := key.PublicKey.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < 2048 {
+		return nil, errors.Errorf("jwtkeys.loadKey: RSA key %s is shorter than 2048 bits", keyCfg.ID)
+	}
+
+	return key, nil
+}
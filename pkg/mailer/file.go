@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+)
+
+// File mail sender, writes every message as .eml file for local development
+type fileMailer struct {
+	cfg *config.Config
+}
+
+// File mail sender constructor
+func NewFileMailer(cfg *config.Config) Mailer {
+	return &fileMailer{cfg: cfg}
+}
+
+// Write message to configured mail folder
+func (m *fileMailer) Send(ctx context.Context, msg *Message) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "fileMailer.Send")
+	defer span.Finish()
+
+	if msg.From == "" {
+		msg.From = m.cfg.Mail.From
+	}
+
+	if err := os.MkdirAll(m.cfg.Mail.Folder, os.ModePerm); err != nil {
+		return errors.Wrap(err, "fileMailer.Send.MkdirAll")
+	}
+
+	fileName := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), uuid.New().String())
+	if err := ioutil.WriteFile(filepath.Join(m.cfg.Mail.Folder, fileName), buildMessage(msg), 0600); err != nil {
+		return errors.Wrap(err, "fileMailer.Send.WriteFile")
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+)
+
+const (
+	SMTPDriver   = "smtp"
+	FileDriver   = "file"
+	MemoryDriver = "memory"
+)
+
+// Mail message
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mail sender interface
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Returns mail sender for configured driver
+func NewMailer(cfg *config.Config) (Mailer, error) {
+	switch cfg.Mail.Driver {
+	case SMTPDriver:
+		return NewSMTPMailer(cfg), nil
+	case FileDriver:
+		return NewFileMailer(cfg), nil
+	case MemoryDriver:
+		return NewMemoryMailer(), nil
+	default:
+		return nil, errors.Errorf("unknown mail driver: %s", cfg.Mail.Driver)
+	}
+}
@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+)
+
+// In memory mail sender, keeps sent messages for tests
+type MemoryMailer struct {
+	mu       sync.RWMutex
+	messages []Message
+}
+
+// In memory mail sender constructor
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{messages: make([]Message, 0)}
+}
+
+// Save message in memory
+func (m *MemoryMailer) Send(ctx context.Context, msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, *msg)
+	return nil
+}
+
+// Get all sent messages
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	messages := make([]Message, len(m.messages))
+	copy(messages, m.messages)
+	return messages
+}
+
+// Get last sent message
+func (m *MemoryMailer) LastMessage() (Message, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.messages) == 0 {
+		return Message{}, false
+	}
+	return m.messages[len(m.messages)-1], true
+}
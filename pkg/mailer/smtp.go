@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+)
+
+// SMTP mail sender
+type smtpMailer struct {
+	cfg *config.Config
+}
+
+// SMTP mail sender constructor
+func NewSMTPMailer(cfg *config.Config) Mailer {
+	return &smtpMailer{cfg: cfg}
+}
+
+// Send message using configured SMTP server
+func (m *smtpMailer) Send(ctx context.Context, msg *Message) error {
+	span, _ := opentracing.StartSpanFromContext(ctx, "smtpMailer.Send")
+	defer span.Finish()
+
+	if msg.From == "" {
+		msg.From = m.cfg.Mail.From
+	}
+
+	var auth smtp.Auth
+	if m.cfg.Mail.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Mail.Username, m.cfg.Mail.Password, m.cfg.Mail.Host)
+	}
+
+	addr := net.JoinHostPort(m.cfg.Mail.Host, m.cfg.Mail.Port)
+	if err := smtp.SendMail(addr, auth, msg.From, msg.To, buildMessage(msg)); err != nil {
+		return errors.Wrap(err, "smtpMailer.Send.SendMail")
+	}
+	return nil
+}
+
+// Build RFC 822 message with plain text body
+func buildMessage(msg *Message) []byte {
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "From: %s\r\n", msg.From)
+	fmt.Fprintf(buf, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(buf, "Subject: %s\r\n", msg.Subject)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	buf.WriteString(msg.Body)
+	return buf.Bytes()
+}
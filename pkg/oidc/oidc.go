@@ -0,0 +1,346 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/pkg/jwtkeys"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+	// Allowed clock skew for ID token time claims
+	clockSkew        = time.Minute
+	codeVerifierSize = 32
+	maxResponseSize  = 1 << 20
+	requestTimeout   = 10 * time.Second
+	// Minimal interval between JWKS refetches caused by unknown kid
+	keysRefreshInterval = time.Minute
+)
+
+// OpenID provider discovery document
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// Token endpoint response
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Verified ID token claims
+type IDTokenClaims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	GivenName     string
+	FamilyName    string
+}
+
+// OpenID Connect relying party for single provider, discovery document and keys are fetched lazily
+type Provider struct {
+	cfg       config.OIDCProvider
+	client    *http.Client
+	mu        sync.RWMutex
+	discovery *Discovery
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// Provider constructor, default http client is used if client is nil
+func NewProvider(cfg config.OIDCProvider, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+	return &Provider{cfg: cfg, client: client, keys: map[string]crypto.PublicKey{}}
+}
+
+// Configured providers by name
+func NewProviders(cfg *config.Config) map[string]*Provider {
+	providers := make(map[string]*Provider, len(cfg.OIDC.Providers))
+	for _, providerCfg := range cfg.OIDC.Providers {
+		providers[providerCfg.Name] = NewProvider(providerCfg, nil)
+	}
+	return providers
+}
+
+// Provider name
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// Authorization code request url with PKCE S256 code challenge
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeVerifier string) (string, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := append([]string{"openid"}, p.cfg.Scopes...)
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {CodeChallenge(codeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange authorization code for tokens
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string) (*TokenResponse, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Wrap(err, "oidc.Provider.Exchange.NewRequestWithContext")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	token := &TokenResponse{}
+	if err = p.doJSON(req, token); err != nil {
+		return nil, errors.Wrap(err, "oidc.Provider.Exchange")
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("oidc.Provider.Exchange: token response has no id_token")
+	}
+
+	return token, nil
+}
+
+// Verify ID token signature against provider JWKS, issuer, audience, expiration and nonce
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken string, nonce string) (*IDTokenClaims, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &jwt.Parser{
+		ValidMethods:         []string{jwtkeys.RS256, jwtkeys.ES256},
+		SkipClaimsValidation: true,
+	}
+	claims := jwt.MapClaims{}
+	if _, err = parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.getKey(ctx, kid)
+	}); err != nil {
+		return nil, errors.Wrap(err, "oidc.Provider.VerifyIDToken.ParseWithClaims")
+	}
+
+	if iss, _ := claims["iss"].(string); iss != discovery.Issuer {
+		return nil, errors.Errorf("oidc.Provider.VerifyIDToken: unexpected issuer %s", iss)
+	}
+	if !hasAudience(claims["aud"], p.cfg.ClientID) {
+		return nil, errors.New("oidc.Provider.VerifyIDToken: client is not in audience")
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok || time.Now().After(time.Unix(int64(exp), 0).Add(clockSkew)) {
+		return nil, errors.New("oidc.Provider.VerifyIDToken: token is expired")
+	}
+	if iat, ok := claims["iat"].(float64); ok && time.Unix(int64(iat), 0).After(time.Now().Add(clockSkew)) {
+		return nil, errors.New("oidc.Provider.VerifyIDToken: token used before issued")
+	}
+	if tokenNonce, _ := claims["nonce"].(string); nonce == "" || tokenNonce != nonce {
+		return nil, errors.New("oidc.Provider.VerifyIDToken: nonce mismatch")
+	}
+
+	idClaims := &IDTokenClaims{}
+	idClaims.Subject, _ = claims["sub"].(string)
+	idClaims.Email, _ = claims["email"].(string)
+	idClaims.Name, _ = claims["name"].(string)
+	idClaims.GivenName, _ = claims["given_name"].(string)
+	idClaims.FamilyName, _ = claims["family_name"].(string)
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		idClaims.EmailVerified = verified
+	case string:
+		idClaims.EmailVerified = verified == "true"
+	}
+	if idClaims.Subject == "" {
+		return nil, errors.New("oidc.Provider.VerifyIDToken: empty subject")
+	}
+
+	return idClaims, nil
+}
+
+// Generate random PKCE code verifier
+func GenerateCodeVerifier() (string, error) {
+	b := make([]byte, codeVerifierSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "oidc.GenerateCodeVerifier")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// PKCE S256 code challenge of verifier
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (p *Provider) getDiscovery(ctx context.Context) (*Discovery, error) {
+	p.mu.RLock()
+	discovery := p.discovery
+	p.mu.RUnlock()
+	if discovery != nil {
+		return discovery, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.cfg.Issuer, "/")+discoveryPath, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "oidc.Provider.getDiscovery.NewRequestWithContext")
+	}
+	discovery = &Discovery{}
+	if err = p.doJSON(req, discovery); err != nil {
+		return nil, errors.Wrap(err, "oidc.Provider.getDiscovery")
+	}
+	if discovery.Issuer != p.cfg.Issuer {
+		return nil, errors.Errorf("oidc.Provider.getDiscovery: issuer %s does not match configured %s", discovery.Issuer, p.cfg.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JwksURI == "" {
+		return nil, errors.New("oidc.Provider.getDiscovery: incomplete discovery document")
+	}
+
+	p.mu.Lock()
+	p.discovery = discovery
+	p.mu.Unlock()
+
+	return discovery, nil
+}
+
+// Get verification key by kid, JWKS is refetched on unknown kid to follow provider key rotation
+func (p *Provider) getKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.RLock()
+	key, ok := p.keys[kid]
+	fetchedAt := p.fetchedAt
+	p.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	if time.Since(fetchedAt) > keysRefreshInterval {
+		if err := p.fetchKeys(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if key, ok = p.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, errors.Errorf("oidc.Provider.getKey: unknown kid %s", kid)
+}
+
+func (p *Provider) fetchKeys(ctx context.Context) error {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discovery.JwksURI, nil)
+	if err != nil {
+		return errors.Wrap(err, "oidc.Provider.fetchKeys.NewRequestWithContext")
+	}
+	jwks := &jwtkeys.JWKS{}
+	if err = p.doJSON(req, jwks); err != nil {
+		return errors.Wrap(err, "oidc.Provider.fetchKeys")
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.fetchedAt = time.Now()
+	p.mu.Unlock()
+
+	return nil
+}
+
+func (p *Provider) doJSON(req *http.Request, v interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "client.Do")
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return errors.Wrap(err, "ioutil.ReadAll")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+	if err = json.Unmarshal(body, v); err != nil {
+		return errors.Wrap(err, "json.Unmarshal")
+	}
+	return nil
+}
+
+func hasAudience(aud interface{}, clientID string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == clientID
+	case []interface{}:
+		for _, a := range v {
+			if s, ok := a.(string); ok && s == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
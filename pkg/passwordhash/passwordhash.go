@@ -0,0 +1,199 @@
+package passwordhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/AleksK1NG/api-mc/config"
+)
+
+// Hashing algorithms
+const (
+	Bcrypt   = "bcrypt"
+	Argon2id = "argon2id"
+)
+
+// Default argon2id parameters, RFC 9106 second recommended option
+const (
+	defaultArgon2Time       = 3
+	defaultArgon2Memory     = 64 * 1024
+	defaultArgon2Threads    = 2
+	defaultArgon2KeyLength  = 32
+	defaultArgon2SaltLength = 16
+)
+
+var (
+	ErrMismatchedHashAndPassword = errors.New("passwordhash: hashed password is not the hash of the given password")
+	ErrUnknownHashFormat         = errors.New("passwordhash: unknown hash format")
+)
+
+// Password hasher, hashes with configured algorithm and verifies hashes of any supported algorithm
+type Hasher interface {
+	Hash(password string) (string, error)
+	Compare(encodedHash string, password string) error
+	NeedsRehash(encodedHash string) bool
+}
+
+type argon2Params struct {
+	time       uint32
+	memory     uint32
+	threads    uint8
+	keyLength  uint32
+	saltLength uint32
+}
+
+type hasher struct {
+	algorithm  string
+	bcryptCost int
+	argon2     argon2Params
+}
+
+// Password hasher constructor, bcrypt is used when algorithm is not configured, zero parameters are set to defaults.
+// Unsupported algorithm or bcrypt cost are reported as error.
+func New(cfg config.PasswordHash) (Hasher, error) {
+	h := &hasher{
+		algorithm:  cfg.Algorithm,
+		bcryptCost: cfg.BcryptCost,
+		argon2: argon2Params{
+			time:       uint32(cfg.Argon2Time),
+			memory:     uint32(cfg.Argon2Memory),
+			threads:    uint8(cfg.Argon2Threads),
+			keyLength:  uint32(cfg.Argon2KeyLength),
+			saltLength: uint32(cfg.Argon2SaltLength),
+		},
+	}
+
+	if h.algorithm == "" {
+		h.algorithm = Bcrypt
+	}
+	if h.algorithm != Bcrypt && h.algorithm != Argon2id {
+		return nil, errors.Errorf("passwordhash.New: unsupported algorithm %s", h.algorithm)
+	}
+	if h.bcryptCost == 0 {
+		h.bcryptCost = bcrypt.DefaultCost
+	}
+	if h.bcryptCost < bcrypt.MinCost || h.bcryptCost > bcrypt.MaxCost {
+		return nil, errors.Errorf("passwordhash.New: unsupported bcrypt cost %d", h.bcryptCost)
+	}
+	if h.argon2.time == 0 {
+		h.argon2.time = defaultArgon2Time
+	}
+	if h.argon2.memory == 0 {
+		h.argon2.memory = defaultArgon2Memory
+	}
+	if h.argon2.threads == 0 {
+		h.argon2.threads = defaultArgon2Threads
+	}
+	if h.argon2.keyLength == 0 {
+		h.argon2.keyLength = defaultArgon2KeyLength
+	}
+	if h.argon2.saltLength == 0 {
+		h.argon2.saltLength = defaultArgon2SaltLength
+	}
+
+	return h, nil
+}
+
+// Hash password with configured algorithm, algorithm and parameters are encoded in the hash
+func (h *hasher) Hash(password string) (string, error) {
+	if h.algorithm == Argon2id {
+		return h.hashArgon2id(password)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+	if err != nil {
+		return "", errors.Wrap(err, "passwordhash.Hash.bcrypt.GenerateFromPassword")
+	}
+	return string(hashedPassword), nil
+}
+
+// Compare encoded hash of any supported algorithm with password
+func (h *hasher) Compare(encodedHash string, password string) error {
+	if strings.HasPrefix(encodedHash, "$"+Argon2id+"$") {
+		params, salt, key, err := decodeArgon2id(encodedHash)
+		if err != nil {
+			return err
+		}
+		otherKey := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, params.keyLength)
+		if subtle.ConstantTimeCompare(key, otherKey) != 1 {
+			return ErrMismatchedHashAndPassword
+		}
+		return nil
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrMismatchedHashAndPassword
+		}
+		return errors.Wrap(err, "passwordhash.Compare.bcrypt.CompareHashAndPassword")
+	}
+	return nil
+}
+
+// Check encoded hash uses other algorithm or parameters than configured
+func (h *hasher) NeedsRehash(encodedHash string) bool {
+	if h.algorithm == Argon2id {
+		params, _, _, err := decodeArgon2id(encodedHash)
+		return err != nil || params != h.argon2
+	}
+
+	cost, err := bcrypt.Cost([]byte(encodedHash))
+	return err != nil || cost != h.bcryptCost
+}
+
+// PHC string format: $argon2id$v=19$m=65536,t=3,p=2$salt$key
+func (h *hasher) hashArgon2id(password string) (string, error) {
+	salt := make([]byte, h.argon2.saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.Wrap(err, "passwordhash.hashArgon2id.rand.Read")
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.argon2.time, h.argon2.memory, h.argon2.threads, h.argon2.keyLength)
+
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		Argon2id,
+		argon2.Version,
+		h.argon2.memory,
+		h.argon2.time,
+		h.argon2.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func decodeArgon2id(encodedHash string) (argon2Params, []byte, []byte, error) {
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != Argon2id {
+		return argon2Params{}, nil, nil, ErrUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2Params{}, nil, nil, ErrUnknownHashFormat
+	}
+
+	params := argon2Params{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return argon2Params{}, nil, nil, ErrUnknownHashFormat
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return argon2Params{}, nil, nil, ErrUnknownHashFormat
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return argon2Params{}, nil, nil, ErrUnknownHashFormat
+	}
+	params.saltLength = uint32(len(salt))
+	params.keyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
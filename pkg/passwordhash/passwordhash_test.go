@@ -0,0 +1,198 @@
+package passwordhash
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/AleksK1NG/api-mc/config"
+)
+
+// Cheap parameters to keep tests fast
+var (
+	testBcryptConfig   = config.PasswordHash{Algorithm: Bcrypt, BcryptCost: bcrypt.MinCost}
+	testArgon2idConfig = config.PasswordHash{Algorithm: Argon2id, Argon2Time: 1, Argon2Memory: 1024, Argon2Threads: 1}
+)
+
+func newTestHasher(t *testing.T, cfg config.PasswordHash) Hasher {
+	t.Helper()
+
+	h, err := New(cfg)
+	require.NoError(t, err)
+	return h
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Defaults", func(t *testing.T) {
+		h := newTestHasher(t, config.PasswordHash{}).(*hasher)
+		require.Equal(t, Bcrypt, h.algorithm)
+		require.Equal(t, bcrypt.DefaultCost, h.bcryptCost)
+		require.Equal(t, argon2Params{
+			time:       defaultArgon2Time,
+			memory:     defaultArgon2Memory,
+			threads:    defaultArgon2Threads,
+			keyLength:  defaultArgon2KeyLength,
+			saltLength: defaultArgon2SaltLength,
+		}, h.argon2)
+	})
+
+	t.Run("Unknown algorithm", func(t *testing.T) {
+		h, err := New(config.PasswordHash{Algorithm: "scrypt"})
+		require.Error(t, err)
+		require.Nil(t, h)
+	})
+
+	t.Run("Invalid bcrypt cost", func(t *testing.T) {
+		for _, cost := range []int{bcrypt.MinCost - 1, bcrypt.MaxCost + 1} {
+			h, err := New(config.PasswordHash{Algorithm: Bcrypt, BcryptCost: cost})
+			require.Error(t, err)
+			require.Nil(t, h)
+		}
+	})
+}
+
+func TestHasher_HashCompare(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		cfg    config.PasswordHash
+		prefix string
+	}{
+		{name: "Bcrypt", cfg: testBcryptConfig, prefix: "$2a$04$"},
+		{name: "Argon2id", cfg: testArgon2idConfig, prefix: "$argon2id$v=19$m=1024,t=1,p=1$"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := newTestHasher(t, tc.cfg)
+
+			encodedHash, err := h.Hash("123456")
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(encodedHash, tc.prefix), encodedHash)
+
+			require.NoError(t, h.Compare(encodedHash, "123456"))
+			require.Equal(t, ErrMismatchedHashAndPassword, h.Compare(encodedHash, "654321"))
+
+			otherHash, err := h.Hash("123456")
+			require.NoError(t, err)
+			require.NotEqual(t, encodedHash, otherHash, "hashes must be salted")
+		})
+	}
+
+	t.Run("Compare hash of other algorithm", func(t *testing.T) {
+		t.Parallel()
+
+		bcryptHasher := newTestHasher(t, testBcryptConfig)
+		argon2idHasher := newTestHasher(t, testArgon2idConfig)
+
+		bcryptHash, err := bcryptHasher.Hash("123456")
+		require.NoError(t, err)
+		argon2idHash, err := argon2idHasher.Hash("123456")
+		require.NoError(t, err)
+
+		require.NoError(t, argon2idHasher.Compare(bcryptHash, "123456"))
+		require.NoError(t, bcryptHasher.Compare(argon2idHash, "123456"))
+	})
+
+	t.Run("Malformed hash", func(t *testing.T) {
+		t.Parallel()
+
+		h := newTestHasher(t, testBcryptConfig)
+		for _, encodedHash := range []string{"", "plain", "$argon2id$v=19$m=1024,t=1,p=1$c2FsdA"} {
+			err := h.Compare(encodedHash, "123456")
+			require.Error(t, err)
+			require.NotEqual(t, ErrMismatchedHashAndPassword, err)
+		}
+	})
+}
+
+func TestDecodeArgon2id(t *testing.T) {
+	t.Parallel()
+
+	t.Run("PHC string", func(t *testing.T) {
+		params, salt, key, err := decodeArgon2id("$argon2id$v=19$m=65536,t=3,p=2$c2FsdHNhbHQ$a2V5a2V5a2V5")
+		require.NoError(t, err)
+		require.Equal(t, argon2Params{time: 3, memory: 65536, threads: 2, keyLength: 9, saltLength: 8}, params)
+		require.Equal(t, []byte("saltsalt"), salt)
+		require.Equal(t, []byte("keykeykey"), key)
+	})
+
+	invalid := []struct {
+		name        string
+		encodedHash string
+	}{
+		{name: "Bcrypt", encodedHash: "$2a$04$abcdefghijklmnopqrstuu5Q4Cj3U6ZHVwOqe6JrbL0WDrT3pJiRq"},
+		{name: "Other argon2 variant", encodedHash: "$argon2i$v=19$m=65536,t=3,p=2$c2FsdHNhbHQ$a2V5a2V5a2V5"},
+		{name: "Missing part", encodedHash: "$argon2id$v=19$m=65536,t=3,p=2$c2FsdHNhbHQ"},
+		{name: "Unsupported version", encodedHash: "$argon2id$v=16$m=65536,t=3,p=2$c2FsdHNhbHQ$a2V5a2V5a2V5"},
+		{name: "Invalid params", encodedHash: "$argon2id$v=19$t=3,m=65536,p=2$c2FsdHNhbHQ$a2V5a2V5a2V5"},
+		{name: "Invalid salt", encodedHash: "$argon2id$v=19$m=65536,t=3,p=2$not base64!$a2V5a2V5a2V5"},
+		{name: "Empty key", encodedHash: "$argon2id$v=19$m=65536,t=3,p=2$c2FsdHNhbHQ$"},
+	}
+
+	for _, tc := range invalid {
+		_, _, _, err := decodeArgon2id(tc.encodedHash)
+		require.Equal(t, ErrUnknownHashFormat, err, tc.name)
+	}
+}
+
+func TestHasher_NeedsRehash(t *testing.T) {
+	t.Parallel()
+
+	bcryptHasher := newTestHasher(t, testBcryptConfig)
+	argon2idHasher := newTestHasher(t, testArgon2idConfig)
+
+	bcryptHash, err := bcryptHasher.Hash("123456")
+	require.NoError(t, err)
+	argon2idHash, err := argon2idHasher.Hash("123456")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name        string
+		hasher      Hasher
+		encodedHash string
+		rehash      bool
+	}{
+		{name: "Bcrypt same cost", hasher: bcryptHasher, encodedHash: bcryptHash, rehash: false},
+		{name: "Bcrypt cost changed", hasher: newTestHasher(t, config.PasswordHash{BcryptCost: bcrypt.MinCost + 1}), encodedHash: bcryptHash, rehash: true},
+		{name: "Bcrypt to argon2id", hasher: argon2idHasher, encodedHash: bcryptHash, rehash: true},
+		{name: "Argon2id same params", hasher: argon2idHasher, encodedHash: argon2idHash, rehash: false},
+		{
+			name: "Argon2id memory changed",
+			hasher: newTestHasher(t, config.PasswordHash{
+				Algorithm:     Argon2id,
+				Argon2Time:    testArgon2idConfig.Argon2Time,
+				Argon2Memory:  2048,
+				Argon2Threads: testArgon2idConfig.Argon2Threads,
+			}),
+			encodedHash: argon2idHash,
+			rehash:      true,
+		},
+		{
+			name: "Argon2id key length changed",
+			hasher: newTestHasher(t, config.PasswordHash{
+				Algorithm:       Argon2id,
+				Argon2Time:      testArgon2idConfig.Argon2Time,
+				Argon2Memory:    testArgon2idConfig.Argon2Memory,
+				Argon2Threads:   testArgon2idConfig.Argon2Threads,
+				Argon2KeyLength: 64,
+			}),
+			encodedHash: argon2idHash,
+			rehash:      true,
+		},
+		{name: "Argon2id to bcrypt", hasher: bcryptHasher, encodedHash: argon2idHash, rehash: true},
+		{name: "Malformed hash", hasher: bcryptHasher, encodedHash: "plain", rehash: true},
+	}
+
+	for _, tc := range cases {
+		require.Equal(t, tc.rehash, tc.hasher.NeedsRehash(tc.encodedHash), tc.name)
+	}
+}
@@ -0,0 +1,116 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Source of breached password SHA-1 hash suffixes by hash prefix, same contract as HIBP range API
+type RangeSource interface {
+	Range(prefix string) ([]string, error)
+}
+
+// Range source backed by local file of upper case SHA-1 hashes sorted by hash, one HASH:COUNT per line.
+// File is binary searched on every lookup, so it is never loaded into memory.
+type FileRangeSource struct {
+	path string
+	once sync.Once
+	file *os.File
+	size int64
+	err  error
+}
+
+// File range source constructor, file is opened lazily on first lookup
+func NewFileRangeSource(path string) *FileRangeSource {
+	return &FileRangeSource{path: path}
+}
+
+// Hash suffixes of all lines starting with prefix
+func (s *FileRangeSource) Range(prefix string) ([]string, error) {
+	s.once.Do(s.open)
+	if s.err != nil {
+		return nil, s.err
+	}
+	prefix = strings.ToUpper(prefix)
+
+	// Smallest offset whose line hash is not less than prefix
+	lo, hi := int64(0), s.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		line, _, err := s.lineAt(mid)
+		if err != nil {
+			return nil, err
+		}
+		if line == "" || lineHash(line) >= prefix {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	_, start, err := s.lineAt(lo)
+	if err != nil {
+		return nil, err
+	}
+
+	suffixes := make([]string, 0)
+	scanner := bufio.NewScanner(io.NewSectionReader(s.file, start, s.size-start))
+	for scanner.Scan() {
+		hash := lineHash(scanner.Text())
+		if !strings.HasPrefix(hash, prefix) {
+			break
+		}
+		suffixes = append(suffixes, hash[len(prefix):])
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "passwordpolicy.FileRangeSource.Range.Scan")
+	}
+
+	return suffixes, nil
+}
+
+func (s *FileRangeSource) open() {
+	file, err := os.Open(s.path)
+	if err != nil {
+		s.err = errors.Wrap(err, "passwordpolicy.FileRangeSource.open.Open")
+		return
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		s.err = errors.Wrap(err, "passwordpolicy.FileRangeSource.open.Stat")
+		return
+	}
+	s.file, s.size = file, info.Size()
+}
+
+// First line starting at or after offset and its start offset, empty line at end of file
+func (s *FileRangeSource) lineAt(offset int64) (string, int64, error) {
+	start := offset
+	if offset > 0 {
+		reader := bufio.NewReader(io.NewSectionReader(s.file, offset-1, s.size-offset+1))
+		skipped, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return "", s.size, nil
+		}
+		if err != nil {
+			return "", 0, errors.Wrap(err, "passwordpolicy.FileRangeSource.lineAt.ReadString")
+		}
+		start = offset - 1 + int64(len(skipped))
+	}
+
+	line, err := bufio.NewReader(io.NewSectionReader(s.file, start, s.size-start)).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", 0, errors.Wrap(err, "passwordpolicy.FileRangeSource.lineAt.ReadString")
+	}
+	return strings.TrimSpace(line), start, nil
+}
+
+func lineHash(line string) string {
+	return strings.ToUpper(strings.TrimSpace(strings.SplitN(line, ":", 2)[0]))
+}
@@ -0,0 +1,137 @@
+package passwordpolicy
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Sorted HASH:COUNT fixture, SHA-1 of "password" and "123456" in the middle
+var rangeFixture = []string{
+	"0000000A1B2C3D4E5F60718293A4B5C6D7E8F901:3",
+	"0000000FFEEDDCCBBAA99887766554433221100F:12",
+	"00001000000000000000000000000000000000AA:1",
+	"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493",
+	"7C4A8D09CA3762AF61E59520943DC26494F8941B:37359195",
+	"7C4A9000000000000000000000000000000000BB:2",
+	"FFFFE000000000000000000000000000000000CC:5",
+	"FFFFF00000000000000000000000000000000001:7",
+	"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:9",
+}
+
+// Write fixture lines to temp file with given line separator
+func writeRangeFile(t *testing.T, lines []string, separator string, trailingSeparator bool) string {
+	t.Helper()
+
+	content := strings.Join(lines, separator)
+	if trailingSeparator && len(lines) > 0 {
+		content += separator
+	}
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestFileRangeSource_Range(t *testing.T) {
+	t.Parallel()
+
+	files := []struct {
+		name              string
+		separator         string
+		trailingSeparator bool
+	}{
+		{name: "LF", separator: "\n", trailingSeparator: true},
+		{name: "No trailing newline", separator: "\n", trailingSeparator: false},
+		{name: "CRLF", separator: "\r\n", trailingSeparator: true},
+		{name: "CRLF no trailing newline", separator: "\r\n", trailingSeparator: false},
+	}
+
+	cases := []struct {
+		name     string
+		prefix   string
+		suffixes []string
+	}{
+		{
+			name:     "First line",
+			prefix:   "00000",
+			suffixes: []string{"00A1B2C3D4E5F60718293A4B5C6D7E8F901", "00FFEEDDCCBBAA99887766554433221100F"},
+		},
+		{
+			name:     "Middle line",
+			prefix:   "5BAA6",
+			suffixes: []string{"1E4C9B93F3F0682250B6CF8331B7EE68FD8"},
+		},
+		{
+			name:     "Lower case prefix",
+			prefix:   "7c4a8",
+			suffixes: []string{"D09CA3762AF61E59520943DC26494F8941B"},
+		},
+		{
+			name:     "Last line",
+			prefix:   "FFFFF",
+			suffixes: []string{"00000000000000000000000000000000001", "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF"},
+		},
+		{
+			name:     "Missing prefix",
+			prefix:   "12345",
+			suffixes: []string{},
+		},
+		{
+			name:     "Adjacent prefix",
+			prefix:   "7C4A9",
+			suffixes: []string{"000000000000000000000000000000000BB"},
+		},
+		{
+			name:     "Missing prefix before first line",
+			prefix:   "00000A",
+			suffixes: []string{},
+		},
+	}
+
+	for _, file := range files {
+		file := file
+		t.Run(file.name, func(t *testing.T) {
+			t.Parallel()
+
+			source := NewFileRangeSource(writeRangeFile(t, rangeFixture, file.separator, file.trailingSeparator))
+			for _, tc := range cases {
+				suffixes, err := source.Range(tc.prefix)
+				require.NoError(t, err, tc.name)
+				require.Equal(t, tc.suffixes, suffixes, tc.name)
+			}
+		})
+	}
+
+	t.Run("Single line", func(t *testing.T) {
+		t.Parallel()
+
+		source := NewFileRangeSource(writeRangeFile(t, rangeFixture[3:4], "\n", false))
+
+		suffixes, err := source.Range("5BAA6")
+		require.NoError(t, err)
+		require.Equal(t, []string{"1E4C9B93F3F0682250B6CF8331B7EE68FD8"}, suffixes)
+
+		suffixes, err = source.Range("00000")
+		require.NoError(t, err)
+		require.Empty(t, suffixes)
+	})
+
+	t.Run("Empty file", func(t *testing.T) {
+		t.Parallel()
+
+		suffixes, err := NewFileRangeSource(writeRangeFile(t, nil, "\n", false)).Range("5BAA6")
+		require.NoError(t, err)
+		require.Empty(t, suffixes)
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewFileRangeSource(filepath.Join(t.TempDir(), "missing.txt")).Range("5BAA6")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,142 @@
+package passwordpolicy
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+)
+
+const (
+	// bcrypt ignores password bytes after 72
+	bcryptMaxLength = 72
+	// SHA-1 hash prefix length sent to range source, k-anonymity bucket size of HIBP range API
+	hashPrefixLength = 5
+)
+
+// Policy rules
+const (
+	RuleMinLength  = "min_length"
+	RuleMaxLength  = "max_length"
+	RuleUpper      = "upper"
+	RuleLower      = "lower"
+	RuleDigit      = "digit"
+	RuleSymbol     = "symbol"
+	RuleBannedWord = "banned_word"
+	RuleBreached   = "breached"
+)
+
+// Password policy
+type Policy struct {
+	cfg         config.PasswordPolicy
+	bannedWords []string
+	breached    RangeSource
+}
+
+// Password policy constructor, breached password check is disabled without breached hashes file
+func New(cfg config.PasswordPolicy) *Policy {
+	if cfg.MaxLength <= 0 || cfg.MaxLength > bcryptMaxLength {
+		cfg.MaxLength = bcryptMaxLength
+	}
+
+	bannedWords := make([]string, 0, len(cfg.BannedWords))
+	for _, word := range cfg.BannedWords {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			bannedWords = append(bannedWords, word)
+		}
+	}
+
+	policy := &Policy{cfg: cfg, bannedWords: bannedWords}
+	if cfg.BreachedHashesFile != "" {
+		policy.breached = NewFileRangeSource(cfg.BreachedHashesFile)
+	}
+	return policy
+}
+
+// Validate password, returns all violated rules,
+// error is returned only when breached password lookup failed and the check was skipped
+func (p *Policy) Validate(password string) ([]httpErrors.PasswordViolation, error) {
+	violations := make([]httpErrors.PasswordViolation, 0)
+
+	if utf8.RuneCountInString(password) < p.cfg.MinLength {
+		violations = append(violations, violation(RuleMinLength, fmt.Sprintf("Password must be at least %d characters long", p.cfg.MinLength)))
+	}
+	if len(password) > p.cfg.MaxLength {
+		violations = append(violations, violation(RuleMaxLength, fmt.Sprintf("Password must be at most %d bytes long", p.cfg.MaxLength)))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+	if p.cfg.RequireUpper && !hasUpper {
+		violations = append(violations, violation(RuleUpper, "Password must contain an uppercase letter"))
+	}
+	if p.cfg.RequireLower && !hasLower {
+		violations = append(violations, violation(RuleLower, "Password must contain a lowercase letter"))
+	}
+	if p.cfg.RequireDigit && !hasDigit {
+		violations = append(violations, violation(RuleDigit, "Password must contain a digit"))
+	}
+	if p.cfg.RequireSymbol && !hasSymbol {
+		violations = append(violations, violation(RuleSymbol, "Password must contain a symbol"))
+	}
+
+	lowerPassword := strings.ToLower(password)
+	for _, word := range p.bannedWords {
+		if strings.Contains(lowerPassword, word) {
+			violations = append(violations, violation(RuleBannedWord, fmt.Sprintf("Password must not contain %q", word)))
+		}
+	}
+
+	if p.breached == nil {
+		return violations, nil
+	}
+	breached, err := p.isBreached(password)
+	if err != nil {
+		return violations, err
+	}
+	if breached {
+		violations = append(violations, violation(RuleBreached, "Password has appeared in a data breach, choose another one"))
+	}
+
+	return violations, nil
+}
+
+// Only hash prefix leaves the policy, suffix is matched locally
+func (p *Policy) isBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+
+	suffixes, err := p.breached.Range(prefix)
+	if err != nil {
+		return false, errors.Wrap(err, "passwordpolicy.Policy.isBreached.Range")
+	}
+	for _, s := range suffixes {
+		if s == suffix {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func violation(rule string, message string) httpErrors.PasswordViolation {
+	return httpErrors.PasswordViolation{Rule: rule, Message: message}
+}
@@ -0,0 +1,137 @@
+package passwordpolicy
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/AleksK1NG/api-mc/config"
+	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
+)
+
+func violatedRules(violations []httpErrors.PasswordViolation) []string {
+	rules := make([]string, 0, len(violations))
+	for _, v := range violations {
+		rules = append(rules, v.Rule)
+	}
+	return rules
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		cfg      config.PasswordPolicy
+		password string
+		rules    []string
+	}{
+		{
+			name:     "Min length",
+			cfg:      config.PasswordPolicy{MinLength: 8},
+			password: "short",
+			rules:    []string{RuleMinLength},
+		},
+		{
+			name:     "Min length counts runes",
+			cfg:      config.PasswordPolicy{MinLength: 4},
+			password: "пароль",
+			rules:    []string{},
+		},
+		{
+			name:     "Max length",
+			cfg:      config.PasswordPolicy{MaxLength: 10},
+			password: "longer than ten",
+			rules:    []string{RuleMaxLength},
+		},
+		{
+			name:     "Max length defaults to bcrypt limit",
+			cfg:      config.PasswordPolicy{},
+			password: strings.Repeat("a", bcryptMaxLength+1),
+			rules:    []string{RuleMaxLength},
+		},
+		{
+			name:     "Max length above bcrypt limit is capped",
+			cfg:      config.PasswordPolicy{MaxLength: 100},
+			password: strings.Repeat("a", bcryptMaxLength+1),
+			rules:    []string{RuleMaxLength},
+		},
+		{
+			name:     "Character classes missing",
+			cfg:      config.PasswordPolicy{RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true},
+			password: "        ",
+			rules:    []string{RuleUpper, RuleLower, RuleDigit, RuleSymbol},
+		},
+		{
+			name:     "Character classes present",
+			cfg:      config.PasswordPolicy{RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true},
+			password: "Passw0rd!",
+			rules:    []string{},
+		},
+		{
+			name:     "Only upper required",
+			cfg:      config.PasswordPolicy{RequireUpper: true},
+			password: "lowercase1!",
+			rules:    []string{RuleUpper},
+		},
+		{
+			name:     "Banned word case insensitive",
+			cfg:      config.PasswordPolicy{BannedWords: []string{" Company "}},
+			password: "myCOMPANY2021",
+			rules:    []string{RuleBannedWord},
+		},
+		{
+			name:     "Empty banned word is ignored",
+			cfg:      config.PasswordPolicy{BannedWords: []string{"", "  "}},
+			password: "anything",
+			rules:    []string{},
+		},
+		{
+			name:     "All violations are returned",
+			cfg:      config.PasswordPolicy{MinLength: 12, RequireDigit: true, BannedWords: []string{"secret"}},
+			password: "secret",
+			rules:    []string{RuleMinLength, RuleDigit, RuleBannedWord},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			violations, err := New(tc.cfg).Validate(tc.password)
+			require.NoError(t, err)
+			require.Equal(t, tc.rules, violatedRules(violations))
+		})
+	}
+}
+
+func TestPolicy_ValidateBreached(t *testing.T) {
+	t.Parallel()
+
+	policy := New(config.PasswordPolicy{BreachedHashesFile: writeRangeFile(t, rangeFixture, "\n", true)})
+
+	t.Run("Breached", func(t *testing.T) {
+		for _, password := range []string{"password", "123456"} {
+			violations, err := policy.Validate(password)
+			require.NoError(t, err)
+			require.Equal(t, []string{RuleBreached}, violatedRules(violations))
+		}
+	})
+
+	t.Run("Not breached", func(t *testing.T) {
+		violations, err := policy.Validate("correct horse battery staple")
+		require.NoError(t, err)
+		require.Empty(t, violations)
+	})
+
+	t.Run("Lookup failed", func(t *testing.T) {
+		policy := New(config.PasswordPolicy{MinLength: 10, BreachedHashesFile: filepath.Join(t.TempDir(), "missing.txt")})
+
+		violations, err := policy.Validate("password")
+		require.Error(t, err)
+		require.Equal(t, []string{RuleMinLength}, violatedRules(violations))
+	})
+}
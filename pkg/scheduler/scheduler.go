@@ -0,0 +1,30 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/AleksK1NG/api-mc/pkg/logger"
+)
+
+// Periodic background job
+type Job func(ctx context.Context) error
+
+// Run job every interval until context is done, job errors are logged and do not stop the schedule
+func Run(ctx context.Context, name string, interval time.Duration, job Job, log logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Infof("Job %s scheduled every %s", name, interval)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Infof("Job %s stopped", name)
+			return
+		case <-ticker.C:
+			if err := job(ctx); err != nil {
+				log.Errorf("Job %s error: %v", name, err)
+			}
+		}
+	}
+}
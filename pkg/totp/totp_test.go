@@ -0,0 +1,85 @@
+package totp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 6238 Appendix B SHA-1 shared secret "12345678901234567890"
+var rfcSecret = b32.EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerateCode(t *testing.T) {
+	t.Parallel()
+
+	// RFC 6238 Appendix B SHA-1 vectors truncated to 6 digits
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+		{unix: 20000000000, code: "353130"},
+	}
+
+	for _, v := range vectors {
+		code, err := GenerateCode(rfcSecret, Step(time.Unix(v.unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, v.code, code, "unix time %d", v.unix)
+	}
+
+	t.Run("Invalid secret", func(t *testing.T) {
+		_, err := GenerateCode("not base32!", 1)
+		require.Error(t, err)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	t.Run("Current step", func(t *testing.T) {
+		step, ok := Validate("050471", rfcSecret, now)
+		require.True(t, ok)
+		require.Equal(t, current, step)
+	})
+
+	t.Run("Skew", func(t *testing.T) {
+		for _, offset := range []int64{-1, 1} {
+			code, err := GenerateCode(rfcSecret, current+offset)
+			require.NoError(t, err)
+
+			step, ok := Validate(code, rfcSecret, now)
+			require.True(t, ok)
+			require.Equal(t, current+offset, step)
+		}
+	})
+
+	t.Run("Outside skew", func(t *testing.T) {
+		for _, offset := range []int64{-2, 2} {
+			code, err := GenerateCode(rfcSecret, current+offset)
+			require.NoError(t, err)
+
+			_, ok := Validate(code, rfcSecret, now)
+			require.False(t, ok)
+		}
+	})
+
+	t.Run("Wrong length", func(t *testing.T) {
+		for _, code := range []string{"", "05047", "0504710", "94287082"} {
+			_, ok := Validate(code, rfcSecret, now)
+			require.False(t, ok)
+		}
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		_, ok := Validate("000000", rfcSecret, now)
+		require.False(t, ok)
+	})
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"context"
 
+	"github.com/AleksK1NG/api-mc/internal/models"
 	"github.com/AleksK1NG/api-mc/pkg/httpErrors"
 	"github.com/AleksK1NG/api-mc/pkg/logger"
 )
@@ -25,3 +26,29 @@ func ValidateIsOwner(ctx context.Context, creatorID string, logger logger.Logger
 
 	return nil
 }
+
+// Validate is user from owner of content or has permission to manage any content
+func ValidateIsOwnerOrPermission(ctx context.Context, creatorID string, permission string, logger logger.Logger) error {
+	if HasPermission(ctx, permission) {
+		return nil
+	}
+	return ValidateIsOwner(ctx, creatorID, logger)
+}
+
+// Check context user has permission
+func HasPermission(ctx context.Context, permission string) bool {
+	permissions, ok := ctx.Value(PermissionsCtxKey{}).(*models.UserPermissions)
+	if !ok {
+		return false
+	}
+	return permissions.Has(permission)
+}
+
+// Check context user has any admin permission
+func HasAdminPermission(ctx context.Context) bool {
+	permissions, ok := ctx.Value(PermissionsCtxKey{}).(*models.UserPermissions)
+	if !ok {
+		return false
+	}
+	return permissions.HasAdmin()
+}
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 
@@ -26,6 +28,12 @@ func GetRequestID(c echo.Context) string {
 // ReqIDCtxKey is a key used for the Request ID in context
 type ReqIDCtxKey struct{}
 
+// IPAddressCtxKey is a key used for the client ip address in context
+type IPAddressCtxKey struct{}
+
+// UserAgentCtxKey is a key used for the client user agent in context
+type UserAgentCtxKey struct{}
+
 // Get ctx with timeout and request id from echo context
 func GetCtxWithReqID(c echo.Context) (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithTimeout(c.Request().Context(), time.Second*15)
@@ -33,9 +41,29 @@ func GetCtxWithReqID(c echo.Context) (context.Context, context.CancelFunc) {
 	return ctx, cancel
 }
 
-// Get context  with request id
+// Get context  with request id, client ip address and user agent
 func GetRequestCtx(c echo.Context) context.Context {
-	return context.WithValue(c.Request().Context(), ReqIDCtxKey{}, GetRequestID(c))
+	ctx := context.WithValue(c.Request().Context(), ReqIDCtxKey{}, GetRequestID(c))
+	ctx = context.WithValue(ctx, IPAddressCtxKey{}, GetIPAddress(c))
+	return context.WithValue(ctx, UserAgentCtxKey{}, c.Request().UserAgent())
+}
+
+// Get request id from context, empty outside of request
+func GetRequestIDFromCtx(ctx context.Context) string {
+	requestID, _ := ctx.Value(ReqIDCtxKey{}).(string)
+	return requestID
+}
+
+// Get client ip address from context, empty outside of request
+func GetIPAddressFromCtx(ctx context.Context) string {
+	ipAddress, _ := ctx.Value(IPAddressCtxKey{}).(string)
+	return ipAddress
+}
+
+// Get client user agent from context, empty outside of request
+func GetUserAgentFromCtx(ctx context.Context) string {
+	userAgent, _ := ctx.Value(UserAgentCtxKey{}).(string)
+	return userAgent
 }
 
 // Get config path for local or docker
@@ -89,6 +117,12 @@ func DeleteSessionCookie(c echo.Context, sessionName string) {
 // UserCtxKey is a key used for the User object in the context
 type UserCtxKey struct{}
 
+// PermissionsCtxKey is a key used for the user permissions in the context
+type PermissionsCtxKey struct{}
+
+// ImpersonatorCtxKey is a key used for the impersonating admin id in the context
+type ImpersonatorCtxKey struct{}
+
 // Get user from context
 func GetUserFromCtx(ctx context.Context) (*models.User, error) {
 	user, ok := ctx.Value(UserCtxKey{}).(*models.User)
@@ -99,11 +133,29 @@ func GetUserFromCtx(ctx context.Context) (*models.User, error) {
 	return user, nil
 }
 
+// Get id of admin impersonating context user, nil without impersonation
+func GetImpersonatorIDFromCtx(ctx context.Context) *uuid.UUID {
+	impersonatorID, ok := ctx.Value(ImpersonatorCtxKey{}).(uuid.UUID)
+	if !ok {
+		return nil
+	}
+	return &impersonatorID
+}
+
 // Get user ip address
 func GetIPAddress(c echo.Context) string {
 	return c.Request().RemoteAddr
 }
 
+// Get client ip of the connection without port, unlike echo RealIP it ignores client controlled forwarding headers
+func GetClientIP(c echo.Context) string {
+	host, _, err := net.SplitHostPort(c.Request().RemoteAddr)
+	if err != nil {
+		return c.Request().RemoteAddr
+	}
+	return host
+}
+
 // Error response with logging error for echo context
 func ErrResponseWithLog(ctx echo.Context, logger logger.Logger, err error) error {
 	logger.Errorf(
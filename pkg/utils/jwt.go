@@ -11,8 +11,12 @@ import (
 
 	"github.com/AleksK1NG/api-mc/config"
 	"github.com/AleksK1NG/api-mc/internal/models"
+	"github.com/AleksK1NG/api-mc/pkg/jwtkeys"
 )
 
+// Access token lifetime in seconds when it is not configured
+const defaultAccessTokenExpire = 3600
+
 // JWT Claims struct
 type Claims struct {
 	Email string `json:"email"`
@@ -21,21 +25,23 @@ type Claims struct {
 }
 
 // Generate new JWT Token
-func GenerateJWTToken(user *models.User, config *config.Config) (string, error) {
+func GenerateJWTToken(user *models.User, config *config.Config, keys *jwtkeys.KeySet) (string, error) {
+	expire := config.Server.AccessTokenExpire
+	if expire <= 0 {
+		expire = defaultAccessTokenExpire
+	}
+
 	// Register the JWT claims, which includes the username and expiry time
 	claims := &Claims{
 		Email: user.Email,
 		ID:    user.UserID.String(),
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Minute * 60).Unix(),
+			ExpiresAt: time.Now().Add(time.Second * time.Duration(expire)).Unix(),
 		},
 	}
 
-	// Declare the token with the algorithm used for signing, and the claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Register the JWT string
-	tokenString, err := token.SignedString([]byte(config.Server.JwtSecretKey))
+	// Sign the token with configured signing key
+	tokenString, err := keys.Sign(claims)
 	if err != nil {
 		return "", err
 	}